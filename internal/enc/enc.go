@@ -12,6 +12,8 @@ import (
 //    "foo" -> "@foo"
 //    "a b" -> `@"a\20b"`
 //    "世" -> `@"\E4\B8\96"`
+//    "1a" -> `@"1a"`
+//    `a"b` -> `@"a\22b"`
 //
 // References:
 //    http://www.llvm.org/docs/LangRef.html#identifiers
@@ -25,6 +27,8 @@ func Global(name string) string {
 //    "foo" -> "%foo"
 //    "a b" -> `%"a\20b"`
 //    "世" -> `%"\E4\B8\96"`
+//    "1a" -> `%"1a"`
+//    `a"b` -> `%"a\22b"`
 //
 // References:
 //    http://www.llvm.org/docs/LangRef.html#identifiers
@@ -131,7 +135,9 @@ func EscapeIdent(s string) string {
 */
 
 // EscapeIdent replaces any characters which are not valid in identifiers with
-// corresponding hexadecimal escape sequence (\XX).
+// corresponding hexadecimal escape sequence (\XX). Identifiers which contain
+// invalid characters, or which start with a decimal digit without being a
+// numeric ID (e.g. "1a"), are surrounded by quotes.
 func EscapeIdent(s string) string {
 	// Check if a replacement is required.
 	extra := 0
@@ -144,6 +150,9 @@ func EscapeIdent(s string) string {
 		}
 	}
 	if extra == 0 {
+		if needsQuote(s) {
+			return `"` + s + `"`
+		}
 		return s
 	}
 
@@ -167,6 +176,21 @@ func EscapeIdent(s string) string {
 	return `"` + string(buf) + `"`
 }
 
+// needsQuote reports whether the given identifier, consisting only of valid
+// identifier characters, must be quoted; i.e. whether it starts with a decimal
+// digit without being a numeric ID.
+func needsQuote(s string) bool {
+	if len(s) == 0 || strings.IndexByte(decimal, s[0]) == -1 {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		if strings.IndexByte(decimal, s[i]) == -1 {
+			return true
+		}
+	}
+	return false
+}
+
 // EscapeString replaces any characters in s categorized as invalid in string
 // literals with corresponding hexadecimal escape sequence (\XX).
 func EscapeString(s []byte) string {
@@ -30,6 +30,12 @@ func TestGlobal(t *testing.T) {
 		{s: "2", want: "@2"},
 		// i=9
 		{s: "foo世bar", want: `@"foo\E4\B8\96bar"`},
+		// i=10
+		{s: "1a", want: `@"1a"`},
+		// i=11
+		{s: `a"b`, want: `@"a\22b"`},
+		// i=12
+		{s: `a\b`, want: `@"a\5Cb"`},
 	}
 	for i, g := range golden {
 		got := Global(g.s)
@@ -64,6 +70,12 @@ func TestLocal(t *testing.T) {
 		{s: "2", want: "%2"},
 		// i=9
 		{s: "foo世bar", want: `%"foo\E4\B8\96bar"`},
+		// i=10
+		{s: "1a", want: `%"1a"`},
+		// i=11
+		{s: `a"b`, want: `%"a\22b"`},
+		// i=12
+		{s: `a\b`, want: `%"a\5Cb"`},
 	}
 	for i, g := range golden {
 		got := Local(g.s)
@@ -98,6 +110,12 @@ func TestLabel(t *testing.T) {
 		{s: "2", want: "2:"},
 		// i=9
 		{s: "foo世bar", want: `"foo\E4\B8\96bar":`},
+		// i=10
+		{s: "1a", want: `"1a":`},
+		// i=11
+		{s: `a"b`, want: `"a\22b":`},
+		// i=12
+		{s: `a\b`, want: `"a\5Cb":`},
 	}
 	for i, g := range golden {
 		got := Label(g.s)
@@ -148,6 +166,12 @@ func TestComdat(t *testing.T) {
 		{s: "2", want: "$2"},
 		// i=9
 		{s: "foo世bar", want: `$"foo\E4\B8\96bar"`},
+		// i=10
+		{s: "1a", want: `$"1a"`},
+		// i=11
+		{s: `a"b`, want: `$"a\22b"`},
+		// i=12
+		{s: `a\b`, want: `$"a\5Cb"`},
 	}
 	for i, g := range golden {
 		got := Comdat(g.s)
@@ -0,0 +1,53 @@
+package ir
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+
+	"github.com/pkg/errors"
+)
+
+// ErrAssemblerNotFound is returned by Module.Assemble if the LLVM assembler
+// could not be located.
+var ErrAssemblerNotFound = errors.New("unable to locate llvm-as")
+
+// Assemble assembles the module into LLVM bitcode, using the llvm-as tool at
+// the given path. An empty path locates llvm-as through the PATH environment
+// variable. If the assembler fails, the returned error contains its error
+// output.
+//
+// ErrAssemblerNotFound is returned if llvm-as could not be located.
+func (m *Module) Assemble(llvmAsPath string) ([]byte, error) {
+	if len(llvmAsPath) == 0 {
+		llvmAsPath = "llvm-as"
+	}
+	path, err := exec.LookPath(llvmAsPath)
+	if err != nil {
+		return nil, ErrAssemblerNotFound
+	}
+	// Write module to temporary file.
+	f, err := ioutil.TempFile("", "module_")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(m.Def()); err != nil {
+		f.Close()
+		return nil, errors.WithStack(err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	// Assemble module.
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cmd := exec.Command(path, "-o", "-", f.Name())
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return nil, errors.Errorf("unable to assemble module; %v\n%s", err, stderr)
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,40 @@
+package ir
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/llir/l/ir/types"
+)
+
+func TestModuleAssemble(t *testing.T) {
+	// The test is enabled by setting LLVM_AS to the path of llvm-as.
+	llvmAsPath := os.Getenv("LLVM_AS")
+	if len(llvmAsPath) == 0 {
+		t.Skip("LLVM_AS not set; skipping assembler test")
+	}
+	entry := NewBlock("entry")
+	entry.NewRet(NewInt(types.I32, 42))
+	f := &Function{
+		GlobalName: "main",
+		Sig:        types.NewFunc(types.I32),
+		Blocks:     []*BasicBlock{entry},
+	}
+	m := &Module{Funcs: []*Function{f}}
+	buf, err := m.Assemble(llvmAsPath)
+	if err != nil {
+		t.Fatalf("unable to assemble module; %v", err)
+	}
+	// LLVM bitcode magic number.
+	if !bytes.HasPrefix(buf, []byte("BC\xC0\xDE")) {
+		t.Errorf("invalid bitcode; missing magic number")
+	}
+}
+
+func TestModuleAssembleNotFound(t *testing.T) {
+	m := &Module{}
+	if _, err := m.Assemble("/nonexistent/llvm-as"); err != ErrAssemblerNotFound {
+		t.Errorf("error mismatch; expected %v, got %v", ErrAssemblerNotFound, err)
+	}
+}
@@ -0,0 +1,155 @@
+package ir
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/llir/l/ir/enum"
+	"github.com/llir/l/ir/types"
+)
+
+// === [ Attributes ] ==========================================================
+
+// --- [ Parameter and return attributes ] -------------------------------------
+
+// Align is an alignment attribute.
+type Align uint64
+
+// String returns the string representation of the alignment attribute.
+func (align Align) String() string {
+	// "align" int_lit
+	return fmt.Sprintf("align %d", uint64(align))
+}
+
+// Dereferenceable is a dereferenceable attribute.
+type Dereferenceable struct {
+	// Number of bytes known to be dereferenceable.
+	N uint64
+	// (optional) Either dereferenceable or null if set.
+	DerefOrNull bool
+}
+
+// String returns the string representation of the dereferenceable attribute.
+func (d Dereferenceable) String() string {
+	// "dereferenceable" "(" int_lit ")"
+	// "dereferenceable_or_null" "(" int_lit ")"
+	if d.DerefOrNull {
+		return fmt.Sprintf("dereferenceable_or_null(%d)", d.N)
+	}
+	return fmt.Sprintf("dereferenceable(%d)", d.N)
+}
+
+// ByVal is a byval parameter attribute.
+type ByVal struct {
+	// Element type of the pointer parameter passed by value.
+	Typ types.Type
+}
+
+// String returns the string representation of the byval parameter attribute.
+func (b ByVal) String() string {
+	// "byval" "(" Type ")"
+	return fmt.Sprintf("byval(%v)", b.Typ)
+}
+
+// SRet is a structure return parameter attribute.
+type SRet struct {
+	// Element type of the pointer parameter of the returned structure.
+	Typ types.Type
+}
+
+// String returns the string representation of the sret parameter attribute.
+func (s SRet) String() string {
+	// "sret" "(" Type ")"
+	return fmt.Sprintf("sret(%v)", s.Typ)
+}
+
+// InAlloca is an inalloca parameter attribute.
+type InAlloca struct {
+	// Element type of the pointer parameter of the argument memory.
+	Typ types.Type
+}
+
+// String returns the string representation of the inalloca parameter
+// attribute.
+func (i InAlloca) String() string {
+	// "inalloca" "(" Type ")"
+	return fmt.Sprintf("inalloca(%v)", i.Typ)
+}
+
+// IsParamAttribute ensures that only parameter attributes can be assigned to
+// the enum.ParamAttribute interface.
+func (Align) IsParamAttribute()           {}
+func (Dereferenceable) IsParamAttribute() {}
+func (ByVal) IsParamAttribute()           {}
+func (SRet) IsParamAttribute()            {}
+func (InAlloca) IsParamAttribute()        {}
+
+// IsReturnAttribute ensures that only return attributes can be assigned to the
+// enum.ReturnAttribute interface.
+func (Align) IsReturnAttribute()           {}
+func (Dereferenceable) IsReturnAttribute() {}
+
+// --- [ Function attributes ] -------------------------------------------------
+
+// Memory is a memory attribute, specifying the memory effects of a function.
+type Memory struct {
+	// Memory effect of locations not explicitly listed.
+	Default enum.MemoryEffect
+	// (optional) Memory effects of specific locations.
+	Locations []MemoryLocationEffect
+}
+
+// MemoryLocationEffect is the memory effect of a specific memory location.
+type MemoryLocationEffect struct {
+	// Memory location.
+	Location enum.MemoryLocation
+	// Memory effect of the location.
+	Effect enum.MemoryEffect
+}
+
+// String returns the string representation of the memory attribute.
+func (m Memory) String() string {
+	// "memory" "(" MemoryEffect ")"
+	// "memory" "(" [ MemoryEffect "," ] MemoryLocation ":" MemoryEffect { "," MemoryLocation ":" MemoryEffect } ")"
+	var parts []string
+	if m.Default != enum.MemoryEffectNone || len(m.Locations) == 0 {
+		parts = append(parts, m.Default.String())
+	}
+	for _, loc := range m.Locations {
+		parts = append(parts, fmt.Sprintf("%v: %v", loc.Location, loc.Effect))
+	}
+	return fmt.Sprintf("memory(%s)", strings.Join(parts, ", "))
+}
+
+// IsFuncAttribute ensures that only function attributes can be assigned to the
+// enum.FuncAttribute interface.
+func (Memory) IsFuncAttribute() {}
+
+// AttrString is a string function attribute (e.g. "no-trapping-math").
+type AttrString string
+
+// String returns the string representation of the string attribute.
+func (a AttrString) String() string {
+	// StringLit
+	return quote(string(a))
+}
+
+// AttrPair is a key-value pair function attribute (e.g.
+// "stack-protector-buffer-size"="8").
+type AttrPair struct {
+	// Attribute key.
+	Key string
+	// Attribute value.
+	Value string
+}
+
+// String returns the string representation of the key-value pair attribute.
+func (a AttrPair) String() string {
+	// StringLit "=" StringLit
+	return fmt.Sprintf("%s=%s", quote(a.Key), quote(a.Value))
+}
+
+// IsFuncAttribute ensures that only function attributes can be assigned to the
+// enum.FuncAttribute interface.
+func (AttrString) IsFuncAttribute() {}
+func (AttrPair) IsFuncAttribute()   {}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/llir/l/internal/enc"
 	"github.com/llir/l/ir/types"
+	"github.com/llir/l/ir/value"
+	"github.com/pkg/errors"
 )
 
 // === [ Basic blocks ] ========================================================
@@ -53,6 +55,11 @@ func (block *BasicBlock) SetName(name string) {
 	block.LocalName = name
 }
 
+// Terminated reports whether the basic block has a terminator.
+func (block *BasicBlock) Terminated() bool {
+	return block.Term != nil
+}
+
 // Def returns the LLVM syntax representation of the basic block definition.
 func (block *BasicBlock) Def() string {
 	// OptLabelIdent Instructions Terminator
@@ -63,9 +70,93 @@ func (block *BasicBlock) Def() string {
 		// TODO: Store block name without ':' suffix or '%' prefix.
 		fmt.Fprintf(buf, "%v\n", enc.Label(block.LocalName))
 	}
+	buf.WriteString(instsString(block))
+	return buf.String()
+}
+
+// Phis returns the leading phi instructions of the basic block; i.e. the
+// contiguous sequence of phi instructions at the top of the basic block.
+func (block *BasicBlock) Phis() []*InstPhi {
+	var phis []*InstPhi
+	for _, inst := range block.Insts {
+		phi, ok := inst.(*InstPhi)
+		if !ok {
+			break
+		}
+		phis = append(phis, phi)
+	}
+	return phis
+}
+
+// FirstNonPhi returns the first non-phi instruction of the basic block, which
+// is the first valid insertion point for non-phi instructions; or nil if the
+// basic block contains only phi instructions (in which case non-phi
+// instructions are inserted before the terminator).
+func (block *BasicBlock) FirstNonPhi() Instruction {
 	for _, inst := range block.Insts {
-		fmt.Fprintf(buf, "\t%v\n", inst.Def())
+		if _, ok := inst.(*InstPhi); !ok {
+			return inst
+		}
 	}
-	fmt.Fprintf(buf, "\t%v", block.Term.Def())
+	return nil
+}
+
+// Validate reports an error if the basic block is invalid; i.e. if a phi
+// instruction follows a non-phi instruction of the basic block.
+func (block *BasicBlock) Validate() error {
+	nonPhi := false
+	for i, inst := range block.Insts {
+		if _, ok := inst.(*InstPhi); !ok {
+			nonPhi = true
+			continue
+		}
+		if nonPhi {
+			return errors.Errorf("invalid phi instruction at index %d of basic block %v; expected phi instructions before non-phi instructions", i, block.Ident())
+		}
+	}
+	return nil
+}
+
+// ### [ Helper functions ] ####################################################
+
+// appendInst appends the given instruction to the basic block. appendInst
+// panics if the basic block is already terminated, as instructions may not
+// follow the terminator of a basic block.
+func (block *BasicBlock) appendInst(inst Instruction) {
+	if block.Terminated() {
+		panic(fmt.Errorf("invalid %T instruction appended to basic block %v; basic block already terminated by %T", inst, block.Ident(), block.Term))
+	}
+	block.Insts = append(block.Insts, inst)
+}
+
+// setTerm sets the terminator of the basic block. setTerm panics if the basic
+// block is already terminated.
+func (block *BasicBlock) setTerm(term Terminator) {
+	if block.Terminated() {
+		panic(fmt.Errorf("invalid %T terminator of basic block %v; basic block already terminated by %T", term, block.Ident(), block.Term))
+	}
+	block.Term = term
+}
+
+// instsString returns the string representation of the instructions and
+// terminator of the given basic block, each indented by two spaces (as done by
+// LLVM).
+func instsString(block *BasicBlock) string {
+	buf := &strings.Builder{}
+	for _, inst := range block.Insts {
+		fmt.Fprintf(buf, "  %v\n", defString(inst))
+	}
+	fmt.Fprintf(buf, "  %v", defString(block.Term))
 	return buf.String()
 }
+
+// defString returns the string representation of the defining line of the
+// given instruction or terminator. Named non-void values are prefixed by their
+// identifier (e.g. "%x = load i32, i32* %p"), while void instructions and
+// unnamed values are not (e.g. "store i32 1, i32* %p").
+func defString(def Definer) string {
+	if n, ok := def.(value.Named); ok && !isVoidValue(n) && !isUnnamed(n.Name()) {
+		return fmt.Sprintf("%v = %v", n.Ident(), def.Def())
+	}
+	return def.Def()
+}
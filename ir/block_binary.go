@@ -12,7 +12,7 @@ import (
 // operands.
 func (block *BasicBlock) NewAdd(x, y value.Value) *InstAdd {
 	inst := NewAdd(x, y)
-	block.Insts = append(block.Insts, inst)
+	block.appendInst(inst)
 	return inst
 }
 
@@ -22,7 +22,7 @@ func (block *BasicBlock) NewAdd(x, y value.Value) *InstAdd {
 // operands.
 func (block *BasicBlock) NewFAdd(x, y value.Value) *InstFAdd {
 	inst := NewFAdd(x, y)
-	block.Insts = append(block.Insts, inst)
+	block.appendInst(inst)
 	return inst
 }
 
@@ -32,7 +32,7 @@ func (block *BasicBlock) NewFAdd(x, y value.Value) *InstFAdd {
 // operands.
 func (block *BasicBlock) NewSub(x, y value.Value) *InstSub {
 	inst := NewSub(x, y)
-	block.Insts = append(block.Insts, inst)
+	block.appendInst(inst)
 	return inst
 }
 
@@ -42,7 +42,7 @@ func (block *BasicBlock) NewSub(x, y value.Value) *InstSub {
 // operands.
 func (block *BasicBlock) NewFSub(x, y value.Value) *InstFSub {
 	inst := NewFSub(x, y)
-	block.Insts = append(block.Insts, inst)
+	block.appendInst(inst)
 	return inst
 }
 
@@ -52,7 +52,7 @@ func (block *BasicBlock) NewFSub(x, y value.Value) *InstFSub {
 // operands.
 func (block *BasicBlock) NewMul(x, y value.Value) *InstMul {
 	inst := NewMul(x, y)
-	block.Insts = append(block.Insts, inst)
+	block.appendInst(inst)
 	return inst
 }
 
@@ -62,7 +62,7 @@ func (block *BasicBlock) NewMul(x, y value.Value) *InstMul {
 // operands.
 func (block *BasicBlock) NewFMul(x, y value.Value) *InstFMul {
 	inst := NewFMul(x, y)
-	block.Insts = append(block.Insts, inst)
+	block.appendInst(inst)
 	return inst
 }
 
@@ -72,7 +72,7 @@ func (block *BasicBlock) NewFMul(x, y value.Value) *InstFMul {
 // operands.
 func (block *BasicBlock) NewUDiv(x, y value.Value) *InstUDiv {
 	inst := NewUDiv(x, y)
-	block.Insts = append(block.Insts, inst)
+	block.appendInst(inst)
 	return inst
 }
 
@@ -82,7 +82,7 @@ func (block *BasicBlock) NewUDiv(x, y value.Value) *InstUDiv {
 // operands.
 func (block *BasicBlock) NewSDiv(x, y value.Value) *InstSDiv {
 	inst := NewSDiv(x, y)
-	block.Insts = append(block.Insts, inst)
+	block.appendInst(inst)
 	return inst
 }
 
@@ -92,7 +92,7 @@ func (block *BasicBlock) NewSDiv(x, y value.Value) *InstSDiv {
 // operands.
 func (block *BasicBlock) NewFDiv(x, y value.Value) *InstFDiv {
 	inst := NewFDiv(x, y)
-	block.Insts = append(block.Insts, inst)
+	block.appendInst(inst)
 	return inst
 }
 
@@ -102,7 +102,7 @@ func (block *BasicBlock) NewFDiv(x, y value.Value) *InstFDiv {
 // operands.
 func (block *BasicBlock) NewURem(x, y value.Value) *InstURem {
 	inst := NewURem(x, y)
-	block.Insts = append(block.Insts, inst)
+	block.appendInst(inst)
 	return inst
 }
 
@@ -112,7 +112,7 @@ func (block *BasicBlock) NewURem(x, y value.Value) *InstURem {
 // operands.
 func (block *BasicBlock) NewSRem(x, y value.Value) *InstSRem {
 	inst := NewSRem(x, y)
-	block.Insts = append(block.Insts, inst)
+	block.appendInst(inst)
 	return inst
 }
 
@@ -122,6 +122,6 @@ func (block *BasicBlock) NewSRem(x, y value.Value) *InstSRem {
 // operands.
 func (block *BasicBlock) NewFRem(x, y value.Value) *InstFRem {
 	inst := NewFRem(x, y)
-	block.Insts = append(block.Insts, inst)
+	block.appendInst(inst)
 	return inst
 }
@@ -12,7 +12,7 @@ import (
 // operands.
 func (block *BasicBlock) NewShl(x, y value.Value) *InstShl {
 	inst := NewShl(x, y)
-	block.Insts = append(block.Insts, inst)
+	block.appendInst(inst)
 	return inst
 }
 
@@ -22,7 +22,7 @@ func (block *BasicBlock) NewShl(x, y value.Value) *InstShl {
 // operands.
 func (block *BasicBlock) NewLShr(x, y value.Value) *InstLShr {
 	inst := NewLShr(x, y)
-	block.Insts = append(block.Insts, inst)
+	block.appendInst(inst)
 	return inst
 }
 
@@ -32,7 +32,7 @@ func (block *BasicBlock) NewLShr(x, y value.Value) *InstLShr {
 // operands.
 func (block *BasicBlock) NewAShr(x, y value.Value) *InstAShr {
 	inst := NewAShr(x, y)
-	block.Insts = append(block.Insts, inst)
+	block.appendInst(inst)
 	return inst
 }
 
@@ -42,7 +42,7 @@ func (block *BasicBlock) NewAShr(x, y value.Value) *InstAShr {
 // operands.
 func (block *BasicBlock) NewAnd(x, y value.Value) *InstAnd {
 	inst := NewAnd(x, y)
-	block.Insts = append(block.Insts, inst)
+	block.appendInst(inst)
 	return inst
 }
 
@@ -52,7 +52,7 @@ func (block *BasicBlock) NewAnd(x, y value.Value) *InstAnd {
 // operands.
 func (block *BasicBlock) NewOr(x, y value.Value) *InstOr {
 	inst := NewOr(x, y)
-	block.Insts = append(block.Insts, inst)
+	block.appendInst(inst)
 	return inst
 }
 
@@ -62,6 +62,6 @@ func (block *BasicBlock) NewOr(x, y value.Value) *InstOr {
 // operands.
 func (block *BasicBlock) NewXor(x, y value.Value) *InstXor {
 	inst := NewXor(x, y)
-	block.Insts = append(block.Insts, inst)
+	block.appendInst(inst)
 	return inst
 }
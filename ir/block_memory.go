@@ -14,7 +14,7 @@ import (
 // given element type.
 func (block *BasicBlock) NewAlloca(elemType types.Type) *InstAlloca {
 	inst := NewAlloca(elemType)
-	block.Insts = append(block.Insts, inst)
+	block.appendInst(inst)
 	return inst
 }
 
@@ -24,7 +24,7 @@ func (block *BasicBlock) NewAlloca(elemType types.Type) *InstAlloca {
 // source address.
 func (block *BasicBlock) NewLoad(src value.Value) *InstLoad {
 	inst := NewLoad(src)
-	block.Insts = append(block.Insts, inst)
+	block.appendInst(inst)
 	return inst
 }
 
@@ -34,7 +34,7 @@ func (block *BasicBlock) NewLoad(src value.Value) *InstLoad {
 // given source value and destination address.
 func (block *BasicBlock) NewStore(src, dst value.Value) *InstStore {
 	inst := NewStore(src, dst)
-	block.Insts = append(block.Insts, inst)
+	block.appendInst(inst)
 	return inst
 }
 
@@ -44,7 +44,7 @@ func (block *BasicBlock) NewStore(src, dst value.Value) *InstStore {
 // given atomic ordering.
 func (block *BasicBlock) NewFence(ordering enum.AtomicOrdering) *InstFence {
 	inst := NewFence(ordering)
-	block.Insts = append(block.Insts, inst)
+	block.appendInst(inst)
 	return inst
 }
 
@@ -55,7 +55,7 @@ func (block *BasicBlock) NewFence(ordering enum.AtomicOrdering) *InstFence {
 // orderings for success and failure.
 func (block *BasicBlock) NewCmpXchg(ptr, cmp, new value.Value, success, failure enum.AtomicOrdering) *InstCmpXchg {
 	inst := NewCmpXchg(ptr, cmp, new, success, failure)
-	block.Insts = append(block.Insts, inst)
+	block.appendInst(inst)
 	return inst
 }
 
@@ -65,7 +65,7 @@ func (block *BasicBlock) NewCmpXchg(ptr, cmp, new value.Value, success, failure
 // the given atomic operation, destination address, operand and atomic ordering.
 func (block *BasicBlock) NewAtomicRMW(op enum.AtomicOp, dst, x value.Value, ordering enum.AtomicOrdering) *InstAtomicRMW {
 	inst := NewAtomicRMW(op, dst, x, ordering)
-	block.Insts = append(block.Insts, inst)
+	block.appendInst(inst)
 	return inst
 }
 
@@ -75,6 +75,15 @@ func (block *BasicBlock) NewAtomicRMW(op enum.AtomicOp, dst, x value.Value, orde
 // based on the given element type, source address and element indices.
 func (block *BasicBlock) NewGetElementPtr(elemType types.Type, src value.Value, indices ...value.Value) *InstGetElementPtr {
 	inst := NewGetElementPtr(elemType, src, indices...)
-	block.Insts = append(block.Insts, inst)
+	block.appendInst(inst)
+	return inst
+}
+
+// NewStructGEP appends a new getelementptr instruction to the basic block
+// computing the address of the given struct field, based on the given source
+// address (pointer to struct) and field index.
+func (block *BasicBlock) NewStructGEP(src value.Value, fieldIndex int) *InstGetElementPtr {
+	inst := NewStructGEP(src, fieldIndex)
+	block.appendInst(inst)
 	return inst
 }
@@ -14,7 +14,7 @@ import (
 // integer comparison predicate and integer scalar or vector operands.
 func (block *BasicBlock) NewICmp(pred enum.IPred, x, y value.Value) *InstICmp {
 	inst := NewICmp(pred, x, y)
-	block.Insts = append(block.Insts, inst)
+	block.appendInst(inst)
 	return inst
 }
 
@@ -25,7 +25,7 @@ func (block *BasicBlock) NewICmp(pred enum.IPred, x, y value.Value) *InstICmp {
 // operands.
 func (block *BasicBlock) NewFCmp(pred enum.FPred, x, y value.Value) *InstFCmp {
 	inst := NewFCmp(pred, x, y)
-	block.Insts = append(block.Insts, inst)
+	block.appendInst(inst)
 	return inst
 }
 
@@ -35,7 +35,7 @@ func (block *BasicBlock) NewFCmp(pred enum.FPred, x, y value.Value) *InstFCmp {
 // incoming values.
 func (block *BasicBlock) NewPhi(incs ...*Incoming) *InstPhi {
 	inst := NewPhi(incs...)
-	block.Insts = append(block.Insts, inst)
+	block.appendInst(inst)
 	return inst
 }
 
@@ -45,7 +45,17 @@ func (block *BasicBlock) NewPhi(incs ...*Incoming) *InstPhi {
 // given selection condition and operands.
 func (block *BasicBlock) NewSelect(cond, x, y value.Value) *InstSelect {
 	inst := NewSelect(cond, x, x)
-	block.Insts = append(block.Insts, inst)
+	block.appendInst(inst)
+	return inst
+}
+
+// ~~~ [ freeze ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+
+// NewFreeze appends a new freeze instruction to the basic block based on the
+// given operand.
+func (block *BasicBlock) NewFreeze(x value.Value) *InstFreeze {
+	inst := NewFreeze(x)
+	block.appendInst(inst)
 	return inst
 }
 
@@ -57,7 +67,7 @@ func (block *BasicBlock) NewSelect(cond, x, y value.Value) *InstSelect {
 // TODO: specify the set of underlying types of callee.
 func (block *BasicBlock) NewCall(callee value.Value, args ...Arg) *InstCall {
 	inst := NewCall(callee, args...)
-	block.Insts = append(block.Insts, inst)
+	block.appendInst(inst)
 	return inst
 }
 
@@ -67,7 +77,7 @@ func (block *BasicBlock) NewCall(callee value.Value, args ...Arg) *InstCall {
 // given variable argument list and argument type.
 func (block *BasicBlock) NewVAArg(vaList value.Value, argType types.Type) *InstVAArg {
 	inst := NewVAArg(vaList, argType)
-	block.Insts = append(block.Insts, inst)
+	block.appendInst(inst)
 	return inst
 }
 
@@ -77,7 +87,7 @@ func (block *BasicBlock) NewVAArg(vaList value.Value, argType types.Type) *InstV
 // on the given result type and filter/catch clauses.
 func (block *BasicBlock) NewLandingPad(resultType types.Type, clauses ...*enum.Clause) *InstLandingPad {
 	inst := NewLandingPad(resultType, clauses...)
-	block.Insts = append(block.Insts, inst)
+	block.appendInst(inst)
 	return inst
 }
 
@@ -87,7 +97,7 @@ func (block *BasicBlock) NewLandingPad(resultType types.Type, clauses ...*enum.C
 // the given exception scope and exception arguments.
 func (block *BasicBlock) NewCatchPad(scope *TermCatchSwitch, args ...Arg) *InstCatchPad {
 	inst := NewCatchPad(scope, args...)
-	block.Insts = append(block.Insts, inst)
+	block.appendInst(inst)
 	return inst
 }
 
@@ -97,6 +107,6 @@ func (block *BasicBlock) NewCatchPad(scope *TermCatchSwitch, args ...Arg) *InstC
 // on the given exception scope and exception arguments.
 func (block *BasicBlock) NewCleanupPad(scope enum.ExceptionScope, args ...Arg) *InstCleanupPad {
 	inst := NewCleanupPad(scope, args...)
-	block.Insts = append(block.Insts, inst)
+	block.appendInst(inst)
 	return inst
 }
@@ -2,6 +2,7 @@ package ir
 
 import (
 	"github.com/llir/l/ir/enum"
+	"github.com/llir/l/ir/types"
 	"github.com/llir/l/ir/value"
 )
 
@@ -11,9 +12,12 @@ import (
 
 // NewRet sets the terminator of the basic block to a new ret terminator based
 // on the given return value. A nil return value indicates a void return.
+//
+// NewRet, as all terminator helpers of basic blocks, panics if the basic block
+// is already terminated.
 func (block *BasicBlock) NewRet(x value.Value) *TermRet {
 	term := NewRet(x)
-	block.Term = term
+	block.setTerm(term)
 	return term
 }
 
@@ -23,7 +27,7 @@ func (block *BasicBlock) NewRet(x value.Value) *TermRet {
 // terminator based on the given target basic block.
 func (block *BasicBlock) NewBr(target *BasicBlock) *TermBr {
 	term := NewBr(target)
-	block.Term = term
+	block.setTerm(term)
 	return term
 }
 
@@ -34,7 +38,7 @@ func (block *BasicBlock) NewBr(target *BasicBlock) *TermBr {
 // basic blocks.
 func (block *BasicBlock) NewCondBr(cond value.Value, targetTrue, targetFalse *BasicBlock) *TermCondBr {
 	term := NewCondBr(cond, targetTrue, targetFalse)
-	block.Term = term
+	block.setTerm(term)
 	return term
 }
 
@@ -45,7 +49,17 @@ func (block *BasicBlock) NewCondBr(cond value.Value, targetTrue, targetFalse *Ba
 // cases.
 func (block *BasicBlock) NewSwitch(x value.Value, targetDefault *BasicBlock, cases ...*Case) *TermSwitch {
 	term := NewSwitch(x, targetDefault, cases...)
-	block.Term = term
+	block.setTerm(term)
+	return term
+}
+
+// NewSwitchFromMap sets the terminator of the basic block to a new switch
+// terminator based on the given control variable, default target basic block
+// and map from case values to target basic blocks. The case comparands are
+// integer constants of the given type, sorted by value.
+func (block *BasicBlock) NewSwitchFromMap(x value.Value, targetDefault *BasicBlock, cases map[int64]*BasicBlock, caseType *types.IntType) *TermSwitch {
+	term := NewSwitchFromMap(x, targetDefault, cases, caseType)
+	block.setTerm(term)
 	return term
 }
 
@@ -53,10 +67,11 @@ func (block *BasicBlock) NewSwitch(x value.Value, targetDefault *BasicBlock, cas
 
 // NewIndirectBr sets the terminator of the basic block to a new indirectbr
 // terminator based on the given target address (derived from a blockaddress
-// constant) and set of valid target basic blocks.
-func (block *BasicBlock) NewIndirectBr(addr *ConstBlockAddress, validTargets ...*BasicBlock) *TermIndirectBr {
+// constant; e.g. loaded from a table of blockaddress constants in computed
+// goto) and set of valid target basic blocks.
+func (block *BasicBlock) NewIndirectBr(addr value.Value, validTargets ...*BasicBlock) *TermIndirectBr {
 	term := NewIndirectBr(addr, validTargets...)
-	block.Term = term
+	block.setTerm(term)
 	return term
 }
 
@@ -69,7 +84,18 @@ func (block *BasicBlock) NewIndirectBr(addr *ConstBlockAddress, validTargets ...
 // TODO: specify the set of underlying types of invokee.
 func (block *BasicBlock) NewInvoke(invokee value.Value, args []Arg, normal, exception *BasicBlock) *TermInvoke {
 	term := NewInvoke(invokee, args, normal, exception)
-	block.Term = term
+	block.setTerm(term)
+	return term
+}
+
+// ~~~ [ callbr ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+
+// NewCallBr sets the terminator of the basic block to a new callbr terminator
+// based on the given callee, function arguments and control flow return points
+// for default and indirect execution.
+func (block *BasicBlock) NewCallBr(callee value.Value, args []Arg, defaultTarget *BasicBlock, indirectTargets ...*BasicBlock) *TermCallBr {
+	term := NewCallBr(callee, args, defaultTarget, indirectTargets...)
+	block.setTerm(term)
 	return term
 }
 
@@ -79,7 +105,7 @@ func (block *BasicBlock) NewInvoke(invokee value.Value, args []Arg, normal, exce
 // based on the given exception argument to propagate.
 func (block *BasicBlock) NewResume(x value.Value) *TermResume {
 	term := NewResume(x)
-	block.Term = term
+	block.setTerm(term)
 	return term
 }
 
@@ -90,7 +116,7 @@ func (block *BasicBlock) NewResume(x value.Value) *TermResume {
 // target.
 func (block *BasicBlock) NewCatchSwitch(scope enum.ExceptionScope, handlers []*BasicBlock, unwindTarget enum.UnwindTarget) *TermCatchSwitch {
 	term := NewCatchSwitch(scope, handlers, unwindTarget)
-	block.Term = term
+	block.setTerm(term)
 	return term
 }
 
@@ -100,7 +126,7 @@ func (block *BasicBlock) NewCatchSwitch(scope enum.ExceptionScope, handlers []*B
 // terminator based on the given exit catchpad and target basic block.
 func (block *BasicBlock) NewCatchRet(from *InstCatchPad, to *BasicBlock) *TermCatchRet {
 	term := NewCatchRet(from, to)
-	block.Term = term
+	block.setTerm(term)
 	return term
 }
 
@@ -110,7 +136,7 @@ func (block *BasicBlock) NewCatchRet(from *InstCatchPad, to *BasicBlock) *TermCa
 // terminator based on the given exit cleanuppad and unwind target.
 func (block *BasicBlock) NewCleanupRet(from *InstCleanupPad, to enum.UnwindTarget) *TermCleanupRet {
 	term := NewCleanupRet(from, to)
-	block.Term = term
+	block.setTerm(term)
 	return term
 }
 
@@ -120,6 +146,6 @@ func (block *BasicBlock) NewCleanupRet(from *InstCleanupPad, to enum.UnwindTarge
 // terminator.
 func (block *BasicBlock) NewUnreachable() *TermUnreachable {
 	term := NewUnreachable()
-	block.Term = term
+	block.setTerm(term)
 	return term
 }
@@ -0,0 +1,214 @@
+package ir
+
+import (
+	"testing"
+
+	"github.com/llir/l/ir/enum"
+	"github.com/llir/l/ir/types"
+	"github.com/llir/l/ir/value"
+)
+
+func TestBlockIdent(t *testing.T) {
+	golden := []struct {
+		in   *BasicBlock
+		want string
+	}{
+		// Named basic block.
+		{in: NewBlock("entry"), want: "%entry"},
+		// Numeric basic block.
+		{in: NewBlock("42"), want: "%42"},
+		// Basic block with special characters.
+		{in: NewBlock("a b"), want: `%"a\20b"`},
+	}
+	for _, g := range golden {
+		if got := g.in.Ident(); g.want != got {
+			t.Errorf("basic block %q identifier mismatch; expected `%v`, got `%v`", g.in.Name(), g.want, got)
+		}
+	}
+}
+
+func TestBlockAssignIDs(t *testing.T) {
+	entry := NewBlock("")
+	exit := NewBlock("")
+	entry.NewBr(exit)
+	exit.NewRet(nil)
+	f := &Function{
+		GlobalName: "f",
+		Sig:        types.NewFunc(types.Void),
+		Blocks:     []*BasicBlock{entry, exit},
+	}
+	if err := f.AssignIDs(); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "%0", entry.Ident(); want != got {
+		t.Errorf("entry basic block identifier mismatch; expected `%v`, got `%v`", want, got)
+	}
+	if want, got := "%1", exit.Ident(); want != got {
+		t.Errorf("exit basic block identifier mismatch; expected `%v`, got `%v`", want, got)
+	}
+	if want, got := "br label %1", entry.Term.Def(); want != got {
+		t.Errorf("branch mismatch; expected `%v`, got `%v`", want, got)
+	}
+}
+
+func TestBlockDef(t *testing.T) {
+	p := NewParam(types.NewPointer(types.I32), "p")
+	block := NewBlock("entry")
+	x := block.NewLoad(p)
+	x.SetName("x")
+	block.NewStore(x, p)
+	block.NewRet(x)
+	// Rendering of the load instruction as an operand and as a definition.
+	if want, got := "%x", x.Ident(); want != got {
+		t.Errorf("load identifier mismatch; expected `%v`, got `%v`", want, got)
+	}
+	if want, got := "load i32, i32* %p", x.Def(); want != got {
+		t.Errorf("load definition mismatch; expected `%v`, got `%v`", want, got)
+	}
+	// Rendering of the defining lines of the basic block; the store instruction
+	// is void and thus has no "%x =" prefix.
+	want := "entry:\n  %x = load i32, i32* %p\n  store i32 %x, i32* %p\n  ret i32 %x"
+	if got := block.Def(); want != got {
+		t.Errorf("basic block mismatch; expected `%v`, got `%v`", want, got)
+	}
+}
+
+func TestBlockTerminated(t *testing.T) {
+	x := NewParam(types.NewStruct(types.I32, types.I8), "x")
+	block := NewBlock("entry")
+	v := block.NewExtractValue(x, 0)
+	if block.Terminated() {
+		t.Errorf("expected unterminated basic block")
+	}
+	if len(block.Insts) != 1 || block.Insts[0] != v {
+		t.Errorf("instruction mismatch; expected [%v], got %v", v.Def(), block.Insts)
+	}
+	block.NewRet(v)
+	if !block.Terminated() {
+		t.Errorf("expected terminated basic block")
+	}
+	// Append instruction after terminator.
+	defer func() {
+		if e := recover(); e == nil {
+			t.Errorf("expected panic for instruction appended to terminated basic block")
+		}
+		if len(block.Insts) != 1 {
+			t.Errorf("invalid number of instructions; expected 1, got %d", len(block.Insts))
+		}
+	}()
+	block.NewAdd(NewInt(types.I32, 1), NewInt(types.I32, 2))
+}
+
+func TestBlockNewRet(t *testing.T) {
+	golden := []struct {
+		x    value.Value
+		want string
+	}{
+		// Void return.
+		{x: nil, want: "ret void"},
+		// Valued return.
+		{x: NewInt(types.I32, 42), want: "ret i32 42"},
+	}
+	for _, g := range golden {
+		block := NewBlock("entry")
+		term := block.NewRet(g.x)
+		if block.Term != term {
+			t.Errorf("terminator mismatch; expected %v, got %v", term.Def(), block.Term)
+		}
+		if got := block.Term.Def(); g.want != got {
+			t.Errorf("terminator mismatch; expected `%v`, got `%v`", g.want, got)
+		}
+	}
+	// Terminate basic block twice.
+	block := NewBlock("entry")
+	block.NewRet(nil)
+	defer func() {
+		if e := recover(); e == nil {
+			t.Errorf("expected panic for terminator of terminated basic block")
+		}
+	}()
+	block.NewRet(nil)
+}
+
+func TestBlockBranches(t *testing.T) {
+	// if/else control flow graph.
+	x := NewParam(types.I32, "x")
+	entry := NewBlock("entry")
+	ifTrue := NewBlock("if.true")
+	ifFalse := NewBlock("if.false")
+	exit := NewBlock("exit")
+	cond := entry.NewICmp(enum.IPredSLT, x, NewInt(types.I32, 0))
+	cond.SetName("cond")
+	if term := entry.NewCondBr(cond, ifTrue, ifFalse); entry.Term != term {
+		t.Errorf("terminator mismatch; expected %v, got %v", term.Def(), entry.Term)
+	}
+	if term := ifTrue.NewBr(exit); ifTrue.Term != term {
+		t.Errorf("terminator mismatch; expected %v, got %v", term.Def(), ifTrue.Term)
+	}
+	ifFalse.NewBr(exit)
+	exit.NewRet(nil)
+	f := NewFunction("f", types.Void, x)
+	f.Blocks = []*BasicBlock{entry, ifTrue, ifFalse, exit}
+	want := `define void @f(i32 %x) {
+entry:
+  %cond = icmp slt i32 %x, 0
+  br i1 %cond, label %if.true, label %if.false
+
+if.true:                                          ; preds = %entry
+  br label %exit
+
+if.false:                                         ; preds = %entry
+  br label %exit
+
+exit:                                             ; preds = %if.false, %if.true
+  ret void
+}`
+	if got := f.Def(); want != got {
+		t.Errorf("function mismatch; expected `%v`, got `%v`", want, got)
+	}
+	// Non-boolean branching condition.
+	defer func() {
+		if e := recover(); e == nil {
+			t.Errorf("expected panic for non-i1 branching condition")
+		}
+	}()
+	NewBlock("").NewCondBr(x, ifTrue, ifFalse)
+}
+
+func TestBlockPhis(t *testing.T) {
+	x := NewParam(types.I32, "x")
+	pred := NewBlock("pred")
+	block := NewBlock("block")
+	a := block.NewPhi(NewIncoming(x, pred))
+	b := block.NewPhi(NewIncoming(x, pred))
+	add := block.NewAdd(a, b)
+	block.NewRet(add)
+	phis := block.Phis()
+	if len(phis) != 2 || phis[0] != a || phis[1] != b {
+		t.Errorf("phi instructions mismatch; expected [%p %p], got %v", a, b, phis)
+	}
+	if got := block.FirstNonPhi(); got != add {
+		t.Errorf("first non-phi instruction mismatch; expected %p, got %v", add, got)
+	}
+	if err := block.Validate(); err != nil {
+		t.Errorf("unexpected error for basic block %v; %v", block.Ident(), err)
+	}
+	// Only phi instructions.
+	block.Insts = block.Insts[:2]
+	if got := block.FirstNonPhi(); got != nil {
+		t.Errorf("first non-phi instruction mismatch; expected nil, got %v", got)
+	}
+	// Phi instruction after non-phi instruction.
+	block.Insts = []Instruction{a, add, b}
+	if phis := block.Phis(); len(phis) != 1 || phis[0] != a {
+		t.Errorf("phi instructions mismatch; expected [%p], got %v", a, phis)
+	}
+	want := "invalid phi instruction at index 2 of basic block %block; expected phi instructions before non-phi instructions"
+	err := block.Validate()
+	if err == nil {
+		t.Fatalf("expected error for misordered phi instructions, got nil")
+	}
+	if got := err.Error(); want != got {
+		t.Errorf("error mismatch; expected `%v`, got `%v`", want, got)
+	}
+}
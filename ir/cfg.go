@@ -0,0 +1,669 @@
+package ir
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/llir/l/ir/enum"
+	"github.com/llir/l/ir/value"
+)
+
+// MergeIdenticalBlocks merges basic blocks with structurally identical
+// instructions and terminators, redirecting the predecessors of each duplicate
+// basic block to the first basic block of identical structure, and removing
+// the duplicate. The number of removed basic blocks is returned.
+//
+// Basic blocks are only merged if it requires no new phi instructions; i.e.
+// basic blocks containing phi instructions, basic blocks with instruction
+// results used outside of the basic block, and basic blocks for which the phi
+// instructions of a successor have differing incoming values are never merged.
+// Furthermore, the entry basic block and basic blocks referenced by blockaddress
+// constants of the function are never removed, and only predecessors
+// terminated by br, conditional br and switch terminators are redirected.
+//
+// Blockaddress constants outside of the function (e.g. in the initializers of
+// global variables) are not known to the function; use
+// Module.MergeIdenticalBlocks to keep the basic blocks they reference.
+func (f *Function) MergeIdenticalBlocks() int {
+	return f.mergeIdenticalBlocks(nil)
+}
+
+// mergeIdenticalBlocks merges identical basic blocks of the function (see
+// MergeIdenticalBlocks), keeping the given basic blocks referenced by
+// blockaddress constants outside of the function.
+func (f *Function) mergeIdenticalBlocks(external map[*BasicBlock]bool) int {
+	n := 0
+	for f.mergeIdenticalBlock(external) {
+		n++
+	}
+	return n
+}
+
+// mergeIdenticalBlock merges the first duplicate basic block of the function
+// with its identical basic block. The boolean return value indicates whether a
+// basic block was merged.
+func (f *Function) mergeIdenticalBlock(external map[*BasicBlock]bool) bool {
+	seen := make(map[string]*BasicBlock)
+	taken := addressTakenBlocks(f, external)
+	for i, block := range f.Blocks {
+		// Skip the entry basic block, as it may not have predecessors.
+		if i == 0 || !f.isMergeableBlock(block) {
+			continue
+		}
+		key := blockKey(block)
+		prev, ok := seen[key]
+		if !ok {
+			seen[key] = block
+			continue
+		}
+		// Keep basic blocks referenced by blockaddress constants.
+		if taken[block] {
+			continue
+		}
+		if !samePhiIncomings(prev, block) || !f.redirectPreds(block, prev) {
+			continue
+		}
+		// Remove incoming values of the duplicate basic block from the phi
+		// instructions of successors.
+		for _, succ := range block.Term.Succs() {
+			for _, inst := range succ.Insts {
+				if phi, ok := inst.(*InstPhi); ok {
+					phi.Incs = removeIncomings(phi.Incs, block)
+				}
+			}
+		}
+		f.Blocks = append(f.Blocks[:i:i], f.Blocks[i+1:]...)
+		return true
+	}
+	return false
+}
+
+// isMergeableBlock reports whether the given basic block may be merged with an
+// identical basic block without introducing phi instructions; i.e. whether the
+// basic block contains no phi instructions and has no instruction results used
+// outside of the basic block.
+func (f *Function) isMergeableBlock(block *BasicBlock) bool {
+	if block.Term == nil {
+		return false
+	}
+	local := make(map[value.Value]bool)
+	for _, inst := range block.Insts {
+		if _, ok := inst.(*InstPhi); ok {
+			return false
+		}
+		if v, ok := inst.(value.Value); ok {
+			local[v] = true
+		}
+	}
+	if len(local) == 0 {
+		return true
+	}
+	for _, other := range f.Blocks {
+		if other == block {
+			continue
+		}
+		for _, inst := range other.Insts {
+			for _, op := range inst.Operands() {
+				if local[*op] {
+					return false
+				}
+			}
+		}
+		if other.Term != nil {
+			for _, op := range other.Term.Operands() {
+				if local[*op] {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// redirectPreds redirects the predecessors of the old basic block to the new
+// basic block. The boolean return value indicates success; the predecessors
+// are left unchanged if any predecessor has a terminator other than br,
+// conditional br or switch.
+func (f *Function) redirectPreds(old, new *BasicBlock) bool {
+	preds := predecessors(f, old)
+	for _, pred := range preds {
+		switch pred.Term.(type) {
+		case *TermBr, *TermCondBr, *TermSwitch:
+			// supported terminator.
+		default:
+			return false
+		}
+	}
+	for _, pred := range preds {
+		switch term := pred.Term.(type) {
+		case *TermBr:
+			term.Target = new
+			term.Successors = nil
+		case *TermCondBr:
+			if term.TargetTrue == old {
+				term.TargetTrue = new
+			}
+			if term.TargetFalse == old {
+				term.TargetFalse = new
+			}
+			term.Successors = nil
+		case *TermSwitch:
+			if term.TargetDefault == old {
+				term.TargetDefault = new
+			}
+			for _, c := range term.Cases {
+				if c.Target == old {
+					c.Target = new
+				}
+			}
+			term.Successors = nil
+		}
+	}
+	return true
+}
+
+// MergeIdenticalBlocks merges identical basic blocks of each function of the
+// module (see Function.MergeIdenticalBlocks), keeping basic blocks referenced by
+// blockaddress constants anywhere in the module (e.g. in the initializers of
+// global variables). The number of removed basic blocks is returned.
+func (m *Module) MergeIdenticalBlocks() int {
+	taken := moduleAddressTakenBlocks(m)
+	n := 0
+	for _, f := range m.Funcs {
+		n += f.mergeIdenticalBlocks(taken)
+	}
+	return n
+}
+
+// FoldTrivialBranches folds each basic block into its predecessor, if the
+// predecessor is the only predecessor of the basic block and is terminated by
+// an unconditional br to the basic block. The number of folded basic blocks is
+// returned.
+//
+// The instructions and terminator of the folded basic block are moved to its
+// predecessor, and the phi instructions of the folded basic block (which have a
+// single incoming value) are replaced by their incoming value. Basic blocks
+// referenced by blockaddress constants of the function are never folded.
+//
+// Blockaddress constants outside of the function (e.g. in the initializers of
+// global variables) are not known to the function; use
+// Module.FoldTrivialBranches to keep the basic blocks they reference.
+func (f *Function) FoldTrivialBranches() int {
+	return f.foldTrivialBranches(nil)
+}
+
+// foldTrivialBranches folds trivial branches of the function (see
+// FoldTrivialBranches), keeping the given basic blocks referenced by
+// blockaddress constants outside of the function.
+func (f *Function) foldTrivialBranches(external map[*BasicBlock]bool) int {
+	n := 0
+	taken := addressTakenBlocks(f, external)
+	for i := 1; i < len(f.Blocks); i++ {
+		block := f.Blocks[i]
+		if taken[block] {
+			continue
+		}
+		preds := predecessors(f, block)
+		if len(preds) != 1 || preds[0] == block {
+			continue
+		}
+		pred := preds[0]
+		if _, ok := pred.Term.(*TermBr); !ok {
+			continue
+		}
+		for _, inst := range block.Insts {
+			if phi, ok := inst.(*InstPhi); ok {
+				f.ReplaceAll(phi, phi.Incs[0].X)
+				continue
+			}
+			pred.Insts = append(pred.Insts, inst)
+		}
+		pred.Term = block.Term
+		// Update the predecessor of incoming values of successors.
+		for _, succ := range block.Term.Succs() {
+			for _, inst := range succ.Insts {
+				if phi, ok := inst.(*InstPhi); ok {
+					for _, inc := range phi.Incs {
+						if inc.Pred == block {
+							inc.Pred = pred
+						}
+					}
+				}
+			}
+		}
+		f.Blocks = append(f.Blocks[:i:i], f.Blocks[i+1:]...)
+		// Revisit the basic block at the same index.
+		i--
+		n++
+	}
+	return n
+}
+
+// FoldTrivialBranches folds trivial branches of each function of the module
+// (see Function.FoldTrivialBranches), keeping basic blocks referenced by
+// blockaddress constants anywhere in the module (e.g. in the initializers of
+// global variables). The number of folded basic blocks is returned.
+func (m *Module) FoldTrivialBranches() int {
+	taken := moduleAddressTakenBlocks(m)
+	n := 0
+	for _, f := range m.Funcs {
+		n += f.foldTrivialBranches(taken)
+	}
+	return n
+}
+
+// LowerTrivialSwitches lowers each switch terminator with a single case to an
+// icmp eq instruction comparing the switch condition against the case
+// comparand, followed by a conditional br terminator to the case and default
+// target basic blocks. The number of lowered switch terminators is returned.
+//
+// The icmp instruction is appended to the instructions of the basic block, and
+// metadata attachments of the switch terminator are retained. The !prof branch
+// weights of the switch (default target first) are reordered to match the
+// conditional br (case target first). A switch with identical case and default
+// target basic blocks is lowered to an unconditional br terminator instead,
+// without !prof metadata, and the duplicate incoming values of phi instructions
+// of the target basic block are removed.
+func (f *Function) LowerTrivialSwitches() int {
+	n := 0
+	for _, block := range f.Blocks {
+		term, ok := block.Term.(*TermSwitch)
+		if !ok || len(term.Cases) != 1 {
+			continue
+		}
+		c := term.Cases[0]
+		if c.Target == term.TargetDefault {
+			// Keep a single incoming value per phi instruction, now that the
+			// target basic block is reached by a single edge from block.
+			for _, inst := range c.Target.Insts {
+				if phi, ok := inst.(*InstPhi); ok {
+					phi.Incs = dedupIncomings(phi.Incs, block)
+				}
+			}
+			br := NewBr(c.Target)
+			br.Metadata = lowerSwitchMetadata(term.Metadata, false)
+			block.Term = br
+			n++
+			continue
+		}
+		cond := NewICmp(enum.IPredEQ, term.X, c.X)
+		block.Insts = append(block.Insts, cond)
+		br := NewCondBr(cond, c.Target, term.TargetDefault)
+		br.Metadata = lowerSwitchMetadata(term.Metadata, true)
+		block.Term = br
+		n++
+	}
+	return n
+}
+
+// RemoveUnreachableBlocks removes the basic blocks of the function which are
+// not reachable from the entry basic block. The number of removed basic blocks
+// is returned.
+//
+// Incoming values of phi instructions in the remaining basic blocks are removed
+// if their predecessor basic block was removed.
+//
+// Basic blocks referenced by blockaddress constants of the function are kept,
+// as are the basic blocks reachable from them. Blockaddress constants outside
+// of the function (e.g. in the initializers of global variables) are not known
+// to the function; use Module.RemoveUnreachableBlocks to keep the basic blocks
+// they reference.
+func (f *Function) RemoveUnreachableBlocks() int {
+	return f.removeUnreachableBlocks(nil)
+}
+
+// removeUnreachableBlocks removes unreachable basic blocks of the function (see
+// RemoveUnreachableBlocks), keeping the given basic blocks referenced by
+// blockaddress constants outside of the function.
+func (f *Function) removeUnreachableBlocks(external map[*BasicBlock]bool) int {
+	if len(f.Blocks) == 0 {
+		return 0
+	}
+	reachable := reachableBlocks(f.Blocks[0])
+	for block := range addressTakenBlocks(f, external) {
+		if reachable[block] {
+			continue
+		}
+		for b := range reachableBlocks(block) {
+			reachable[b] = true
+		}
+	}
+	var keep []*BasicBlock
+	for _, block := range f.Blocks {
+		if reachable[block] {
+			keep = append(keep, block)
+		}
+	}
+	n := len(f.Blocks) - len(keep)
+	if n == 0 {
+		return 0
+	}
+	for _, block := range keep {
+		for _, inst := range block.Insts {
+			phi, ok := inst.(*InstPhi)
+			if !ok {
+				continue
+			}
+			var incs []*Incoming
+			for _, inc := range phi.Incs {
+				if reachable[inc.Pred] {
+					incs = append(incs, inc)
+				}
+			}
+			phi.Incs = incs
+		}
+	}
+	f.Blocks = keep
+	return n
+}
+
+// RemoveUnreachableBlocks removes unreachable basic blocks of each function of
+// the module (see Function.RemoveUnreachableBlocks), keeping basic blocks
+// referenced by blockaddress constants anywhere in the module (e.g. in the
+// initializers of global variables). The number of removed basic blocks is
+// returned.
+func (m *Module) RemoveUnreachableBlocks() int {
+	taken := moduleAddressTakenBlocks(m)
+	n := 0
+	for _, f := range m.Funcs {
+		n += f.removeUnreachableBlocks(taken)
+	}
+	return n
+}
+
+// ### [ Helper functions ] ####################################################
+
+// lowerSwitchMetadata returns the metadata attachments of the br terminator
+// lowered from a switch terminator with a single case and the given metadata
+// attachments. For conditional br terminators, the two !prof branch weights
+// are swapped, as the switch lists the default target first while the
+// conditional br lists the case target first; any other !prof metadata is
+// dropped, as is !prof metadata of unconditional br terminators.
+func lowerSwitchMetadata(mds []MetadataAttachment, cond bool) []MetadataAttachment {
+	var lowered []MetadataAttachment
+	for _, md := range mds {
+		if md.Name != "prof" {
+			lowered = append(lowered, md)
+			continue
+		}
+		if !cond {
+			continue
+		}
+		weights, ok := md.Node.(*MDTuple)
+		if !ok || len(weights.Fields) != 3 || weights.Fields[0] != MDString("branch_weights") {
+			continue
+		}
+		swapped := NewMDTuple(weights.Fields[0], weights.Fields[2], weights.Fields[1])
+		lowered = append(lowered, MetadataAttachment{Name: md.Name, Node: swapped})
+	}
+	return lowered
+}
+
+// predecessors returns the predecessor basic blocks of the given basic block,
+// in order of occurrence in the function.
+func predecessors(f *Function, block *BasicBlock) []*BasicBlock {
+	var preds []*BasicBlock
+	for _, pred := range f.Blocks {
+		if pred.Term == nil {
+			continue
+		}
+		for _, succ := range pred.Term.Succs() {
+			if succ == block {
+				preds = append(preds, pred)
+				break
+			}
+		}
+	}
+	return preds
+}
+
+// addressTakenBlocks returns the set of basic blocks referenced by blockaddress
+// constants in the operands of the instructions and terminators of the given
+// function, including blockaddress constants nested in constant expressions and
+// aggregate constants, and the given basic blocks referenced by blockaddress
+// constants outside of the function.
+func addressTakenBlocks(f *Function, external map[*BasicBlock]bool) map[*BasicBlock]bool {
+	taken := make(map[*BasicBlock]bool)
+	for block := range external {
+		taken[block] = true
+	}
+	visit := func(ops []*value.Value) {
+		for _, op := range ops {
+			if c, ok := (*op).(Constant); ok {
+				walkBlockAddresses(c, func(addr *ConstBlockAddress) {
+					taken[addr.Block] = true
+				})
+			}
+		}
+	}
+	for _, block := range f.Blocks {
+		for _, inst := range block.Insts {
+			visit(inst.Operands())
+		}
+		if block.Term != nil {
+			visit(block.Term.Operands())
+		}
+	}
+	return taken
+}
+
+// moduleAddressTakenBlocks returns the set of basic blocks referenced by
+// blockaddress constants of the given module; i.e. in the initializers of
+// global variables, the prefix, prologue and personality of functions, and the
+// operands of instructions and terminators.
+func moduleAddressTakenBlocks(m *Module) map[*BasicBlock]bool {
+	taken := make(map[*BasicBlock]bool)
+	visit := func(c Constant) {
+		if c != nil {
+			walkBlockAddresses(c, func(addr *ConstBlockAddress) {
+				taken[addr.Block] = true
+			})
+		}
+	}
+	for _, g := range m.Globals {
+		visit(g.Init)
+	}
+	for _, f := range m.Funcs {
+		visit(f.Prefix)
+		visit(f.Prologue)
+		visit(f.Personality)
+		for block := range addressTakenBlocks(f, nil) {
+			taken[block] = true
+		}
+	}
+	return taken
+}
+
+// walkBlockAddresses invokes f for each blockaddress constant contained in the
+// given constant; i.e. the constant itself, or the operands of constant
+// expressions and elements of aggregate constants. Global variables and
+// functions are not traversed.
+func walkBlockAddresses(c Constant, f func(addr *ConstBlockAddress)) {
+	switch c := c.(type) {
+	case *ConstBlockAddress:
+		f(c)
+		return
+	case *Global, *Function:
+		return
+	}
+	var walk func(v reflect.Value)
+	walk = func(v reflect.Value) {
+		if v.Type().Implements(typeType) {
+			return
+		}
+		switch v.Kind() {
+		case reflect.Interface, reflect.Ptr:
+			if v.IsNil() {
+				return
+			}
+			if c, ok := v.Interface().(Constant); ok {
+				walkBlockAddresses(c, f)
+				return
+			}
+			if v.Kind() == reflect.Ptr && v.Elem().Kind() == reflect.Struct {
+				// e.g. getelementptr expression indices.
+				walk(v.Elem())
+			}
+		case reflect.Struct:
+			for i := 0; i < v.NumField(); i++ {
+				if v.Type().Field(i).PkgPath != "" {
+					// Unexported field.
+					continue
+				}
+				walk(v.Field(i))
+			}
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < v.Len(); i++ {
+				walk(v.Index(i))
+			}
+		}
+	}
+	if v := reflect.ValueOf(c); v.Kind() == reflect.Ptr && v.Elem().Kind() == reflect.Struct {
+		walk(v.Elem())
+	}
+}
+
+// reachableBlocks returns the set of basic blocks reachable from the given
+// entry basic block, including the entry basic block itself.
+func reachableBlocks(entry *BasicBlock) map[*BasicBlock]bool {
+	reachable := map[*BasicBlock]bool{entry: true}
+	stack := []*BasicBlock{entry}
+	for len(stack) > 0 {
+		block := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if block.Term == nil {
+			continue
+		}
+		for _, succ := range block.Term.Succs() {
+			if !reachable[succ] {
+				reachable[succ] = true
+				stack = append(stack, succ)
+			}
+		}
+	}
+	return reachable
+}
+
+// blockKey returns a key uniquely identifying the structure of the given basic
+// block. Structurally identical basic blocks have the same key.
+//
+// Local values defined within the basic block are identified by position,
+// while all other operands are identified as by instKey.
+func blockKey(block *BasicBlock) string {
+	// Temporarily name local values by position, to render operands defined
+	// within the basic block identically across basic blocks.
+	local := make(map[value.Value]int)
+	var names []string
+	for i, inst := range block.Insts {
+		if n, ok := inst.(value.Named); ok {
+			local[n] = i
+			names = append(names, n.Name())
+			n.SetName(fmt.Sprintf("local.%d", i))
+		}
+	}
+	defer func() {
+		j := 0
+		for _, inst := range block.Insts {
+			if n, ok := inst.(value.Named); ok {
+				n.SetName(names[j])
+				j++
+			}
+		}
+	}()
+	buf := &strings.Builder{}
+	writeOps := func(ops []*value.Value) {
+		for _, op := range ops {
+			if c, ok := (*op).(Constant); ok {
+				fmt.Fprintf(buf, "; %v", c)
+			} else if i, ok := local[*op]; ok {
+				fmt.Fprintf(buf, "; local %d", i)
+			} else {
+				fmt.Fprintf(buf, "; %p", *op)
+			}
+		}
+		buf.WriteString("\n")
+	}
+	for _, inst := range block.Insts {
+		fmt.Fprintf(buf, "%T %v", inst, inst.Def())
+		writeOps(inst.Operands())
+	}
+	fmt.Fprintf(buf, "%T %v", block.Term, block.Term.Def())
+	for _, succ := range block.Term.Succs() {
+		fmt.Fprintf(buf, "; %p", succ)
+	}
+	writeOps(block.Term.Operands())
+	return buf.String()
+}
+
+// samePhiIncomings reports whether the phi instructions of the successors of
+// the given basic blocks have identical incoming values for a and b.
+func samePhiIncomings(a, b *BasicBlock) bool {
+	for _, succ := range a.Term.Succs() {
+		for _, inst := range succ.Insts {
+			phi, ok := inst.(*InstPhi)
+			if !ok {
+				continue
+			}
+			var x, y value.Value
+			for _, inc := range phi.Incs {
+				switch inc.Pred {
+				case a:
+					x = inc.X
+				case b:
+					y = inc.X
+				}
+			}
+			if !sameValue(x, y) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// sameValue reports whether the given values are identical, or structurally
+// identical constants.
+func sameValue(x, y value.Value) bool {
+	if x == y {
+		return true
+	}
+	c, ok := x.(Constant)
+	if !ok {
+		return false
+	}
+	d, ok := y.(Constant)
+	if !ok {
+		return false
+	}
+	return c.String() == d.String()
+}
+
+// dedupIncomings returns the given incoming values of a phi instruction, with
+// only the first incoming value from the given predecessor basic block kept.
+func dedupIncomings(incs []*Incoming, pred *BasicBlock) []*Incoming {
+	var keep []*Incoming
+	seen := false
+	for _, inc := range incs {
+		if inc.Pred == pred {
+			if seen {
+				continue
+			}
+			seen = true
+		}
+		keep = append(keep, inc)
+	}
+	return keep
+}
+
+// removeIncomings returns the given incoming values of a phi instruction,
+// without the incoming values from the given predecessor basic block.
+func removeIncomings(incs []*Incoming, pred *BasicBlock) []*Incoming {
+	var keep []*Incoming
+	for _, inc := range incs {
+		if inc.Pred != pred {
+			keep = append(keep, inc)
+		}
+	}
+	return keep
+}
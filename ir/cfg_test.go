@@ -0,0 +1,289 @@
+package ir
+
+import (
+	"testing"
+
+	"github.com/llir/l/ir/types"
+)
+
+func TestMergeIdenticalBlocks(t *testing.T) {
+	// Identical return basic blocks.
+	cond := NewParam(types.I1, "cond")
+	entry := NewBlock("entry")
+	a := NewBlock("a")
+	b := NewBlock("b")
+	entry.NewCondBr(cond, a, b)
+	x := a.NewAdd(NewInt(types.I32, 1), NewInt(types.I32, 2))
+	a.NewRet(a.NewMul(x, x))
+	y := b.NewAdd(NewInt(types.I32, 1), NewInt(types.I32, 2))
+	b.NewRet(b.NewMul(y, y))
+	f := NewFunction("f", types.I32, cond)
+	f.Blocks = []*BasicBlock{entry, a, b}
+	if n := f.MergeIdenticalBlocks(); n != 1 {
+		t.Errorf("number of merged basic blocks mismatch; expected 1, got %d", n)
+	}
+	if len(f.Blocks) != 2 || f.Blocks[1] != a {
+		t.Errorf("basic block %v not merged", b.Ident())
+	}
+	if want, got := "br i1 %cond, label %a, label %a", entry.Term.Def(); want != got {
+		t.Errorf("terminator mismatch; expected `%v`, got `%v`", want, got)
+	}
+	// Names of local values are left unchanged.
+	if x.Name() != "" || y.Name() != "" {
+		t.Errorf("unexpected names of local values; %q and %q", x.Name(), y.Name())
+	}
+}
+
+func TestMergeIdenticalBlocksAddressTaken(t *testing.T) {
+	// Identical return basic blocks, the second of which is referenced by a
+	// blockaddress constant.
+	cond := NewParam(types.I1, "cond")
+	p := NewParam(types.NewPointer(types.I64), "p")
+	entry := NewBlock("entry")
+	a := NewBlock("a")
+	b := NewBlock("b")
+	f := NewFunction("f", types.I32, cond, p)
+	f.Blocks = []*BasicBlock{entry, a, b}
+	// Nested in a constant expression.
+	entry.NewStore(NewPtrToIntExpr(NewBlockAddress(f, b), types.I64), p)
+	entry.NewCondBr(cond, a, b)
+	a.NewRet(NewInt(types.I32, 1))
+	b.NewRet(NewInt(types.I32, 1))
+	if n := f.MergeIdenticalBlocks(); n != 0 {
+		t.Errorf("number of merged basic blocks mismatch; expected 0, got %d", n)
+	}
+	if len(f.Blocks) != 3 {
+		t.Errorf("address-taken basic block %v merged", b.Ident())
+	}
+}
+
+func TestModuleMergeIdenticalBlocksAddressTaken(t *testing.T) {
+	// Identical return basic blocks, the second of which is referenced by a
+	// blockaddress constant in the initializer of a global variable.
+	m := &Module{}
+	cond := NewParam(types.I1, "cond")
+	entry := NewBlock("entry")
+	a := NewBlock("a")
+	b := NewBlock("b")
+	f := m.NewFunc("f", types.I32, cond)
+	f.Blocks = []*BasicBlock{entry, a, b}
+	entry.NewCondBr(cond, a, b)
+	a.NewRet(NewInt(types.I32, 1))
+	b.NewRet(NewInt(types.I32, 1))
+	m.NewGlobalDef("tbl", NewBlockAddress(f, b))
+	if n := m.MergeIdenticalBlocks(); n != 0 {
+		t.Errorf("number of merged basic blocks mismatch; expected 0, got %d", n)
+	}
+	if len(f.Blocks) != 3 {
+		t.Errorf("address-taken basic block %v merged", b.Ident())
+	}
+}
+
+func TestMergeIdenticalBlocksPhi(t *testing.T) {
+	golden := []struct {
+		xa, xb int64
+		want   int
+	}{
+		// Same incoming values.
+		{xa: 1, xb: 1, want: 1},
+		// Differing incoming values.
+		{xa: 1, xb: 2, want: 0},
+	}
+	for _, g := range golden {
+		cond := NewParam(types.I1, "cond")
+		entry := NewBlock("entry")
+		a := NewBlock("a")
+		b := NewBlock("b")
+		exit := NewBlock("exit")
+		entry.NewCondBr(cond, a, b)
+		a.NewBr(exit)
+		b.NewBr(exit)
+		phi := exit.NewPhi(NewIncoming(NewInt(types.I32, g.xa), a), NewIncoming(NewInt(types.I32, g.xb), b))
+		exit.NewRet(phi)
+		f := NewFunction("f", types.I32, cond)
+		f.Blocks = []*BasicBlock{entry, a, b, exit}
+		if n := f.MergeIdenticalBlocks(); n != g.want {
+			t.Errorf("number of merged basic blocks mismatch; expected %d, got %d", g.want, n)
+		}
+		if want := 2 - g.want; len(phi.Incs) != want {
+			t.Errorf("number of incoming values mismatch; expected %d, got %d", want, len(phi.Incs))
+		}
+	}
+}
+
+func TestFoldTrivialBranches(t *testing.T) {
+	// Straight-line function.
+	x := NewParam(types.I32, "x")
+	entry := NewBlock("entry")
+	body := NewBlock("body")
+	exit := NewBlock("exit")
+	y := entry.NewAdd(x, NewInt(types.I32, 1))
+	y.SetName("y")
+	entry.NewBr(body)
+	phi := body.NewPhi(NewIncoming(y, entry))
+	z := body.NewMul(phi, phi)
+	z.SetName("z")
+	body.NewBr(exit)
+	exit.NewRet(z)
+	f := NewFunction("f", types.I32, x)
+	f.Blocks = []*BasicBlock{entry, body, exit}
+	if n := f.FoldTrivialBranches(); n != 2 {
+		t.Errorf("number of folded basic blocks mismatch; expected 2, got %d", n)
+	}
+	want := `define i32 @f(i32 %x) {
+entry:
+  %y = add i32 %x, 1
+  %z = mul i32 %y, %y
+  ret i32 %z
+}`
+	if got := f.Def(); want != got {
+		t.Errorf("function mismatch; expected `%v`, got `%v`", want, got)
+	}
+}
+
+func TestFoldTrivialBranchesAddressTaken(t *testing.T) {
+	entry := NewBlock("entry")
+	exit := NewBlock("exit")
+	f := NewFunction("f", types.I8Ptr)
+	f.Blocks = []*BasicBlock{entry, exit}
+	entry.NewBr(exit)
+	exit.NewRet(NewBlockAddress(f, exit))
+	if n := f.FoldTrivialBranches(); n != 0 {
+		t.Errorf("number of folded basic blocks mismatch; expected 0, got %d", n)
+	}
+	if len(f.Blocks) != 2 {
+		t.Errorf("address-taken basic block %v folded", exit.Ident())
+	}
+}
+
+func TestModuleFoldTrivialBranchesAddressTaken(t *testing.T) {
+	m := &Module{}
+	entry := NewBlock("entry")
+	exit := NewBlock("exit")
+	f := m.NewFunc("f", types.Void)
+	f.Blocks = []*BasicBlock{entry, exit}
+	entry.NewBr(exit)
+	exit.NewRet(nil)
+	m.NewGlobalDef("tbl", NewBlockAddress(f, exit))
+	if n := m.FoldTrivialBranches(); n != 0 {
+		t.Errorf("number of folded basic blocks mismatch; expected 0, got %d", n)
+	}
+	if len(f.Blocks) != 2 {
+		t.Errorf("address-taken basic block %v folded", exit.Ident())
+	}
+}
+
+func TestLowerTrivialSwitches(t *testing.T) {
+	x := NewParam(types.I32, "x")
+	entry := NewBlock("entry")
+	a := NewBlock("a")
+	b := NewBlock("b")
+	exit := NewBlock("exit")
+	sw := entry.NewSwitch(x, b, NewCase(NewInt(types.I32, 42), a))
+	// Hot default target.
+	if err := sw.SetBranchWeights(1000, 1); err != nil {
+		t.Fatal(err)
+	}
+	sw = a.NewSwitch(x, exit, NewCase(NewInt(types.I32, 1), exit))
+	if err := sw.SetBranchWeights(1, 1); err != nil {
+		t.Fatal(err)
+	}
+	b.NewBr(exit)
+	phi := exit.NewPhi(NewIncoming(NewInt(types.I32, 1), a), NewIncoming(NewInt(types.I32, 1), a), NewIncoming(NewInt(types.I32, 2), b))
+	phi.SetName("phi")
+	exit.NewRet(phi)
+	f := NewFunction("f", types.I32, x)
+	f.Blocks = []*BasicBlock{entry, a, b, exit}
+	if n := f.LowerTrivialSwitches(); n != 2 {
+		t.Errorf("number of lowered switch terminators mismatch; expected 2, got %d", n)
+	}
+	want := `define i32 @f(i32 %x) {
+entry:
+  %0 = icmp eq i32 %x, 42
+  br i1 %0, label %a, label %b, !prof !{!"branch_weights", i32 1, i32 1000}
+
+a:                                                ; preds = %entry
+  br label %exit
+
+b:                                                ; preds = %entry
+  br label %exit
+
+exit:                                             ; preds = %b, %a
+  %phi = phi i32 [ 1, %a ], [ 2, %b ]
+  ret i32 %phi
+}`
+	if err := f.AssignIDs(); err != nil {
+		t.Fatalf("unable to assign IDs; %v", err)
+	}
+	if got := f.Def(); want != got {
+		t.Errorf("function mismatch; expected `%v`, got `%v`", want, got)
+	}
+}
+
+func TestRemoveUnreachableBlocks(t *testing.T) {
+	cond := NewParam(types.I1, "cond")
+	entry := NewBlock("entry")
+	loop := NewBlock("loop")
+	orphan := NewBlock("orphan")
+	exit := NewBlock("exit")
+	entry.NewBr(loop)
+	// Self-loop reachable from entry.
+	loop.NewCondBr(cond, loop, exit)
+	// Orphan basic block without predecessors.
+	orphan.NewBr(exit)
+	phi := exit.NewPhi(NewIncoming(NewInt(types.I32, 1), loop), NewIncoming(NewInt(types.I32, 2), orphan))
+	phi.SetName("phi")
+	exit.NewRet(phi)
+	f := NewFunction("f", types.I32, cond)
+	f.Blocks = []*BasicBlock{entry, loop, orphan, exit}
+	if n := f.RemoveUnreachableBlocks(); n != 1 {
+		t.Errorf("number of removed basic blocks mismatch; expected 1, got %d", n)
+	}
+	want := `define i32 @f(i1 %cond) {
+entry:
+  br label %loop
+
+loop:                                             ; preds = %loop, %entry
+  br i1 %cond, label %loop, label %exit
+
+exit:                                             ; preds = %loop
+  %phi = phi i32 [ 1, %loop ]
+  ret i32 %phi
+}`
+	if err := f.AssignIDs(); err != nil {
+		t.Fatalf("unable to assign IDs; %v", err)
+	}
+	if got := f.Def(); want != got {
+		t.Errorf("function mismatch; expected `%v`, got `%v`", want, got)
+	}
+	// No unreachable basic blocks remain.
+	if n := f.RemoveUnreachableBlocks(); n != 0 {
+		t.Errorf("number of removed basic blocks mismatch; expected 0, got %d", n)
+	}
+}
+
+func TestModuleRemoveUnreachableBlocksAddressTaken(t *testing.T) {
+	// Basic block only reachable through a blockaddress constant in the
+	// initializer of a global variable.
+	m := &Module{}
+	entry := NewBlock("entry")
+	target := NewBlock("target")
+	exit := NewBlock("exit")
+	f := m.NewFunc("f", types.Void)
+	f.Blocks = []*BasicBlock{entry, target, exit}
+	entry.NewRet(nil)
+	target.NewBr(exit)
+	exit.NewRet(nil)
+	m.NewGlobalDef("tbl", NewBlockAddress(f, target))
+	if n := m.RemoveUnreachableBlocks(); n != 0 {
+		t.Errorf("number of removed basic blocks mismatch; expected 0, got %d", n)
+	}
+	if len(f.Blocks) != 3 {
+		t.Errorf("address-taken basic block %v or its successor removed", target.Ident())
+	}
+	// Without the global variable, the basic blocks are unreachable.
+	m.Globals = nil
+	if n := m.RemoveUnreachableBlocks(); n != 2 {
+		t.Errorf("number of removed basic blocks mismatch; expected 2, got %d", n)
+	}
+}
@@ -0,0 +1,177 @@
+package ir
+
+import (
+	"reflect"
+
+	"github.com/llir/l/ir/types"
+	"github.com/llir/l/ir/value"
+)
+
+// Clone returns a deep copy of the function, named by the function name with a
+// ".clone" suffix. The basic blocks, instructions and terminators of the
+// function are copied, and all operands, phi incoming values and predecessors,
+// and terminator successors referring to local values of the function are
+// rewritten to refer to their copies in the cloned function.
+//
+// Result types of instructions (Typ) are copied, as they may not be inferable
+// from the operands of the instruction; e.g. the address space of alloca
+// instructions, or explicit types of opaque pointer loads (see
+// Module.UpgradeToOpaquePointers).
+//
+// The function signature of the clone is a copy of the original function
+// signature; use CloneBody to share the function signature instead.
+func (f *Function) Clone() *Function {
+	g := f.CloneBody()
+	sig := *f.Sig
+	sig.Params = append([]types.Type(nil), f.Sig.Params...)
+	g.Sig = &sig
+	if f.Typ != nil {
+		typ := *f.Typ
+		typ.ElemType = g.Sig
+		g.Typ = &typ
+	}
+	return g
+}
+
+// CloneBody returns a deep copy of the function as by Clone, except that the
+// function signature (Sig) and the cached pointer type to the function (Typ) of
+// the clone are shared with the original function, and are thus not copied.
+//
+// Modifying the function signature of either function affects both; it is
+// intended for callers replacing the body of the clone while keeping its type.
+func (f *Function) CloneBody() *Function {
+	g := *f
+	g.GlobalName = f.GlobalName + ".clone"
+	g.valueTable = nil
+	// Map from original local values to their copies.
+	remap := make(map[value.Value]value.Value)
+	g.Params = make([]*Param, len(f.Params))
+	for i, param := range f.Params {
+		p := *param
+		g.Params[i] = &p
+		remap[param] = &p
+	}
+	g.Blocks = make([]*BasicBlock, len(f.Blocks))
+	for i, block := range f.Blocks {
+		g.Blocks[i] = &BasicBlock{LocalName: block.LocalName}
+		remap[block] = g.Blocks[i]
+	}
+	// Copy instructions and terminators, before rewriting operands, as operands
+	// may refer to values defined later in the function (e.g. phi incoming
+	// values of loops).
+	var copies []interface{}
+	for i, block := range f.Blocks {
+		b := g.Blocks[i]
+		for _, inst := range block.Insts {
+			c := cloneStruct(inst).(Instruction)
+			if v, ok := inst.(value.Value); ok {
+				remap[v] = c.(value.Value)
+			}
+			b.Insts = append(b.Insts, c)
+			copies = append(copies, c)
+		}
+		if block.Term != nil {
+			c := cloneStruct(block.Term).(Terminator)
+			if v, ok := block.Term.(value.Value); ok {
+				remap[v] = c.(value.Value)
+			}
+			b.Term = c
+			copies = append(copies, c)
+		}
+	}
+	for _, c := range copies {
+		remapFields(reflect.ValueOf(c).Elem(), remap)
+	}
+	return &g
+}
+
+// ### [ Helper functions ] ####################################################
+
+// cloneStruct returns a copy of the given pointer to struct (e.g. instruction
+// or terminator), with slices of exported fields copied to new backing arrays.
+// Unexported fields and cached successors are reset.
+func cloneStruct(x interface{}) interface{} {
+	src := reflect.ValueOf(x).Elem()
+	dst := reflect.New(src.Type()).Elem()
+	for i := 0; i < src.NumField(); i++ {
+		field := src.Type().Field(i)
+		if field.PkgPath != "" {
+			// Unexported field.
+			continue
+		}
+		if field.Name == "Successors" {
+			continue
+		}
+		v := src.Field(i)
+		if v.Kind() == reflect.Slice && !v.IsNil() {
+			v = reflect.AppendSlice(reflect.MakeSlice(v.Type(), 0, v.Len()), v)
+		}
+		dst.Field(i).Set(v)
+	}
+	return dst.Addr().Interface()
+}
+
+// remapFields rewrites the exported fields of the given struct which refer to
+// values of the remap table. Slice elements are rewritten in place, and
+// elements referring to structs which are not values (e.g. phi incoming values
+// and switch cases) are copied before being rewritten.
+func remapFields(v reflect.Value, remap map[value.Value]value.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		if v.Type().Field(i).PkgPath != "" {
+			// Unexported field.
+			continue
+		}
+		field := v.Field(i)
+		switch field.Kind() {
+		case reflect.Interface, reflect.Ptr:
+			remapValue(field, remap)
+		case reflect.Slice:
+			for j := 0; j < field.Len(); j++ {
+				elem := field.Index(j)
+				if elem.Kind() != reflect.Interface && elem.Kind() != reflect.Ptr {
+					continue
+				}
+				if remapValue(elem, remap) {
+					continue
+				}
+				if elem.Kind() == reflect.Ptr && !elem.IsNil() && elem.Elem().Kind() == reflect.Struct {
+					if _, ok := elem.Interface().(value.Value); ok {
+						// Values not part of the function (e.g. globals).
+						continue
+					}
+					c := reflect.New(elem.Elem().Type())
+					c.Elem().Set(elem.Elem())
+					remapFields(c.Elem(), remap)
+					elem.Set(c)
+				}
+			}
+		}
+	}
+}
+
+// remapValue rewrites the given settable value if it refers to a value of the
+// remap table, either directly or wrapped as metadata (e.g. "metadata i32 %x");
+// wrapped values are rewritten to new metadata as values. The boolean return
+// value indicates whether the value was rewritten.
+func remapValue(v reflect.Value, remap map[value.Value]value.Value) bool {
+	if v.IsNil() {
+		return false
+	}
+	x, ok := v.Interface().(value.Value)
+	if !ok {
+		return false
+	}
+	if w, ok := wrappedValue(x); ok {
+		if new, ok := remap[w]; ok {
+			v.Set(reflect.ValueOf(NewMetadataValue(NewMDValue(new))))
+			return true
+		}
+		return false
+	}
+	new, ok := remap[x]
+	if !ok {
+		return false
+	}
+	v.Set(reflect.ValueOf(new))
+	return true
+}
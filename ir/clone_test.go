@@ -0,0 +1,145 @@
+package ir
+
+import (
+	"testing"
+
+	"github.com/llir/l/ir/enum"
+	"github.com/llir/l/ir/types"
+	"github.com/llir/l/ir/value"
+)
+
+func TestFunctionClone(t *testing.T) {
+	// Sum of integers below n.
+	n := NewParam(types.I32, "n")
+	entry := NewBlock("entry")
+	loop := NewBlock("loop")
+	exit := NewBlock("exit")
+	zero := NewInt(types.I32, 0)
+	entry.NewBr(loop)
+	i := loop.NewPhi(NewIncoming(zero, entry))
+	sum := loop.NewPhi(NewIncoming(zero, entry))
+	i.SetName("i")
+	sum.SetName("sum")
+	next := loop.NewAdd(i, NewInt(types.I32, 1))
+	next.SetName("next")
+	acc := loop.NewAdd(sum, i)
+	acc.SetName("acc")
+	i.Incs = append(i.Incs, NewIncoming(next, loop))
+	sum.Incs = append(sum.Incs, NewIncoming(acc, loop))
+	cond := loop.NewICmp(enum.IPredSLT, next, n)
+	cond.SetName("cond")
+	loop.NewCondBr(cond, loop, exit)
+	exit.NewRet(acc)
+	f := NewFunction("sum", types.I32, n)
+	f.Blocks = []*BasicBlock{entry, loop, exit}
+	want := f.Def()
+
+	g := f.Clone()
+	if g.GlobalName != "sum.clone" {
+		t.Errorf("function name mismatch; expected `sum.clone`, got `%v`", g.GlobalName)
+	}
+	// Identical output, except for the function name.
+	g.GlobalName = f.GlobalName
+	if got := g.Def(); want != got {
+		t.Errorf("function mismatch; expected `%v`, got `%v`", want, got)
+	}
+	// No operand, phi predecessor or successor refers to the original function.
+	orig := make(map[value.Value]bool)
+	for _, param := range f.Params {
+		orig[param] = true
+	}
+	for _, block := range f.Blocks {
+		orig[block] = true
+		for _, inst := range block.Insts {
+			orig[inst.(value.Value)] = true
+		}
+	}
+	check := func(v value.Value) {
+		if orig[v] {
+			t.Errorf("reference to original value %v in cloned function", v.Ident())
+		}
+	}
+	for _, block := range g.Blocks {
+		check(block)
+		for _, inst := range block.Insts {
+			check(inst.(value.Value))
+			for _, op := range inst.Operands() {
+				check(*op)
+			}
+			if phi, ok := inst.(*InstPhi); ok {
+				for _, inc := range phi.Incs {
+					check(inc.Pred)
+				}
+			}
+		}
+		for _, op := range block.Term.Operands() {
+			check(*op)
+		}
+		for _, succ := range block.Term.Succs() {
+			check(succ)
+		}
+	}
+	// The original function is unchanged.
+	g.Blocks[1].Insts[0].(*InstPhi).Incs[0].X = NewInt(types.I32, 1)
+	if got := f.Def(); want != got {
+		t.Errorf("original function changed; expected `%v`, got `%v`", want, got)
+	}
+}
+
+func TestFunctionCloneBody(t *testing.T) {
+	x := NewParam(types.I32, "x")
+	entry := NewBlock("entry")
+	y := entry.NewAdd(x, NewInt(types.I32, 1))
+	entry.NewRet(y)
+	f := NewFunction("inc", types.I32, x)
+	f.Blocks = []*BasicBlock{entry}
+	f.Type()
+
+	g := f.CloneBody()
+	if g.Sig != f.Sig {
+		t.Errorf("function signature mismatch; expected shared signature %p, got %p", f.Sig, g.Sig)
+	}
+	if g.Typ != f.Typ {
+		t.Errorf("function type mismatch; expected shared type %p, got %p", f.Typ, g.Typ)
+	}
+	// Values are still remapped to the clone.
+	add := g.Blocks[0].Insts[0].(*InstAdd)
+	if add.X != g.Params[0] {
+		t.Errorf("operand mismatch; expected cloned parameter %p, got %p", g.Params[0], add.X)
+	}
+	if ret := g.Blocks[0].Term.(*TermRet); ret.X != add {
+		t.Errorf("return value mismatch; expected cloned instruction %p, got %p", add, ret.X)
+	}
+	// Clone copies the function signature.
+	h := f.Clone()
+	if h.Sig == f.Sig {
+		t.Errorf("function signature of Clone shared with original function")
+	}
+	if h.Typ.ElemType != h.Sig {
+		t.Errorf("function type mismatch; expected pointer to %p, got pointer to %v", h.Sig, h.Typ.ElemType)
+	}
+}
+
+func TestFunctionCloneOpaque(t *testing.T) {
+	m := &Module{}
+	p := NewParam(types.I32Ptr, "p")
+	f := m.NewFunc("f", types.I32, p)
+	entry := NewBlock("entry")
+	x := entry.NewLoad(p)
+	entry.NewRet(x)
+	f.Blocks = []*BasicBlock{entry}
+	if err := f.AssignIDs(); err != nil {
+		t.Fatal(err)
+	}
+	m.UpgradeToOpaquePointers()
+	// Explicit result types of the upgraded function are retained by the clone.
+	g := f.Clone()
+	want := `define i32 @f.clone(ptr %p) {
+entry:
+  %0 = load i32, ptr %p
+  ret i32 %0
+}`
+	if got := g.Def(); want != got {
+		t.Errorf("function mismatch; expected `%v`, got `%v`", want, got)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/llir/l/ir/types"
+	"github.com/pkg/errors"
 )
 
 // --- [ blockaddress constants ] ----------------------------------------------
@@ -14,6 +15,12 @@ type ConstBlockAddress struct {
 	Func *Function
 	// Basic block to take address of.
 	Block *BasicBlock
+
+	// extra.
+
+	// Type of the constant. If Typ is nil, the first invocation of Type stores
+	// i8*.
+	Typ *types.PointerType
 }
 
 // NewBlockAddress returns a new blockaddress constant based on the given parent
@@ -30,7 +37,11 @@ func (c *ConstBlockAddress) String() string {
 
 // Type returns the type of the constant.
 func (c *ConstBlockAddress) Type() types.Type {
-	return types.I8Ptr
+	// Cache type if not present.
+	if c.Typ == nil {
+		c.Typ = types.I8Ptr
+	}
+	return c.Typ
 }
 
 // Ident returns the identifier associated with the constant.
@@ -38,3 +49,24 @@ func (c *ConstBlockAddress) Ident() string {
 	// "blockaddress" "(" GlobalIdent "," LocalIdent ")"
 	return fmt.Sprintf("blockaddress(%v, %v)", c.Func.Ident(), c.Block.Ident())
 }
+
+// Validate reports an error if the blockaddress constant is invalid; i.e. if
+// the basic block is not a basic block of the parent function, or if the basic
+// block is the entry basic block of the parent function, the address of which
+// may not be taken.
+//
+// Basic blocks are referred to by pointer, and are thus resolved to their
+// current identifiers when printed; e.g. after local IDs have been assigned to
+// unnamed basic blocks by AssignIDs.
+func (c *ConstBlockAddress) Validate() error {
+	for i, block := range c.Func.Blocks {
+		if block != c.Block {
+			continue
+		}
+		if i == 0 {
+			return errors.Errorf("invalid blockaddress of entry basic block %v of function %v", c.Block.Ident(), c.Func.Ident())
+		}
+		return nil
+	}
+	return errors.Errorf("invalid blockaddress of basic block %v; expected basic block of function %v", c.Block.Ident(), c.Func.Ident())
+}
@@ -0,0 +1,37 @@
+package ir
+
+import (
+	"fmt"
+
+	"github.com/llir/l/ir/types"
+)
+
+// --- [ Poison values ] -------------------------------------------------------
+
+// ConstPoison is an LLVM IR poison value.
+type ConstPoison struct {
+	// Poison value type.
+	Typ types.Type
+}
+
+// NewPoison returns a new poison value based on the given type.
+func NewPoison(typ types.Type) *ConstPoison {
+	return &ConstPoison{Typ: typ}
+}
+
+// String returns the LLVM syntax representation of the constant as a type-value
+// pair.
+func (c *ConstPoison) String() string {
+	return fmt.Sprintf("%v %v", c.Type(), c.Ident())
+}
+
+// Type returns the type of the constant.
+func (c *ConstPoison) Type() types.Type {
+	return c.Typ
+}
+
+// Ident returns the identifier associated with the constant.
+func (*ConstPoison) Ident() string {
+	// "poison"
+	return "poison"
+}
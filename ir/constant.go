@@ -55,6 +55,12 @@ var (
 //
 //    *ir.ConstUndef   // https://godoc.org/github.com/llir/l/ir#ConstUndef
 //
+// Poison values
+//
+// https://llvm.org/docs/LangRef.html#poison-values
+//
+//    *ir.ConstPoison   // https://godoc.org/github.com/llir/l/ir#ConstPoison
+//
 // Addresses of basic blocks
 //
 // https://llvm.org/docs/LangRef.html#addresses-of-basic-blocks
@@ -87,6 +93,7 @@ func (*ConstZeroInitializer) isConstant() {}
 func (*Global) isConstant()               {}
 func (*Function) isConstant()             {}
 func (*ConstUndef) isConstant()           {}
+func (*ConstPoison) isConstant()          {}
 func (*ConstBlockAddress) isConstant()    {}
 
 // Binary expressions.
@@ -19,5 +19,6 @@ var (
 	_ Constant = (*Global)(nil)
 	_ Constant = (*Function)(nil)
 	_ Constant = (*ConstUndef)(nil)
+	_ Constant = (*ConstPoison)(nil)
 	_ Constant = (*ConstBlockAddress)(nil)
 )
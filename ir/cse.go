@@ -0,0 +1,85 @@
+package ir
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/llir/l/ir/value"
+)
+
+// CommonSubexpressionElimination eliminates pure instructions which are
+// structurally identical to an earlier instruction of the same basic block,
+// replacing all uses of the eliminated instruction with the earlier one. The
+// number of eliminated instructions is returned.
+//
+// Only instructions without side effects (getelementptr, binary, bitwise,
+// conversion, icmp and fcmp instructions) are considered. Instructions with
+// differing flags or metadata are never merged.
+func (f *Function) CommonSubexpressionElimination() int {
+	n := 0
+	for _, block := range f.Blocks {
+		seen := make(map[string]value.Value)
+		var insts []Instruction
+		for _, inst := range block.Insts {
+			if !isPureInst(inst) {
+				insts = append(insts, inst)
+				continue
+			}
+			v := inst.(value.Value)
+			key := instKey(inst)
+			if prev, ok := seen[key]; ok {
+				f.ReplaceAll(v, prev)
+				n++
+				continue
+			}
+			seen[key] = v
+			insts = append(insts, inst)
+		}
+		block.Insts = insts
+	}
+	return n
+}
+
+// ### [ Helper functions ] ####################################################
+
+// isPureInst reports whether the given instruction is free of side effects and
+// may thus be eliminated if structurally identical to an earlier instruction.
+func isPureInst(inst Instruction) bool {
+	switch inst.(type) {
+	// Binary instructions.
+	case *InstAdd, *InstFAdd, *InstSub, *InstFSub, *InstMul, *InstFMul, *InstUDiv, *InstSDiv, *InstFDiv, *InstURem, *InstSRem, *InstFRem:
+		return true
+	// Bitwise instructions.
+	case *InstShl, *InstLShr, *InstAShr, *InstAnd, *InstOr, *InstXor:
+		return true
+	// Memory instructions.
+	case *InstGetElementPtr:
+		return true
+	// Conversion instructions.
+	case *InstTrunc, *InstZExt, *InstSExt, *InstFPTrunc, *InstFPExt, *InstFPToUI, *InstFPToSI, *InstUIToFP, *InstSIToFP, *InstPtrToInt, *InstIntToPtr, *InstBitCast, *InstAddrSpaceCast:
+		return true
+	// Other instructions.
+	case *InstICmp, *InstFCmp:
+		return true
+	}
+	return false
+}
+
+// instKey returns a key uniquely identifying the structure of the given
+// instruction. Structurally identical instructions have the same key.
+//
+// The LLVM syntax representation of the instruction captures its kind, type,
+// flags and metadata, while the identity of each operand is added to
+// distinguish between operands with the same identifier (e.g. unnamed values).
+func instKey(inst Instruction) string {
+	buf := &strings.Builder{}
+	fmt.Fprintf(buf, "%T %v %v", inst, inst.(value.Value).Type(), inst.Def())
+	for _, op := range inst.Operands() {
+		if c, ok := (*op).(Constant); ok {
+			fmt.Fprintf(buf, "; %v", c)
+		} else {
+			fmt.Fprintf(buf, "; %p", *op)
+		}
+	}
+	return buf.String()
+}
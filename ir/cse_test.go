@@ -0,0 +1,58 @@
+package ir
+
+import (
+	"testing"
+
+	"github.com/llir/l/ir/enum"
+	"github.com/llir/l/ir/types"
+)
+
+func TestCommonSubexpressionElimination(t *testing.T) {
+	arr := types.NewArray(4, types.I32)
+	p := NewParam(types.NewPointer(arr), "p")
+	x := NewParam(types.I32, "x")
+	entry := NewBlock("entry")
+	gep1 := entry.NewGetElementPtr(arr, p, NewInt(types.I64, 0), NewInt(types.I64, 1))
+	entry.NewStore(x, gep1)
+	gep2 := entry.NewGetElementPtr(arr, p, NewInt(types.I64, 0), NewInt(types.I64, 1))
+	store2 := entry.NewStore(x, gep2)
+	entry.NewStore(x, gep2)
+	entry.NewRet(nil)
+	f := &Function{
+		GlobalName: "f",
+		Sig:        types.NewFunc(types.Void, p.Type(), x.Type()),
+		Params:     []*Param{p, x},
+		Blocks:     []*BasicBlock{entry},
+	}
+	if n := f.CommonSubexpressionElimination(); n != 1 {
+		t.Errorf("number of eliminated instructions mismatch; expected 1, got %d", n)
+	}
+	// One getelementptr and three stores remain.
+	if len(entry.Insts) != 4 {
+		t.Fatalf("number of instructions mismatch; expected 4, got %d", len(entry.Insts))
+	}
+	if entry.Insts[2] != store2 {
+		t.Errorf("store instruction eliminated")
+	}
+	if store2.Dst != gep1 {
+		t.Errorf("use of eliminated getelementptr not replaced")
+	}
+}
+
+func TestCommonSubexpressionEliminationFlags(t *testing.T) {
+	x := NewParam(types.I32, "x")
+	entry := NewBlock("entry")
+	entry.NewAdd(x, x)
+	add := entry.NewAdd(x, x)
+	add.OverflowFlags = []enum.OverflowFlag{enum.OverflowFlagNSW}
+	entry.NewRet(add)
+	f := &Function{
+		GlobalName: "f",
+		Sig:        types.NewFunc(types.I32, x.Type()),
+		Params:     []*Param{x},
+		Blocks:     []*BasicBlock{entry},
+	}
+	if n := f.CommonSubexpressionElimination(); n != 0 {
+		t.Errorf("number of eliminated instructions mismatch; expected 0, got %d", n)
+	}
+}
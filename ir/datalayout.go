@@ -0,0 +1,331 @@
+package ir
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/llir/l/ir/types"
+	"github.com/pkg/errors"
+)
+
+// === [ Data layout ] =========================================================
+
+// DataLayout specifies how data is to be laid out in memory, as described by a
+// data layout string (e.g. "e-m:e-i64:64-f80:128-n8:16:32:64-S128").
+//
+// All alignments are in bytes.
+type DataLayout struct {
+	// Big-endian byte order.
+	BigEndian bool
+	// ABI alignment of integer types, indexed by bit size.
+	IntAligns map[int64]int
+	// ABI alignment of floating-point types, indexed by bit size.
+	FloatAligns map[int64]int
+	// ABI alignment of vector types, indexed by bit size.
+	VectorAligns map[int64]int
+	// ABI alignment of aggregate types.
+	AggregateAlign int
+	// Size in bits of pointers, indexed by address space.
+	PointerSizes map[types.AddrSpace]int64
+	// ABI alignment of pointers, indexed by address space.
+	PointerAligns map[types.AddrSpace]int
+}
+
+// NewDataLayout returns a new data layout based on the given data layout
+// string. Specifications not present in the data layout string are given the
+// default values of LLVM.
+func NewDataLayout(layout string) (*DataLayout, error) {
+	dl := &DataLayout{
+		IntAligns:      map[int64]int{1: 1, 8: 1, 16: 2, 32: 4, 64: 4},
+		FloatAligns:    map[int64]int{16: 2, 32: 4, 64: 8, 128: 16},
+		VectorAligns:   map[int64]int{64: 8, 128: 16},
+		AggregateAlign: 1,
+		PointerSizes:   map[types.AddrSpace]int64{0: 64},
+		PointerAligns:  map[types.AddrSpace]int{0: 8},
+	}
+	if len(layout) == 0 {
+		return dl, nil
+	}
+	for _, spec := range strings.Split(layout, "-") {
+		if err := dl.parseSpec(spec); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	return dl, nil
+}
+
+// parseSpec parses the given data layout specification.
+func (dl *DataLayout) parseSpec(spec string) error {
+	if len(spec) == 0 {
+		return errors.Errorf("invalid empty data layout specification")
+	}
+	fields := strings.Split(spec[1:], ":")
+	switch spec[0] {
+	case 'e':
+		dl.BigEndian = false
+	case 'E':
+		dl.BigEndian = true
+	case 'p':
+		// p[n]:size:abi[:pref[:idx]]
+		if len(fields) < 3 {
+			return errors.Errorf("invalid pointer specification %q; expected size and ABI alignment", spec)
+		}
+		addrSpace, err := parseLayoutInt(spec, fields[0], 0)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		size, err := parseLayoutInt(spec, fields[1], 0)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		abi, err := parseLayoutInt(spec, fields[2], 0)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		dl.PointerSizes[types.AddrSpace(addrSpace)] = size
+		dl.PointerAligns[types.AddrSpace(addrSpace)] = int(abi / 8)
+	case 'i', 'f', 'v':
+		// i<size>:abi[:pref]
+		// f<size>:abi[:pref]
+		// v<size>:abi[:pref]
+		if len(fields) < 2 {
+			return errors.Errorf("invalid alignment specification %q; expected size and ABI alignment", spec)
+		}
+		size, err := parseLayoutInt(spec, fields[0], -1)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		abi, err := parseLayoutInt(spec, fields[1], -1)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		switch spec[0] {
+		case 'i':
+			dl.IntAligns[size] = int(abi / 8)
+		case 'f':
+			dl.FloatAligns[size] = int(abi / 8)
+		case 'v':
+			dl.VectorAligns[size] = int(abi / 8)
+		}
+	case 'a':
+		// a:abi[:pref]
+		if len(fields) < 2 {
+			return errors.Errorf("invalid aggregate specification %q; expected ABI alignment", spec)
+		}
+		abi, err := parseLayoutInt(spec, fields[1], 0)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		dl.AggregateAlign = int(abi / 8)
+		if dl.AggregateAlign == 0 {
+			dl.AggregateAlign = 1
+		}
+	default:
+		// Ignore specifications which do not affect type alignment (e.g.
+		// mangling, native integer widths and stack alignment).
+	}
+	return nil
+}
+
+// ABIAlignment returns the ABI alignment in bytes of the given type.
+func (dl *DataLayout) ABIAlignment(t types.Type) int {
+	switch t := t.(type) {
+	case *types.IntType:
+		return dl.intAlignment(t.BitSize)
+	case *types.FloatType:
+		if align, ok := dl.FloatAligns[t.BitSize()]; ok {
+			return align
+		}
+		// Use natural alignment if no alignment has been specified.
+		return naturalAlignment(t.BitSize())
+	case *types.MMXType:
+		return dl.vectorAlignment(64)
+	case *types.PointerType:
+		return dl.pointerAlignment(t.AddrSpace)
+	case *types.VectorType:
+		return dl.vectorAlignment(t.Len * dl.elemBitSize(t.ElemType))
+	case *types.ArrayType:
+		return dl.ABIAlignment(t.ElemType)
+	case *types.StructType:
+		if t.Packed {
+			return 1
+		}
+		align := dl.AggregateAlign
+		for _, field := range t.Fields {
+			if a := dl.ABIAlignment(field); a > align {
+				align = a
+			}
+		}
+		return align
+	default:
+		panic(fmt.Errorf("support for ABI alignment of type %T not yet implemented", t))
+	}
+}
+
+// TypeAllocSize returns the size in bytes allocated for values of the given
+// type, including padding required by the alignment of the type; i.e. the
+// offset in bytes between successive elements of an array of the type.
+func (dl *DataLayout) TypeAllocSize(t types.Type) int64 {
+	switch t := t.(type) {
+	case *types.ArrayType:
+		return t.Len * dl.TypeAllocSize(t.ElemType)
+	case *types.StructType:
+		return dl.structSize(t)
+	default:
+		return alignTo(dl.typeStoreSize(t), dl.ABIAlignment(t))
+	}
+}
+
+// StructFieldOffset returns the offset in bytes of the field at the given index
+// of the struct type.
+func (dl *DataLayout) StructFieldOffset(t *types.StructType, index int) int64 {
+	if index < 0 || index >= len(t.Fields) {
+		panic(fmt.Errorf("invalid field index of struct type %v; expected 0 <= index < %d, got %d", t, len(t.Fields), index))
+	}
+	var offset int64
+	for i, field := range t.Fields {
+		if !t.Packed {
+			offset = alignTo(offset, dl.ABIAlignment(field))
+		}
+		if i == index {
+			break
+		}
+		offset += dl.TypeAllocSize(field)
+	}
+	return offset
+}
+
+// structSize returns the size in bytes of the given struct type, including
+// padding between fields and trailing padding.
+func (dl *DataLayout) structSize(t *types.StructType) int64 {
+	var size int64
+	for _, field := range t.Fields {
+		if !t.Packed {
+			size = alignTo(size, dl.ABIAlignment(field))
+		}
+		size += dl.TypeAllocSize(field)
+	}
+	return alignTo(size, dl.ABIAlignment(t))
+}
+
+// typeStoreSize returns the number of bytes written when storing a value of the
+// given scalar or vector type.
+func (dl *DataLayout) typeStoreSize(t types.Type) int64 {
+	switch t := t.(type) {
+	case *types.IntType:
+		return (t.BitSize + 7) / 8
+	case *types.FloatType:
+		return (t.BitSize() + 7) / 8
+	case *types.MMXType:
+		return 8
+	case *types.PointerType:
+		return (dl.elemBitSize(t) + 7) / 8
+	case *types.VectorType:
+		return (t.Len*dl.elemBitSize(t.ElemType) + 7) / 8
+	default:
+		panic(fmt.Errorf("support for size of type %T not yet implemented", t))
+	}
+}
+
+// intAlignment returns the ABI alignment of an integer type of the given bit
+// size. If no alignment has been specified for the bit size, the alignment of
+// the smallest larger integer type is used, or the alignment of the largest
+// integer type if no larger integer type is specified.
+func (dl *DataLayout) intAlignment(bitSize int64) int {
+	if align, ok := dl.IntAligns[bitSize]; ok {
+		return align
+	}
+	var larger, largest int64 = -1, -1
+	for size := range dl.IntAligns {
+		if size > bitSize && (larger == -1 || size < larger) {
+			larger = size
+		}
+		if size > largest {
+			largest = size
+		}
+	}
+	if larger != -1 {
+		return dl.IntAligns[larger]
+	}
+	return dl.IntAligns[largest]
+}
+
+// vectorAlignment returns the ABI alignment of a vector type of the given bit
+// size.
+func (dl *DataLayout) vectorAlignment(bitSize int64) int {
+	if align, ok := dl.VectorAligns[bitSize]; ok {
+		return align
+	}
+	// Use natural alignment if no alignment has been specified.
+	return naturalAlignment(bitSize)
+}
+
+// pointerAlignment returns the ABI alignment of a pointer type in the given
+// address space.
+func (dl *DataLayout) pointerAlignment(addrSpace types.AddrSpace) int {
+	if align, ok := dl.PointerAligns[addrSpace]; ok {
+		return align
+	}
+	// Use the alignment of the default address space if no alignment has been
+	// specified.
+	return dl.PointerAligns[0]
+}
+
+// pointerSize returns the size in bits of a pointer type in the given address
+// space.
+func (dl *DataLayout) pointerSize(addrSpace types.AddrSpace) int64 {
+	if size, ok := dl.PointerSizes[addrSpace]; ok {
+		return size
+	}
+	// Use the size of the default address space if no size has been specified.
+	return dl.PointerSizes[0]
+}
+
+// elemBitSize returns the size in bits of the given vector element type.
+func (dl *DataLayout) elemBitSize(t types.Type) int64 {
+	switch t := t.(type) {
+	case *types.IntType:
+		return t.BitSize
+	case *types.FloatType:
+		return t.BitSize()
+	case *types.PointerType:
+		return dl.pointerSize(t.AddrSpace)
+	default:
+		panic(fmt.Errorf("invalid vector element type; expected *types.IntType, *types.FloatType or *types.PointerType, got %T", t))
+	}
+}
+
+// ### [ Helper functions ] ####################################################
+
+// parseLayoutInt parses the given integer field of the data layout
+// specification. An empty field is given the default value def, unless def is
+// negative.
+func parseLayoutInt(spec, field string, def int64) (int64, error) {
+	if len(field) == 0 && def >= 0 {
+		return def, nil
+	}
+	x, err := strconv.ParseInt(field, 10, 64)
+	if err != nil {
+		return 0, errors.Errorf("invalid integer %q in data layout specification %q", field, spec)
+	}
+	return x, nil
+}
+
+// naturalAlignment returns the natural alignment in bytes of a type of the
+// given bit size; i.e. the size in bytes rounded up to the nearest power of
+// two.
+func naturalAlignment(bitSize int64) int {
+	size := (bitSize + 7) / 8
+	align := 1
+	for int64(align) < size {
+		align *= 2
+	}
+	return align
+}
+
+// alignTo returns x rounded up to the nearest multiple of align.
+func alignTo(x int64, align int) int64 {
+	a := int64(align)
+	return (x + a - 1) / a * a
+}
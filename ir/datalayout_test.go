@@ -0,0 +1,98 @@
+package ir
+
+import (
+	"testing"
+
+	"github.com/llir/l/ir/types"
+)
+
+func TestDataLayoutABIAlignment(t *testing.T) {
+	// Data layout of x86-64 Linux.
+	amd64, err := NewDataLayout("e-m:e-p270:32:32-p271:32:32-p272:64:64-i64:64-f80:128-n8:16:32:64-S128")
+	if err != nil {
+		t.Fatalf("unable to parse data layout; %v", err)
+	}
+	// Default data layout.
+	def, err := NewDataLayout("")
+	if err != nil {
+		t.Fatalf("unable to parse data layout; %v", err)
+	}
+	golden := []struct {
+		dl   *DataLayout
+		in   types.Type
+		want int
+	}{
+		{dl: amd64, in: types.I64, want: 8},
+		{dl: def, in: types.I64, want: 4},
+		{dl: amd64, in: types.I1, want: 1},
+		{dl: amd64, in: types.NewInt(24), want: 4},
+		{dl: amd64, in: types.NewInt(128), want: 8},
+		{dl: amd64, in: types.X86FP80, want: 16},
+		{dl: amd64, in: types.I8Ptr, want: 8},
+		{dl: amd64, in: types.NewVector(4, types.Float), want: 16},
+		{dl: amd64, in: types.NewArray(3, types.I16), want: 2},
+		{dl: amd64, in: types.NewStruct(types.I8, types.I64), want: 8},
+		{dl: def, in: types.NewStruct(types.I8, types.I64), want: 4},
+		{dl: amd64, in: &types.StructType{Packed: true, Fields: []types.Type{types.I8, types.I64}}, want: 1},
+	}
+	for _, g := range golden {
+		got := g.dl.ABIAlignment(g.in)
+		if g.want != got {
+			t.Errorf("ABI alignment mismatch of %v; expected %d, got %d", g.in, g.want, got)
+		}
+	}
+}
+
+func TestNaturalAlignment(t *testing.T) {
+	dl, err := NewDataLayout("e-m:e-i64:64-f80:128-n8:16:32:64-S128")
+	if err != nil {
+		t.Fatalf("unable to parse data layout; %v", err)
+	}
+	pair := types.NewStruct(types.I32, types.Double)
+	entry := NewBlock("entry")
+	alloca := entry.NewAlloca(pair)
+	load := entry.NewLoad(NewParam(types.I64Ptr, "p"))
+	store := entry.NewStore(NewInt(types.I16, 1), NewParam(types.I16Ptr, "q"))
+	if got := alloca.NaturalAlignment(dl); got != 8 {
+		t.Errorf("alloca alignment mismatch; expected 8, got %d", got)
+	}
+	if got := load.NaturalAlignment(dl); got != 8 {
+		t.Errorf("load alignment mismatch; expected 8, got %d", got)
+	}
+	if got := store.NaturalAlignment(dl); got != 2 {
+		t.Errorf("store alignment mismatch; expected 2, got %d", got)
+	}
+}
+
+func TestNewDataLayoutInvalid(t *testing.T) {
+	if _, err := NewDataLayout("e-i64:x"); err == nil {
+		t.Errorf("expected error for invalid data layout, got nil")
+	}
+}
+
+func TestDataLayoutTypeAllocSize(t *testing.T) {
+	dl, err := NewDataLayout("e-m:e-i64:64-f80:128-n8:16:32:64-S128")
+	if err != nil {
+		t.Fatalf("unable to parse data layout; %v", err)
+	}
+	golden := []struct {
+		in   types.Type
+		want int64
+	}{
+		{in: types.I1, want: 1},
+		{in: types.NewInt(24), want: 4},
+		{in: types.I64, want: 8},
+		{in: types.X86FP80, want: 16},
+		{in: types.I8Ptr, want: 8},
+		{in: types.NewVector(3, types.Float), want: 16},
+		{in: types.NewArray(3, types.I16), want: 6},
+		{in: types.NewStruct(types.I8, types.I64, types.I16), want: 24},
+		{in: &types.StructType{Packed: true, Fields: []types.Type{types.I8, types.I64, types.I16}}, want: 11},
+	}
+	for _, g := range golden {
+		got := dl.TypeAllocSize(g.in)
+		if g.want != got {
+			t.Errorf("allocation size mismatch of %v; expected %d, got %d", g.in, g.want, got)
+		}
+	}
+}
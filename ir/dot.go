@@ -0,0 +1,72 @@
+package ir
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// WriteDOT writes the control flow graph of the function to w in Graphviz DOT
+// format, as a debugging aid. The graph contains one node per basic block,
+// labeled by the instructions and terminator of the basic block, and one edge
+// per successor of each basic block; edges of conditional br terminators are
+// labeled true and false.
+//
+// Local IDs are assigned to unnamed local values of the function (see
+// AssignIDs) before the graph is written.
+func (f *Function) WriteDOT(w io.Writer) error {
+	if err := f.AssignIDs(); err != nil {
+		return errors.WithStack(err)
+	}
+	buf := &strings.Builder{}
+	fmt.Fprintf(buf, "digraph %s {\n", dotQuote(f.Ident()))
+	buf.WriteString("\tnode [shape=box, fontname=\"monospace\"];\n")
+	for _, block := range f.Blocks {
+		fmt.Fprintf(buf, "\t%s [label=%s];\n", dotQuote(block.Ident()), dotLabel(block.Def()))
+	}
+	for _, block := range f.Blocks {
+		if block.Term == nil {
+			continue
+		}
+		if term, ok := block.Term.(*TermCondBr); ok {
+			fmt.Fprintf(buf, "\t%s -> %s [label=\"true\"];\n", dotQuote(block.Ident()), dotQuote(term.TargetTrue.Ident()))
+			fmt.Fprintf(buf, "\t%s -> %s [label=\"false\"];\n", dotQuote(block.Ident()), dotQuote(term.TargetFalse.Ident()))
+			continue
+		}
+		for _, succ := range block.Term.Succs() {
+			fmt.Fprintf(buf, "\t%s -> %s;\n", dotQuote(block.Ident()), dotQuote(succ.Ident()))
+		}
+	}
+	buf.WriteString("}\n")
+	if _, err := io.WriteString(w, buf.String()); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// ### [ Helper functions ] ####################################################
+
+// dotQuote returns s as a double-quoted DOT string.
+func dotQuote(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `"`, `\"`, -1)
+	return `"` + s + `"`
+}
+
+// dotLabel returns the given lines of text as a double-quoted DOT label, with
+// each line left-justified.
+func dotLabel(s string) string {
+	buf := &strings.Builder{}
+	buf.WriteString(`"`)
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.Replace(line, "\t", "  ", -1)
+		line = strings.Replace(line, `\`, `\\`, -1)
+		line = strings.Replace(line, `"`, `\"`, -1)
+		buf.WriteString(line)
+		buf.WriteString(`\l`)
+	}
+	buf.WriteString(`"`)
+	return buf.String()
+}
@@ -0,0 +1,46 @@
+package ir
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/llir/l/ir/enum"
+	"github.com/llir/l/ir/types"
+)
+
+func TestFunctionWriteDOT(t *testing.T) {
+	// Diamond control flow graph.
+	x := NewParam(types.I32, "x")
+	entry := NewBlock("entry")
+	a := NewBlock("a")
+	b := NewBlock("b")
+	exit := NewBlock("exit")
+	cond := entry.NewICmp(enum.IPredSLT, x, NewInt(types.I32, 0))
+	cond.SetName("cond")
+	entry.NewCondBr(cond, a, b)
+	a.NewBr(exit)
+	b.NewBr(exit)
+	phi := exit.NewPhi(NewIncoming(NewInt(types.I32, 1), a), NewIncoming(NewInt(types.I32, 2), b))
+	exit.NewRet(phi)
+	f := NewFunction("f", types.I32, x)
+	f.Blocks = []*BasicBlock{entry, a, b, exit}
+	buf := &strings.Builder{}
+	if err := f.WriteDOT(buf); err != nil {
+		t.Fatalf("unable to write DOT of function %v; %v", f.Ident(), err)
+	}
+	want := `digraph "@f" {
+	node [shape=box, fontname="monospace"];
+	"%entry" [label="entry:\l  %cond = icmp slt i32 %x, 0\l  br i1 %cond, label %a, label %b\l"];
+	"%a" [label="a:\l  br label %exit\l"];
+	"%b" [label="b:\l  br label %exit\l"];
+	"%exit" [label="exit:\l  %0 = phi i32 [ 1, %a ], [ 2, %b ]\l  ret i32 %0\l"];
+	"%entry" -> "%a" [label="true"];
+	"%entry" -> "%b" [label="false"];
+	"%a" -> "%exit";
+	"%b" -> "%exit";
+}
+`
+	if got := buf.String(); want != got {
+		t.Errorf("DOT mismatch; expected `%v`, got `%v`", want, got)
+	}
+}
@@ -0,0 +1,16 @@
+package enum
+
+import (
+	"github.com/pkg/errors"
+)
+
+// AtomicOpFromString returns the atomicrmw binary operation corresponding to
+// the given LLVM syntax representation (e.g. "uinc_wrap").
+func AtomicOpFromString(s string) (AtomicOp, error) {
+	for op := AtomicOpAdd; op <= AtomicOpXor; op++ {
+		if op.String() == s {
+			return op, nil
+		}
+	}
+	return 0, errors.Errorf("invalid atomicrmw binary operation %q", s)
+}
@@ -0,0 +1,34 @@
+package enum
+
+import "testing"
+
+func TestAtomicOpFromString(t *testing.T) {
+	golden := []struct {
+		in   string
+		want AtomicOp
+	}{
+		{in: "add", want: AtomicOpAdd},
+		{in: "fmax", want: AtomicOpFMax},
+		{in: "fmin", want: AtomicOpFMin},
+		{in: "udec_wrap", want: AtomicOpUDecWrap},
+		{in: "uinc_wrap", want: AtomicOpUIncWrap},
+		{in: "usub_cond", want: AtomicOpUSubCond},
+		{in: "usub_sat", want: AtomicOpUSubSat},
+		{in: "xor", want: AtomicOpXor},
+	}
+	for _, g := range golden {
+		got, err := AtomicOpFromString(g.in)
+		if err != nil {
+			t.Errorf("unable to parse atomicrmw binary operation %q; %v", g.in, err)
+			continue
+		}
+		if g.want != got {
+			t.Errorf("atomicrmw binary operation mismatch; expected %v, got %v", g.want, got)
+		}
+	}
+	for _, s := range []string{"", "none", "AtomicOp(0)", "uinc"} {
+		if _, err := AtomicOpFromString(s); err == nil {
+			t.Errorf("expected error for invalid atomicrmw binary operation %q", s)
+		}
+	}
+}
@@ -1,6 +1,45 @@
 // Package enum defines enumerate types of LLVM IR.
 package enum
 
+//go:generate stringer -linecomment -type AsmDialect
+
+// AsmDialect is an inline assembler dialect.
+type AsmDialect uint8
+
+// Inline assembler dialects.
+const (
+	AsmDialectATT   AsmDialect = iota // att
+	AsmDialectIntel                   // inteldialect
+)
+
+//go:generate stringer -linecomment -type AtomicOp
+
+// AtomicOp is an atomicrmw binary operation.
+type AtomicOp uint8
+
+// atomicrmw binary operations.
+const (
+	AtomicOpAdd      AtomicOp = iota + 1 // add
+	AtomicOpAnd                          // and
+	AtomicOpFAdd                         // fadd
+	AtomicOpFMax                         // fmax
+	AtomicOpFMin                         // fmin
+	AtomicOpFSub                         // fsub
+	AtomicOpMax                          // max
+	AtomicOpMin                          // min
+	AtomicOpNAnd                         // nand
+	AtomicOpOr                           // or
+	AtomicOpSub                          // sub
+	AtomicOpUDecWrap                     // udec_wrap
+	AtomicOpUIncWrap                     // uinc_wrap
+	AtomicOpUMax                         // umax
+	AtomicOpUMin                         // umin
+	AtomicOpUSubCond                     // usub_cond
+	AtomicOpUSubSat                      // usub_sat
+	AtomicOpXChg                         // xchg
+	AtomicOpXor                          // xor
+)
+
 //go:generate stringer -linecomment -type AtomicOrdering
 
 // AtomicOrdering is an atomic ordering attribute.
@@ -110,6 +149,66 @@ const (
 	FastMathFlagReassoc                      // reassoc
 )
 
+//go:generate stringer -linecomment -type FuncAttr
+
+// FuncAttr is a function attribute.
+type FuncAttr uint8
+
+// Function attributes.
+const (
+	FuncAttrAlwaysInline                    FuncAttr = iota // alwaysinline
+	FuncAttrArgMemOnly                                      // argmemonly
+	FuncAttrBuiltin                                         // builtin
+	FuncAttrCold                                            // cold
+	FuncAttrConvergent                                      // convergent
+	FuncAttrDisableSanitizerInstrumentation                 // disable_sanitizer_instrumentation
+	FuncAttrHot                                             // hot
+	FuncAttrInaccessibleMemOnly                             // inaccessiblememonly
+	FuncAttrInaccessibleMemOrArgMemOnly                     // inaccessiblemem_or_argmemonly
+	FuncAttrInlineHint                                      // inlinehint
+	FuncAttrJumpTable                                       // jumptable
+	FuncAttrMinSize                                         // minsize
+	FuncAttrMustProgress                                    // mustprogress
+	FuncAttrNaked                                           // naked
+	FuncAttrNoBuiltin                                       // nobuiltin
+	FuncAttrNoCallback                                      // nocallback
+	FuncAttrNoCFCheck                                       // nocf_check
+	FuncAttrNoDuplicate                                     // noduplicate
+	FuncAttrNoFree                                          // nofree
+	FuncAttrNoImplicitFloat                                 // noimplicitfloat
+	FuncAttrNoInline                                        // noinline
+	FuncAttrNoMerge                                         // nomerge
+	FuncAttrNonLazyBind                                     // nonlazybind
+	FuncAttrNoProfile                                       // noprofile
+	FuncAttrNoRecurse                                       // norecurse
+	FuncAttrNoRedZone                                       // noredzone
+	FuncAttrNoReturn                                        // noreturn
+	FuncAttrNoSync                                          // nosync
+	FuncAttrNoUnwind                                        // nounwind
+	FuncAttrOptForFuzzing                                   // optforfuzzing
+	FuncAttrOptNone                                         // optnone
+	FuncAttrOptSize                                         // optsize
+	FuncAttrReadNone                                        // readnone
+	FuncAttrReadOnly                                        // readonly
+	FuncAttrReturnsTwice                                    // returns_twice
+	FuncAttrSafeStack                                       // safestack
+	FuncAttrSanitizeAddress                                 // sanitize_address
+	FuncAttrSanitizeHWAddress                               // sanitize_hwaddress
+	FuncAttrSanitizeMemory                                  // sanitize_memory
+	FuncAttrSanitizeMemTag                                  // sanitize_memtag
+	FuncAttrSanitizeThread                                  // sanitize_thread
+	FuncAttrShadowCallStack                                 // shadowcallstack
+	FuncAttrSpeculatable                                    // speculatable
+	FuncAttrSpeculativeLoadHardening                        // speculative_load_hardening
+	FuncAttrStrictFP                                        // strictfp
+	FuncAttrUWTable                                         // uwtable
+	FuncAttrWillReturn                                      // willreturn
+	FuncAttrWriteOnly                                       // writeonly
+	FuncAttrSSP                                             // ssp
+	FuncAttrSSPReq                                          // sspreq
+	FuncAttrSSPStrong                                       // sspstrong
+)
+
 //go:generate stringer -linecomment -type FPred
 
 // FPred is a floating-point comparison predicate.
@@ -176,6 +275,30 @@ const (
 	LinkageExternWeak // extern_weak
 )
 
+//go:generate stringer -linecomment -type MemoryEffect
+
+// MemoryEffect is a memory effect of a memory attribute.
+type MemoryEffect uint8
+
+// Memory effects.
+const (
+	MemoryEffectNone      MemoryEffect = iota // none
+	MemoryEffectRead                          // read
+	MemoryEffectWrite                         // write
+	MemoryEffectReadWrite                     // readwrite
+)
+
+//go:generate stringer -linecomment -type MemoryLocation
+
+// MemoryLocation is a memory location of a memory attribute.
+type MemoryLocation uint8
+
+// Memory locations.
+const (
+	MemoryLocationArgMem          MemoryLocation = iota // argmem
+	MemoryLocationInaccessibleMem                       // inaccessiblemem
+)
+
 //go:generate stringer -linecomment -type OverflowFlag
 
 // OverflowFlag is an integer overflow flag.
@@ -187,6 +310,31 @@ const (
 	OverflowFlagNUW                     // nuw
 )
 
+//go:generate stringer -linecomment -type ParamAttr
+
+// ParamAttr is a parameter attribute.
+type ParamAttr uint8
+
+// Parameter attributes.
+const (
+	ParamAttrImmarg     ParamAttr = iota // immarg
+	ParamAttrInReg                       // inreg
+	ParamAttrNest                        // nest
+	ParamAttrNoAlias                     // noalias
+	ParamAttrNoCapture                   // nocapture
+	ParamAttrNoFree                      // nofree
+	ParamAttrNonNull                     // nonnull
+	ParamAttrNoUndef                     // noundef
+	ParamAttrReadNone                    // readnone
+	ParamAttrReadOnly                    // readonly
+	ParamAttrReturned                    // returned
+	ParamAttrSignExt                     // signext
+	ParamAttrSwiftError                  // swifterror
+	ParamAttrSwiftSelf                   // swiftself
+	ParamAttrWriteOnly                   // writeonly
+	ParamAttrZeroExt                     // zeroext
+)
+
 //go:generate stringer -linecomment -type Preemption
 
 // Preemption specifies the preemtion of a global identifier.
@@ -199,6 +347,21 @@ const (
 	PreemptionDSOPreemptable                   // dso_preemptable
 )
 
+//go:generate stringer -linecomment -type ReturnAttr
+
+// ReturnAttr is a return attribute.
+type ReturnAttr uint8
+
+// Return attributes.
+const (
+	ReturnAttrInReg   ReturnAttr = iota // inreg
+	ReturnAttrNoAlias                   // noalias
+	ReturnAttrNonNull                   // nonnull
+	ReturnAttrNoUndef                   // noundef
+	ReturnAttrSignExt                   // signext
+	ReturnAttrZeroExt                   // zeroext
+)
+
 //go:generate stringer -linecomment -type SelectionKind
 
 // SelectionKind is a Comdat selection kind.
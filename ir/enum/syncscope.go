@@ -0,0 +1,28 @@
+package enum
+
+// SyncScope is an atomic synchronization scope.
+//
+// The SyncScope fields of atomic instructions are strings; use String to
+// convert a SyncScope to the string representation accepted by these fields.
+type SyncScope string
+
+// Synchronization scopes.
+const (
+	// SyncScopeSystem synchronizes with all concurrently executing code; it is
+	// the default scope and rendered as the empty string.
+	SyncScopeSystem SyncScope = ""
+	// SyncScopeSingleThread only synchronizes with code executing in the same
+	// thread (e.g. signal handlers).
+	SyncScopeSingleThread SyncScope = "singlethread"
+)
+
+// NewSyncScope returns a new synchronization scope based on the given
+// target-specific scope name (e.g. "agent" or "workgroup").
+func NewSyncScope(s string) SyncScope {
+	return SyncScope(s)
+}
+
+// String returns the string representation of the synchronization scope.
+func (s SyncScope) String() string {
+	return string(s)
+}
@@ -0,0 +1,20 @@
+package enum
+
+import "testing"
+
+func TestSyncScopeString(t *testing.T) {
+	golden := []struct {
+		in   SyncScope
+		want string
+	}{
+		{in: SyncScopeSystem, want: ""},
+		{in: SyncScopeSingleThread, want: "singlethread"},
+		{in: NewSyncScope("agent"), want: "agent"},
+	}
+	for _, g := range golden {
+		got := g.in.String()
+		if g.want != got {
+			t.Errorf("sync scope mismatch; expected `%v`, got `%v`", g.want, got)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package enum
+
+import (
+	"github.com/pkg/errors"
+)
+
+// TailFromString returns the tail call attribute corresponding to the given
+// LLVM syntax representation (e.g. "musttail"). The empty string corresponds to
+// TailNone.
+func TailFromString(s string) (Tail, error) {
+	switch s {
+	case "":
+		return TailNone, nil
+	case TailMustTail.String():
+		return TailMustTail, nil
+	case TailNoTail.String():
+		return TailNoTail, nil
+	case TailTail.String():
+		return TailTail, nil
+	}
+	return TailNone, errors.Errorf("invalid tail call attribute %q; expected musttail, notail or tail", s)
+}
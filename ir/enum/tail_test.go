@@ -0,0 +1,34 @@
+package enum
+
+import "testing"
+
+func TestTailFromString(t *testing.T) {
+	golden := []struct {
+		in   string
+		want Tail
+	}{
+		{in: "", want: TailNone},
+		{in: "musttail", want: TailMustTail},
+		{in: "notail", want: TailNoTail},
+		{in: "tail", want: TailTail},
+	}
+	for _, g := range golden {
+		got, err := TailFromString(g.in)
+		if err != nil {
+			t.Errorf("unable to parse tail call attribute %q; %v", g.in, err)
+			continue
+		}
+		if g.want != got {
+			t.Errorf("tail call attribute mismatch; expected %v, got %v", g.want, got)
+		}
+		// Round-trip.
+		if g.want != TailNone && g.in != got.String() {
+			t.Errorf("tail call attribute round-trip mismatch; expected %q, got %q", g.in, got.String())
+		}
+	}
+	for _, s := range []string{"none", "Tail", "tail call"} {
+		if _, err := TailFromString(s); err == nil {
+			t.Errorf("expected error for invalid tail call attribute %q", s)
+		}
+	}
+}
@@ -4,7 +4,7 @@
 
 package enum
 
-type AtomicOp uint
+import "fmt"
 
 type Clause struct {
 }
@@ -24,15 +24,60 @@ type UnwindTarget interface {
 	IsUnwindTarget()
 }
 
-// TODO: add proper implementations.
+// FuncAttribute is a function attribute.
+//
+// A FuncAttribute has one of the following underlying types.
+//
+//    enum.FuncAttr   // https://godoc.org/github.com/llir/l/ir/enum#FuncAttr
+//    ir.Memory       // https://godoc.org/github.com/llir/l/ir#Memory
+//    ir.AttrString   // https://godoc.org/github.com/llir/l/ir#AttrString
+//    ir.AttrPair     // https://godoc.org/github.com/llir/l/ir#AttrPair
 type FuncAttribute interface {
-	isFuncAttribute()
+	fmt.Stringer
+	// IsFuncAttribute ensures that only function attributes can be assigned to
+	// the enum.FuncAttribute interface.
+	IsFuncAttribute()
 }
 
+// ParamAttribute is a parameter attribute.
+//
+// A ParamAttribute has one of the following underlying types.
+//
+//    enum.ParamAttr        // https://godoc.org/github.com/llir/l/ir/enum#ParamAttr
+//    ir.Align              // https://godoc.org/github.com/llir/l/ir#Align
+//    ir.Dereferenceable    // https://godoc.org/github.com/llir/l/ir#Dereferenceable
+//    ir.ByVal              // https://godoc.org/github.com/llir/l/ir#ByVal
+//    ir.SRet               // https://godoc.org/github.com/llir/l/ir#SRet
+//    ir.InAlloca           // https://godoc.org/github.com/llir/l/ir#InAlloca
 type ParamAttribute interface {
-	isParamAttribute()
+	fmt.Stringer
+	// IsParamAttribute ensures that only parameter attributes can be assigned to
+	// the enum.ParamAttribute interface.
+	IsParamAttribute()
 }
 
+// ReturnAttribute is a return attribute.
+//
+// A ReturnAttribute has one of the following underlying types.
+//
+//    enum.ReturnAttr       // https://godoc.org/github.com/llir/l/ir/enum#ReturnAttr
+//    ir.Align              // https://godoc.org/github.com/llir/l/ir#Align
+//    ir.Dereferenceable    // https://godoc.org/github.com/llir/l/ir#Dereferenceable
 type ReturnAttribute interface {
-	isReturnAttribute()
+	fmt.Stringer
+	// IsReturnAttribute ensures that only return attributes can be assigned to
+	// the enum.ReturnAttribute interface.
+	IsReturnAttribute()
 }
+
+// IsFuncAttribute ensures that only function attributes can be assigned to the
+// enum.FuncAttribute interface.
+func (FuncAttr) IsFuncAttribute() {}
+
+// IsParamAttribute ensures that only parameter attributes can be assigned to
+// the enum.ParamAttribute interface.
+func (ParamAttr) IsParamAttribute() {}
+
+// IsReturnAttribute ensures that only return attributes can be assigned to the
+// enum.ReturnAttribute interface.
+func (ReturnAttr) IsReturnAttribute() {}
@@ -0,0 +1,25 @@
+package ir
+
+// IRError is an error with the location of the offending LLVM IR, as reported
+// by Function.Verify.
+type IRError struct {
+	// Function containing the offending LLVM IR.
+	Function *Function
+	// (optional) Basic block containing the offending LLVM IR; nil if not
+	// applicable.
+	Block *BasicBlock
+	// (optional) Offending instruction or terminator; nil if not applicable.
+	Inst Definer
+	// Underlying error.
+	Err error
+}
+
+// Error returns the error message of the underlying error.
+func (e *IRError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *IRError) Unwrap() error {
+	return e.Err
+}
@@ -10,6 +10,10 @@ import (
 
 // --- [ Binary expressions ] --------------------------------------------------
 
+// Note, the fadd, fsub, fmul, udiv, sdiv, fdiv, urem, srem and frem constant
+// expressions were removed in LLVM 15, and are only supported by earlier
+// versions of LLVM.
+
 // ~~~ [ add ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // ExprAdd is an LLVM IR add expression.
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/llir/l/ir/types"
+	"github.com/pkg/errors"
 )
 
 // --- [ Conversion expressions ] ----------------------------------------------
@@ -47,6 +48,12 @@ func (e *ExprTrunc) Simplify() Constant {
 	panic("not yet implemented")
 }
 
+// Validate reports an error if the trunc expression is invalid; i.e. if the
+// source value cannot be converted to the target type.
+func (e *ExprTrunc) Validate() error {
+	return validateCast("trunc", e.From.Type(), e.To)
+}
+
 // ~~~ [ zext ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // ExprZExt is an LLVM IR zext expression.
@@ -86,6 +93,12 @@ func (e *ExprZExt) Simplify() Constant {
 	panic("not yet implemented")
 }
 
+// Validate reports an error if the zext expression is invalid; i.e. if the
+// source value cannot be converted to the target type.
+func (e *ExprZExt) Validate() error {
+	return validateCast("zext", e.From.Type(), e.To)
+}
+
 // ~~~ [ sext ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // ExprSExt is an LLVM IR sext expression.
@@ -125,6 +138,12 @@ func (e *ExprSExt) Simplify() Constant {
 	panic("not yet implemented")
 }
 
+// Validate reports an error if the sext expression is invalid; i.e. if the
+// source value cannot be converted to the target type.
+func (e *ExprSExt) Validate() error {
+	return validateCast("sext", e.From.Type(), e.To)
+}
+
 // ~~~ [ fptrunc ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // ExprFPTrunc is an LLVM IR fptrunc expression.
@@ -164,6 +183,12 @@ func (e *ExprFPTrunc) Simplify() Constant {
 	panic("not yet implemented")
 }
 
+// Validate reports an error if the fptrunc expression is invalid; i.e. if the
+// source value cannot be converted to the target type.
+func (e *ExprFPTrunc) Validate() error {
+	return validateCast("fptrunc", e.From.Type(), e.To)
+}
+
 // ~~~ [ fpext ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // ExprFPExt is an LLVM IR fpext expression.
@@ -203,6 +228,12 @@ func (e *ExprFPExt) Simplify() Constant {
 	panic("not yet implemented")
 }
 
+// Validate reports an error if the fpext expression is invalid; i.e. if the
+// source value cannot be converted to the target type.
+func (e *ExprFPExt) Validate() error {
+	return validateCast("fpext", e.From.Type(), e.To)
+}
+
 // ~~~ [ fptoui ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // ExprFPToUI is an LLVM IR fptoui expression.
@@ -242,6 +273,12 @@ func (e *ExprFPToUI) Simplify() Constant {
 	panic("not yet implemented")
 }
 
+// Validate reports an error if the fptoui expression is invalid; i.e. if the
+// source value cannot be converted to the target type.
+func (e *ExprFPToUI) Validate() error {
+	return validateCast("fptoui", e.From.Type(), e.To)
+}
+
 // ~~~ [ fptosi ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // ExprFPToSI is an LLVM IR fptosi expression.
@@ -281,6 +318,12 @@ func (e *ExprFPToSI) Simplify() Constant {
 	panic("not yet implemented")
 }
 
+// Validate reports an error if the fptosi expression is invalid; i.e. if the
+// source value cannot be converted to the target type.
+func (e *ExprFPToSI) Validate() error {
+	return validateCast("fptosi", e.From.Type(), e.To)
+}
+
 // ~~~ [ uitofp ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // ExprUIToFP is an LLVM IR uitofp expression.
@@ -320,6 +363,12 @@ func (e *ExprUIToFP) Simplify() Constant {
 	panic("not yet implemented")
 }
 
+// Validate reports an error if the uitofp expression is invalid; i.e. if the
+// source value cannot be converted to the target type.
+func (e *ExprUIToFP) Validate() error {
+	return validateCast("uitofp", e.From.Type(), e.To)
+}
+
 // ~~~ [ sitofp ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // ExprSIToFP is an LLVM IR sitofp expression.
@@ -359,6 +408,12 @@ func (e *ExprSIToFP) Simplify() Constant {
 	panic("not yet implemented")
 }
 
+// Validate reports an error if the sitofp expression is invalid; i.e. if the
+// source value cannot be converted to the target type.
+func (e *ExprSIToFP) Validate() error {
+	return validateCast("sitofp", e.From.Type(), e.To)
+}
+
 // ~~~ [ ptrtoint ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // ExprPtrToInt is an LLVM IR ptrtoint expression.
@@ -398,6 +453,19 @@ func (e *ExprPtrToInt) Simplify() Constant {
 	panic("not yet implemented")
 }
 
+// Validate reports an error if the ptrtoint expression is invalid; i.e. if the
+// source value cannot be converted to the target type.
+func (e *ExprPtrToInt) Validate() error {
+	return validateCast("ptrtoint", e.From.Type(), e.To)
+}
+
+// ValidateSize reports an error if the integer type of the ptrtoint expression
+// does not match the pointer size of the source address space, as specified by
+// the given data layout. Such conversions are legal, but often unintended.
+func (e *ExprPtrToInt) ValidateSize(dl *DataLayout) error {
+	return validatePtrIntSize("ptrtoint", e.From.Type(), e.To, dl)
+}
+
 // ~~~ [ inttoptr ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // ExprIntToPtr is an LLVM IR inttoptr expression.
@@ -437,6 +505,19 @@ func (e *ExprIntToPtr) Simplify() Constant {
 	panic("not yet implemented")
 }
 
+// Validate reports an error if the inttoptr expression is invalid; i.e. if the
+// source value cannot be converted to the target type.
+func (e *ExprIntToPtr) Validate() error {
+	return validateCast("inttoptr", e.From.Type(), e.To)
+}
+
+// ValidateSize reports an error if the integer type of the inttoptr expression
+// does not match the pointer size of the target address space, as specified by
+// the given data layout. Such conversions are legal, but often unintended.
+func (e *ExprIntToPtr) ValidateSize(dl *DataLayout) error {
+	return validatePtrIntSize("inttoptr", e.From.Type(), e.To, dl)
+}
+
 // ~~~ [ bitcast ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // ExprBitCast is an LLVM IR bitcast expression.
@@ -476,6 +557,12 @@ func (e *ExprBitCast) Simplify() Constant {
 	panic("not yet implemented")
 }
 
+// Validate reports an error if the bitcast expression is invalid; i.e. if the
+// source value cannot be converted to the target type.
+func (e *ExprBitCast) Validate() error {
+	return validateCast("bitcast", e.From.Type(), e.To)
+}
+
 // ~~~ [ addrspacecast ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // ExprAddrSpaceCast is an LLVM IR addrspacecast expression.
@@ -514,3 +601,144 @@ func (e *ExprAddrSpaceCast) Ident() string {
 func (e *ExprAddrSpaceCast) Simplify() Constant {
 	panic("not yet implemented")
 }
+
+// Validate reports an error if the addrspacecast expression is invalid; i.e. if the
+// source value cannot be converted to the target type.
+func (e *ExprAddrSpaceCast) Validate() error {
+	return validateCast("addrspacecast", e.From.Type(), e.To)
+}
+
+// ### [ Helper functions ] ####################################################
+
+// validateCast reports an error if a value of type from cannot be converted to
+// type to using the given conversion opcode (e.g. "trunc").
+func validateCast(opcode string, from, to types.Type) error {
+	fromElem, fromLen, fromVec := castElemType(from)
+	toElem, toLen, toVec := castElemType(to)
+	sameShape := fromVec == toVec && fromLen == toLen
+	// Bitcasts of non-pointer types are validated by total bit size, and may
+	// change the number of vector elements (e.g. bitcast <2 x i32> to i64).
+	if opcode != "bitcast" && !sameShape {
+		return errors.Errorf("invalid %s from %v to %v; expected scalar types or vector types of equal length", opcode, from, to)
+	}
+	valid := false
+	switch opcode {
+	case "trunc", "zext", "sext":
+		x, ok1 := fromElem.(*types.IntType)
+		y, ok2 := toElem.(*types.IntType)
+		if ok1 && ok2 {
+			if opcode == "trunc" {
+				valid = x.BitSize > y.BitSize
+			} else {
+				valid = x.BitSize < y.BitSize
+			}
+		}
+	case "fptrunc", "fpext":
+		x, ok1 := fromElem.(*types.FloatType)
+		y, ok2 := toElem.(*types.FloatType)
+		if ok1 && ok2 {
+			if opcode == "fptrunc" {
+				valid = x.BitSize() > y.BitSize()
+			} else {
+				valid = x.BitSize() < y.BitSize()
+			}
+		}
+	case "fptoui", "fptosi":
+		_, ok1 := fromElem.(*types.FloatType)
+		_, ok2 := toElem.(*types.IntType)
+		valid = ok1 && ok2
+	case "uitofp", "sitofp":
+		_, ok1 := fromElem.(*types.IntType)
+		_, ok2 := toElem.(*types.FloatType)
+		valid = ok1 && ok2
+	case "ptrtoint":
+		_, ok1 := fromElem.(*types.PointerType)
+		_, ok2 := toElem.(*types.IntType)
+		valid = ok1 && ok2
+	case "inttoptr":
+		_, ok1 := fromElem.(*types.IntType)
+		_, ok2 := toElem.(*types.PointerType)
+		valid = ok1 && ok2
+	case "addrspacecast":
+		x, ok1 := fromElem.(*types.PointerType)
+		y, ok2 := toElem.(*types.PointerType)
+		valid = ok1 && ok2 && x.AddrSpace != y.AddrSpace
+	case "bitcast":
+		x, ok1 := fromElem.(*types.PointerType)
+		y, ok2 := toElem.(*types.PointerType)
+		switch {
+		case ok1 && ok2:
+			valid = sameShape && x.AddrSpace == y.AddrSpace
+		case ok1 || ok2:
+			// Pointers may only be bitcast to pointers.
+			valid = false
+		default:
+			fromSize, fromOK := castBitSize(from)
+			toSize, toOK := castBitSize(to)
+			valid = fromOK && toOK && fromSize == toSize
+		}
+	default:
+		panic(fmt.Errorf("support for conversion opcode %q not yet implemented", opcode))
+	}
+	if !valid {
+		return errors.Errorf("invalid %s from %v to %v", opcode, from, to)
+	}
+	return nil
+}
+
+// validatePtrIntSize reports an error if the integer size of the given
+// ptrtoint or inttoptr conversion between from and to does not match the
+// pointer size of the corresponding address space in the data layout. The check
+// is skipped if dl is nil.
+func validatePtrIntSize(opcode string, from, to types.Type, dl *DataLayout) error {
+	if dl == nil {
+		return nil
+	}
+	ptrType, intType := from, to
+	if opcode == "inttoptr" {
+		ptrType, intType = to, from
+	}
+	ptrElem, _, _ := castElemType(ptrType)
+	intElem, _, _ := castElemType(intType)
+	p, ok1 := ptrElem.(*types.PointerType)
+	n, ok2 := intElem.(*types.IntType)
+	if !ok1 || !ok2 {
+		// Reported by validateCast.
+		return nil
+	}
+	if size := dl.pointerSize(p.AddrSpace); n.BitSize != size {
+		return errors.Errorf("invalid %s from %v to %v; expected i%d integer type matching pointer size of address space %d, got %v", opcode, from, to, size, int64(p.AddrSpace), n)
+	}
+	return nil
+}
+
+// castElemType returns the element type of the given conversion operand type,
+// along with its vector length. The boolean return value indicates whether t
+// is a vector type.
+func castElemType(t types.Type) (elem types.Type, n int64, vec bool) {
+	if t, ok := t.(*types.VectorType); ok {
+		return t.ElemType, t.Len, true
+	}
+	return t, 0, false
+}
+
+// castBitSize returns the size in bits of the given first-class non-pointer
+// type. The boolean return value indicates success.
+func castBitSize(t types.Type) (int64, bool) {
+	switch t := t.(type) {
+	case *types.IntType:
+		return t.BitSize, true
+	case *types.FloatType:
+		return t.BitSize(), true
+	case *types.MMXType:
+		return 64, true
+	case *types.VectorType:
+		if t.Scalable {
+			// The size of scalable vectors is not known at compile time.
+			return 0, false
+		}
+		elemSize, ok := castBitSize(t.ElemType)
+		return t.Len * elemSize, ok
+	}
+	return 0, false
+}
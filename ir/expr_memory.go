@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/llir/l/ir/types"
+	"github.com/llir/l/ir/value"
 )
 
 // --- [ Memory expressions ] --------------------------------------------------
@@ -21,8 +22,14 @@ type ExprGetElementPtr struct {
 	Indices []*Index
 
 	// (optional) The result is a poison value if the calculated pointer is not
-	// an in bounds address of the allocated source object.
+	// an in bounds address of the allocated source object. Implies NUSW.
 	InBounds bool
+	// (optional) The result is a poison value if the offset computation
+	// overflows in the unsigned or signed sense, respectively.
+	NUSW bool
+	// (optional) The result is a poison value if the offset computation
+	// overflows in the unsigned sense.
+	NUW bool
 }
 
 // NewGetElementPtrExpr returns a new getelementptr expression based on the
@@ -40,17 +47,19 @@ func (e *ExprGetElementPtr) String() string {
 // Type returns the type of the constant expression.
 func (e *ExprGetElementPtr) Type() types.Type {
 	// TODO: cache type?
-	return types.NewPointer(e.ElemType)
+	indices := make([]value.Value, len(e.Indices))
+	for i, index := range e.Indices {
+		indices[i] = index.Index
+	}
+	return gepType(e.ElemType, e.Src.Type(), indices)
 }
 
 // Ident returns the identifier associated with the constant expression.
 func (e *ExprGetElementPtr) Ident() string {
-	// "getelementptr" OptInBounds "(" Type "," Type Constant "," GEPConstIndices ")"
+	// "getelementptr" GEPFlags "(" Type "," Type Constant "," GEPConstIndices ")"
 	buf := &strings.Builder{}
 	buf.WriteString("getelementptr")
-	if e.InBounds {
-		buf.WriteString(" inbounds")
-	}
+	buf.WriteString(gepFlagsString(e.InBounds, e.NUSW, e.NUW))
 	fmt.Fprintf(buf, " (%v, %v", e.ElemType, e.Src)
 	for _, index := range e.Indices {
 		fmt.Fprintf(buf, ", %v", index)
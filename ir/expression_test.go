@@ -1,5 +1,11 @@
 package ir
 
+import (
+	"testing"
+
+	"github.com/llir/l/ir/types"
+)
+
 // Assert that each constant expression implements the ir.Expression interface.
 var (
 	// Binary expressions.
@@ -50,3 +56,101 @@ var (
 	_ Expression = (*ExprFCmp)(nil)
 	_ Expression = (*ExprSelect)(nil)
 )
+
+func TestConversionExpr(t *testing.T) {
+	g := NewGlobalDef("g", NewInt(types.I8, 0))
+	printf := NewFunction("printf", types.I32, NewParam(types.I8Ptr, "format"))
+	printf.Sig.Variadic = true
+	golden := []struct {
+		in   Expression
+		want string
+	}{
+		// bitcast
+		{
+			in:   NewBitCastExpr(g, types.NewPointer(types.I32)),
+			want: "i32* bitcast (i8* @g to i32*)",
+		},
+		// ptrtoint
+		{
+			in:   NewPtrToIntExpr(g, types.I64),
+			want: "i64 ptrtoint (i8* @g to i64)",
+		},
+		// bitcast of variadic function pointer.
+		{
+			in:   NewBitCastExpr(printf, types.I8Ptr),
+			want: "i8* bitcast (i32 (i8*, ...)* @printf to i8*)",
+		},
+		{
+			in:   NewBitCastExpr(printf, types.NewPointer(types.NewFunc(types.I32, types.I8Ptr, types.I32))),
+			want: "i32 (i8*, i32)* bitcast (i32 (i8*, ...)* @printf to i32 (i8*, i32)*)",
+		},
+	}
+	for _, g := range golden {
+		if got := g.in.String(); g.want != got {
+			t.Errorf("constant expression mismatch; expected `%v`, got `%v`", g.want, got)
+		}
+	}
+}
+
+func TestConversionExprValidate(t *testing.T) {
+	g := NewGlobalDef("g", NewInt(types.I8, 0))
+	x := NewInt(types.I32, 42)
+	v := NewZeroInitializer(types.NewVector(2, types.I32))
+	golden := []struct {
+		in   interface{ Validate() error }
+		want string
+	}{
+		{in: NewBitCastExpr(g, types.NewPointer(types.I32)), want: ""},
+		{in: NewBitCastExpr(x, types.Float), want: ""},
+		{in: NewBitCastExpr(x, types.I64), want: "invalid bitcast from i32 to i64"},
+		{in: NewBitCastExpr(g, types.I64), want: "invalid bitcast from i8* to i64"},
+		{in: NewBitCastExpr(v, types.I64), want: ""},
+		{in: NewBitCastExpr(v, types.NewVector(4, types.I16)), want: ""},
+		{in: NewBitCastExpr(v, types.I32), want: "invalid bitcast from <2 x i32> to i32"},
+		{in: NewBitCastExpr(v, types.NewVector(2, types.I8Ptr)), want: "invalid bitcast from <2 x i32> to <2 x i8*>"},
+		{in: NewPtrToIntExpr(g, types.I64), want: ""},
+		{in: NewPtrToIntExpr(x, types.I64), want: "invalid ptrtoint from i32 to i64"},
+		{in: NewTruncExpr(x, types.I8), want: ""},
+		{in: NewTruncExpr(x, types.I64), want: "invalid trunc from i32 to i64"},
+		{in: NewZExtExpr(x, types.NewVector(2, types.I64)), want: "invalid zext from i32 to <2 x i64>; expected scalar types or vector types of equal length"},
+	}
+	for i, g := range golden {
+		got := ""
+		if err := g.in.Validate(); err != nil {
+			got = err.Error()
+		}
+		if g.want != got {
+			t.Errorf("i=%d: error mismatch; expected `%v`, got `%v`", i, g.want, got)
+		}
+	}
+}
+
+func TestBinaryExpr(t *testing.T) {
+	g := NewGlobalDef("g", NewInt(types.I8, 0))
+	add := NewAddExpr(NewPtrToIntExpr(g, types.I32), NewInt(types.I32, 4))
+	const want = "i32 add (i32 ptrtoint (i8* @g to i32), i32 4)"
+	if got := add.String(); want != got {
+		t.Errorf("constant expression mismatch; expected `%v`, got `%v`", want, got)
+	}
+}
+
+func TestGetElementPtrExprType(t *testing.T) {
+	st := types.NewStruct(types.I32, types.NewArray(4, types.I16))
+	g := NewGlobalDef("g", NewZeroInitializer(st))
+	zero := NewIndex(NewInt(types.I64, 0))
+	one := NewIndex(NewInt(types.I32, 1))
+	two := NewIndex(NewInt(types.I64, 2))
+	golden := []struct {
+		in   *ExprGetElementPtr
+		want string
+	}{
+		{in: NewGetElementPtrExpr(st, g, zero), want: "{ i32, [4 x i16] }*"},
+		{in: NewGetElementPtrExpr(st, g, zero, one), want: "[4 x i16]*"},
+		{in: NewGetElementPtrExpr(st, g, zero, one, two), want: "i16*"},
+	}
+	for _, g := range golden {
+		if got := g.in.Type().String(); g.want != got {
+			t.Errorf("type mismatch of %v; expected `%v`, got `%v`", g.in.Ident(), g.want, got)
+		}
+	}
+}
@@ -0,0 +1,373 @@
+package ir
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/llir/l/ir/enum"
+	"github.com/llir/l/ir/types"
+	"github.com/llir/l/ir/value"
+)
+
+// Fold returns the constant result of the given instruction, if all of its
+// operands are integer constants. The boolean return value indicates success.
+//
+// Integer arithmetic, bitwise and icmp instructions are folded. Instructions
+// with overflow flags (nsw, nuw) or the exact flag fold to poison if the
+// constraint of the flag is violated. Instructions with undefined behaviour
+// (e.g. division by zero) are not folded.
+//
+// Getelementptr instructions with a null, undef or poison source address and
+// constant indices are also folded; see foldGetElementPtr.
+//
+// Freeze instructions fold to their operand, if the operand is a constant which
+// is neither undef nor poison, nor contains undef or poison elements.
+func Fold(inst Instruction) (Constant, bool) {
+	switch inst := inst.(type) {
+	// Binary instructions.
+	case *InstAdd:
+		return foldIntBinary(inst.X, inst.Y, func(typ *types.IntType, x, y *ConstInt) (Constant, bool) {
+			return foldOverflow(typ, x, y, inst.OverflowFlags, (*big.Int).Add), true
+		})
+	case *InstSub:
+		return foldIntBinary(inst.X, inst.Y, func(typ *types.IntType, x, y *ConstInt) (Constant, bool) {
+			return foldOverflow(typ, x, y, inst.OverflowFlags, (*big.Int).Sub), true
+		})
+	case *InstMul:
+		return foldIntBinary(inst.X, inst.Y, func(typ *types.IntType, x, y *ConstInt) (Constant, bool) {
+			return foldOverflow(typ, x, y, inst.OverflowFlags, (*big.Int).Mul), true
+		})
+	case *InstUDiv:
+		return foldIntBinary(inst.X, inst.Y, func(typ *types.IntType, x, y *ConstInt) (Constant, bool) {
+			a, b := unsignedInt(typ, x.X), unsignedInt(typ, y.X)
+			if b.Sign() == 0 {
+				// Division by zero is undefined behaviour.
+				return nil, false
+			}
+			q, r := new(big.Int).QuoRem(a, b, new(big.Int))
+			if inst.Exact && r.Sign() != 0 {
+				return NewPoison(typ), true
+			}
+			return newIntResult(typ, q), true
+		})
+	case *InstSDiv:
+		return foldIntBinary(inst.X, inst.Y, func(typ *types.IntType, x, y *ConstInt) (Constant, bool) {
+			a, b := signedInt(typ, x.X), signedInt(typ, y.X)
+			if b.Sign() == 0 {
+				// Division by zero is undefined behaviour.
+				return nil, false
+			}
+			q, r := new(big.Int).QuoRem(a, b, new(big.Int))
+			if !inSignedRange(typ, q) {
+				// Overflow (e.g. INT_MIN / -1) is undefined behaviour.
+				return nil, false
+			}
+			if inst.Exact && r.Sign() != 0 {
+				return NewPoison(typ), true
+			}
+			return newIntResult(typ, q), true
+		})
+	case *InstURem:
+		return foldIntBinary(inst.X, inst.Y, func(typ *types.IntType, x, y *ConstInt) (Constant, bool) {
+			a, b := unsignedInt(typ, x.X), unsignedInt(typ, y.X)
+			if b.Sign() == 0 {
+				// Division by zero is undefined behaviour.
+				return nil, false
+			}
+			return newIntResult(typ, new(big.Int).Rem(a, b)), true
+		})
+	case *InstSRem:
+		return foldIntBinary(inst.X, inst.Y, func(typ *types.IntType, x, y *ConstInt) (Constant, bool) {
+			a, b := signedInt(typ, x.X), signedInt(typ, y.X)
+			if b.Sign() == 0 {
+				// Division by zero is undefined behaviour.
+				return nil, false
+			}
+			if !inSignedRange(typ, new(big.Int).Quo(a, b)) {
+				// Overflow (e.g. INT_MIN % -1) is undefined behaviour.
+				return nil, false
+			}
+			return newIntResult(typ, new(big.Int).Rem(a, b)), true
+		})
+	// Bitwise instructions.
+	case *InstShl:
+		return foldIntBinary(inst.X, inst.Y, func(typ *types.IntType, x, y *ConstInt) (Constant, bool) {
+			shift, ok := shiftAmount(typ, y)
+			if !ok {
+				return NewPoison(typ), true
+			}
+			shl := func(z, a, _ *big.Int) *big.Int {
+				return z.Lsh(a, shift)
+			}
+			return foldOverflow(typ, x, y, inst.OverflowFlags, shl), true
+		})
+	case *InstLShr:
+		return foldIntBinary(inst.X, inst.Y, func(typ *types.IntType, x, y *ConstInt) (Constant, bool) {
+			shift, ok := shiftAmount(typ, y)
+			if !ok {
+				return NewPoison(typ), true
+			}
+			a := unsignedInt(typ, x.X)
+			z := new(big.Int).Rsh(a, shift)
+			if inst.Exact && new(big.Int).Lsh(z, shift).Cmp(a) != 0 {
+				return NewPoison(typ), true
+			}
+			return newIntResult(typ, z), true
+		})
+	case *InstAShr:
+		return foldIntBinary(inst.X, inst.Y, func(typ *types.IntType, x, y *ConstInt) (Constant, bool) {
+			shift, ok := shiftAmount(typ, y)
+			if !ok {
+				return NewPoison(typ), true
+			}
+			a := signedInt(typ, x.X)
+			z := new(big.Int).Rsh(a, shift)
+			if inst.Exact && new(big.Int).Lsh(z, shift).Cmp(a) != 0 {
+				return NewPoison(typ), true
+			}
+			return newIntResult(typ, z), true
+		})
+	case *InstAnd:
+		return foldIntBinary(inst.X, inst.Y, func(typ *types.IntType, x, y *ConstInt) (Constant, bool) {
+			return newIntResult(typ, new(big.Int).And(unsignedInt(typ, x.X), unsignedInt(typ, y.X))), true
+		})
+	case *InstOr:
+		return foldIntBinary(inst.X, inst.Y, func(typ *types.IntType, x, y *ConstInt) (Constant, bool) {
+			return newIntResult(typ, new(big.Int).Or(unsignedInt(typ, x.X), unsignedInt(typ, y.X))), true
+		})
+	case *InstXor:
+		return foldIntBinary(inst.X, inst.Y, func(typ *types.IntType, x, y *ConstInt) (Constant, bool) {
+			return newIntResult(typ, new(big.Int).Xor(unsignedInt(typ, x.X), unsignedInt(typ, y.X))), true
+		})
+	// Memory instructions.
+	case *InstGetElementPtr:
+		return foldGetElementPtr(inst)
+	// Other instructions.
+	case *InstICmp:
+		return foldIntBinary(inst.X, inst.Y, func(typ *types.IntType, x, y *ConstInt) (Constant, bool) {
+			if foldICmp(inst.Pred, typ, x, y) {
+				return True, true
+			}
+			return False, true
+		})
+	case *InstFreeze:
+		c, ok := inst.X.(Constant)
+		if !ok || !isWellDefined(c) {
+			return nil, false
+		}
+		return c, true
+	}
+	return nil, false
+}
+
+// ### [ Helper functions ] ####################################################
+
+// foldIntBinary folds the given operands using f, if both operands are integer
+// constants of the same scalar integer type.
+func foldIntBinary(x, y value.Value, f func(typ *types.IntType, x, y *ConstInt) (Constant, bool)) (Constant, bool) {
+	a, ok := x.(*ConstInt)
+	if !ok {
+		return nil, false
+	}
+	b, ok := y.(*ConstInt)
+	if !ok {
+		return nil, false
+	}
+	if !a.Typ.Equal(b.Typ) {
+		return nil, false
+	}
+	return f(a.Typ, a, b)
+}
+
+// isWellDefined reports whether the given constant is known to be neither undef
+// nor poison, nor to contain undef or poison elements. Constant expressions are
+// conservatively considered not well-defined, as they may evaluate to poison.
+func isWellDefined(c Constant) bool {
+	switch c := c.(type) {
+	case *ConstInt, *ConstFloat, *ConstNull, *ConstZeroInitializer, *ConstCharArray, *ConstBlockAddress, *Global, *Function:
+		return true
+	case *ConstArray:
+		return allWellDefined(c.Elems)
+	case *ConstStruct:
+		return allWellDefined(c.Fields)
+	case *ConstVector:
+		return allWellDefined(c.Elems)
+	default:
+		return false
+	}
+}
+
+// allWellDefined reports whether all of the given constants are well-defined;
+// see isWellDefined.
+func allWellDefined(cs []Constant) bool {
+	for _, c := range cs {
+		if !isWellDefined(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// foldGetElementPtr folds the given getelementptr instruction, if its source
+// address is a null, undef or poison constant and all of its indices are
+// integer or poison constants.
+//
+// The result is poison if the source address or any index is poison, or if an
+// inbounds getelementptr has an undef source address (as an out of bounds base
+// address may be chosen) or computes a non-zero offset from null in the default
+// address space. Otherwise, the result is undef for undef source addresses,
+// null if all indices of a null source address are zero, and a getelementptr
+// constant expression on null if not.
+func foldGetElementPtr(inst *InstGetElementPtr) (Constant, bool) {
+	switch inst.Src.(type) {
+	case *ConstNull, *ConstUndef, *ConstPoison:
+		// constant source address.
+	default:
+		return nil, false
+	}
+	typ := inst.Type()
+	var indices []*Index
+	zero := true
+	poison := false
+	for _, index := range inst.Indices {
+		switch index := index.(type) {
+		case *ConstInt:
+			if index.X.Sign() != 0 {
+				zero = false
+			}
+			indices = append(indices, NewIndex(index))
+		case *ConstPoison:
+			poison = true
+		default:
+			return nil, false
+		}
+	}
+	if poison {
+		return NewPoison(typ), true
+	}
+	switch src := inst.Src.(type) {
+	case *ConstPoison:
+		return NewPoison(typ), true
+	case *ConstUndef:
+		if inst.InBounds {
+			return NewPoison(typ), true
+		}
+		return NewUndef(typ), true
+	case *ConstNull:
+		if zero {
+			if t, ok := typ.(*types.PointerType); ok {
+				return NewNull(t), true
+			}
+			return nil, false
+		}
+		if inst.InBounds && src.Typ.AddrSpace == 0 {
+			// No allocated object is located at null in the default address
+			// space.
+			return NewPoison(typ), true
+		}
+		expr := NewGetElementPtrExpr(inst.ElemType, src, indices...)
+		expr.InBounds = inst.InBounds
+		expr.NUSW = inst.NUSW
+		expr.NUW = inst.NUW
+		return expr, true
+	}
+	return nil, false
+}
+
+// foldOverflow folds the given operands using op, producing a poison value if
+// the result overflows in a way prohibited by the given overflow flags.
+func foldOverflow(typ *types.IntType, x, y *ConstInt, flags []enum.OverflowFlag, op func(z, a, b *big.Int) *big.Int) Constant {
+	for _, flag := range flags {
+		switch flag {
+		case enum.OverflowFlagNSW:
+			z := op(new(big.Int), signedInt(typ, x.X), signedInt(typ, y.X))
+			if !inSignedRange(typ, z) {
+				return NewPoison(typ)
+			}
+		case enum.OverflowFlagNUW:
+			z := op(new(big.Int), unsignedInt(typ, x.X), unsignedInt(typ, y.X))
+			if !inUnsignedRange(typ, z) {
+				return NewPoison(typ)
+			}
+		}
+	}
+	return newIntResult(typ, op(new(big.Int), unsignedInt(typ, x.X), unsignedInt(typ, y.X)))
+}
+
+// foldICmp reports whether the integer comparison of x and y holds.
+func foldICmp(pred enum.IPred, typ *types.IntType, x, y *ConstInt) bool {
+	switch pred {
+	case enum.IPredEQ:
+		return unsignedInt(typ, x.X).Cmp(unsignedInt(typ, y.X)) == 0
+	case enum.IPredNE:
+		return unsignedInt(typ, x.X).Cmp(unsignedInt(typ, y.X)) != 0
+	case enum.IPredSGE:
+		return signedInt(typ, x.X).Cmp(signedInt(typ, y.X)) >= 0
+	case enum.IPredSGT:
+		return signedInt(typ, x.X).Cmp(signedInt(typ, y.X)) > 0
+	case enum.IPredSLE:
+		return signedInt(typ, x.X).Cmp(signedInt(typ, y.X)) <= 0
+	case enum.IPredSLT:
+		return signedInt(typ, x.X).Cmp(signedInt(typ, y.X)) < 0
+	case enum.IPredUGE:
+		return unsignedInt(typ, x.X).Cmp(unsignedInt(typ, y.X)) >= 0
+	case enum.IPredUGT:
+		return unsignedInt(typ, x.X).Cmp(unsignedInt(typ, y.X)) > 0
+	case enum.IPredULE:
+		return unsignedInt(typ, x.X).Cmp(unsignedInt(typ, y.X)) <= 0
+	case enum.IPredULT:
+		return unsignedInt(typ, x.X).Cmp(unsignedInt(typ, y.X)) < 0
+	}
+	panic(fmt.Errorf("support for integer predicate %v not yet implemented", pred))
+}
+
+// shiftAmount returns the shift amount of the given integer constant. The
+// boolean return value is false if the shift amount is greater than or equal to
+// the bit size of the integer type.
+func shiftAmount(typ *types.IntType, y *ConstInt) (uint, bool) {
+	shift := unsignedInt(typ, y.X)
+	if shift.Cmp(big.NewInt(typ.BitSize)) >= 0 {
+		return 0, false
+	}
+	return uint(shift.Uint64()), true
+}
+
+// unsignedInt returns the unsigned interpretation of x as an integer of the
+// given type.
+func unsignedInt(typ *types.IntType, x *big.Int) *big.Int {
+	mod := new(big.Int).Lsh(big.NewInt(1), uint(typ.BitSize))
+	return new(big.Int).Mod(x, mod)
+}
+
+// signedInt returns the signed (two's complement) interpretation of x as an
+// integer of the given type.
+func signedInt(typ *types.IntType, x *big.Int) *big.Int {
+	z := unsignedInt(typ, x)
+	if z.Bit(int(typ.BitSize)-1) == 1 {
+		mod := new(big.Int).Lsh(big.NewInt(1), uint(typ.BitSize))
+		z.Sub(z, mod)
+	}
+	return z
+}
+
+// inSignedRange reports whether x is representable as a signed integer of the
+// given type.
+func inSignedRange(typ *types.IntType, x *big.Int) bool {
+	return signedInt(typ, x).Cmp(x) == 0
+}
+
+// inUnsignedRange reports whether x is representable as an unsigned integer of
+// the given type.
+func inUnsignedRange(typ *types.IntType, x *big.Int) bool {
+	return unsignedInt(typ, x).Cmp(x) == 0
+}
+
+// newIntResult returns a new integer constant of the given type, wrapping x to
+// the bit size of the type. Boolean results are represented as 0 or 1, other
+// results are represented as signed integers.
+func newIntResult(typ *types.IntType, x *big.Int) *ConstInt {
+	if typ.BitSize == 1 {
+		return &ConstInt{Typ: typ, X: unsignedInt(typ, x)}
+	}
+	return &ConstInt{Typ: typ, X: signedInt(typ, x)}
+}
@@ -0,0 +1,98 @@
+package ir
+
+import (
+	"testing"
+
+	"github.com/llir/l/ir/enum"
+	"github.com/llir/l/ir/types"
+)
+
+func TestFold(t *testing.T) {
+	x := NewParam(types.I32, "x")
+	add := NewAdd(NewInt(types.I32, 2), NewInt(types.I32, 3))
+	nsw := NewAdd(NewInt(types.I8, 127), NewInt(types.I8, 1))
+	nsw.OverflowFlags = []enum.OverflowFlag{enum.OverflowFlagNSW}
+	null := NewNull(types.NewPointer(types.I32))
+	undef := NewUndef(types.NewPointer(types.I32))
+	gepNull := NewGetElementPtr(types.I32, null, NewInt(types.I64, 1))
+	gepNullInBounds := NewGetElementPtr(types.I32, null, NewInt(types.I64, 1))
+	gepNullInBounds.InBounds = true
+	gepUndefInBounds := NewGetElementPtr(types.I32, undef, NewInt(types.I64, 1))
+	gepUndefInBounds.InBounds = true
+	vec := types.NewVector(2, types.I32)
+	golden := []struct {
+		in   Instruction
+		want string // empty if not foldable
+	}{
+		// add i32 2, 3
+		{in: add, want: "i32 5"},
+		// add i8 127, 1 (wraps)
+		{in: NewAdd(NewInt(types.I8, 127), NewInt(types.I8, 1)), want: "i8 -128"},
+		// add nsw i8 127, 1 (signed overflow)
+		{in: nsw, want: "i8 poison"},
+		// sub i32 2, 3
+		{in: NewSub(NewInt(types.I32, 2), NewInt(types.I32, 3)), want: "i32 -1"},
+		// udiv i32 -1, 2
+		{in: NewUDiv(NewInt(types.I32, -1), NewInt(types.I32, 2)), want: "i32 2147483647"},
+		// sdiv i32 7, 0 (division by zero)
+		{in: NewSDiv(NewInt(types.I32, 7), NewInt(types.I32, 0)), want: ""},
+		// srem i32 -7, 2
+		{in: NewSRem(NewInt(types.I32, -7), NewInt(types.I32, 2)), want: "i32 -1"},
+		// shl i32 1, 32 (shift amount too large)
+		{in: NewShl(NewInt(types.I32, 1), NewInt(types.I32, 32)), want: "i32 poison"},
+		// lshr i8 -128, 7
+		{in: NewLShr(NewInt(types.I8, -128), NewInt(types.I8, 7)), want: "i8 1"},
+		// ashr i8 -128, 7
+		{in: NewAShr(NewInt(types.I8, -128), NewInt(types.I8, 7)), want: "i8 -1"},
+		// xor i32 5, 3
+		{in: NewXor(NewInt(types.I32, 5), NewInt(types.I32, 3)), want: "i32 6"},
+		// icmp slt i32 -1, 0
+		{in: NewICmp(enum.IPredSLT, NewInt(types.I32, -1), NewInt(types.I32, 0)), want: "i1 true"},
+		// icmp ult i32 -1, 0
+		{in: NewICmp(enum.IPredULT, NewInt(types.I32, -1), NewInt(types.I32, 0)), want: "i1 false"},
+		// add i32 %x, 3 (non-constant operand)
+		{in: NewAdd(x, NewInt(types.I32, 3)), want: ""},
+		// getelementptr i32, i32* null, i64 0
+		{in: NewGetElementPtr(types.I32, null, NewInt(types.I64, 0)), want: "i32* null"},
+		// getelementptr i32, i32* null, i64 1
+		{in: gepNull, want: "i32* getelementptr (i32, i32* null, i64 1)"},
+		// getelementptr inbounds i32, i32* null, i64 1
+		{in: gepNullInBounds, want: "i32* poison"},
+		// getelementptr i32, i32* null, i64 poison
+		{in: NewGetElementPtr(types.I32, null, NewPoison(types.I64)), want: "i32* poison"},
+		// getelementptr i32, i32* null, i32 %x (non-constant index)
+		{in: NewGetElementPtr(types.I32, null, x), want: ""},
+		// getelementptr i32, i32* undef, i64 1
+		{in: NewGetElementPtr(types.I32, undef, NewInt(types.I64, 1)), want: "i32* undef"},
+		// getelementptr inbounds i32, i32* undef, i64 1
+		{in: gepUndefInBounds, want: "i32* poison"},
+		// freeze i32 5
+		{in: NewFreeze(NewInt(types.I32, 5)), want: "i32 5"},
+		// freeze <2 x i32> <i32 1, i32 2>
+		{in: NewFreeze(NewVector(vec, NewInt(types.I32, 1), NewInt(types.I32, 2))), want: "<2 x i32> <i32 1, i32 2>"},
+		// freeze i32 undef
+		{in: NewFreeze(NewUndef(types.I32)), want: ""},
+		// freeze i32 poison
+		{in: NewFreeze(NewPoison(types.I32)), want: ""},
+		// freeze <2 x i32> <i32 1, i32 undef>
+		{in: NewFreeze(NewVector(vec, NewInt(types.I32, 1), NewUndef(types.I32))), want: ""},
+		// freeze i32 %x (non-constant operand)
+		{in: NewFreeze(x), want: ""},
+	}
+	for _, g := range golden {
+		c, ok := Fold(g.in)
+		if g.want == "" {
+			if ok {
+				t.Errorf("unexpected folding of `%v`; got `%v`", g.in.Def(), c)
+			}
+			continue
+		}
+		if !ok {
+			t.Errorf("unable to fold `%v`", g.in.Def())
+			continue
+		}
+		if got := c.String(); g.want != got {
+			t.Errorf("folding mismatch of `%v`; expected `%v`, got `%v`", g.in.Def(), g.want, got)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package ir
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -65,6 +66,12 @@ type Function struct {
 	// (optional) Metadata attachments.
 	// TODO: add support for metadata.
 	//Metadata []*metadata.MetadataAttachment
+
+	// Symbol table from local name to position of local value in the function;
+	// lazily built by Value.
+	valueTable map[string]localPos
+	// Number of local values of the function when valueTable was built.
+	valueCount int
 }
 
 // TODO: decide whether to have the function name parameter be the first
@@ -74,15 +81,12 @@ type Function struct {
 // NewFunction returns a new function based on the given function name, return
 // type and function parameters.
 func NewFunction(name string, retType types.Type, params ...*Param) *Function {
-	panic("not yet implemented")
-	/*
-		paramTypes := make([]types.Type, len(params))
-		for i, param := range f.Params {
-			paramType[i] = param.Type()
-		}
-		sig := types.NewFunc(f.RetType, paramTypes...)
-		return &Function{Sig: sig, GlobalName: name, Params: params}
-	*/
+	paramTypes := make([]types.Type, len(params))
+	for i, param := range params {
+		paramTypes[i] = param.Type()
+	}
+	sig := types.NewFunc(retType, paramTypes...)
+	return &Function{Sig: sig, GlobalName: name, Params: params}
 }
 
 // String returns the LLVM syntax representation of the function as a type-value
@@ -153,12 +157,28 @@ func (f *Function) Def() string {
 }
 
 // AssignIDs assigns IDs to unnamed local variables.
+//
+// Local variables with numeric names (e.g. from partial numbering by the user)
+// are honored, as long as local IDs are increasing; unnamed local variables
+// are assigned the next local ID following the preceding local ID, and gaps
+// left by the user are thus not filled. An error is reported if a numeric name
+// is not greater than the preceding local ID. This also makes AssignIDs
+// idempotent; re-running AssignIDs on a function with already assigned IDs
+// leaves the function unchanged.
+//
+// An error is also reported if two or more local variables of the function
+// share the same name, as LLVM rejects redefinitions of local names. The
+// duplicate names are listed in sorted order, to produce stable error messages.
 func (f *Function) AssignIDs() error {
 	if len(f.Blocks) == 0 {
 		return nil
 	}
+	// Invalidate local value symbol table.
+	f.valueTable = nil
 	id := 0
 	names := make(map[string]value.Value)
+	// dups tracks local names used by more than one value.
+	dups := make(map[string]bool)
 	setName := func(n value.Named) error {
 		got := n.Name()
 		if isUnnamed(got) {
@@ -166,15 +186,20 @@ func (f *Function) AssignIDs() error {
 			n.SetName(name)
 			names[name] = n
 			id++
+			return nil
 		} else if isLocalID(got) {
-			want := strconv.Itoa(id)
-			if want != got {
-				return errors.Errorf("invalid local ID in function %q, expected %s, got %s", enc.Global(f.GlobalName), enc.Local(want), enc.Local(got))
+			x, err := strconv.Atoi(got)
+			if err != nil || x < id || strconv.Itoa(x) != got {
+				return errors.Errorf("invalid local ID in function %q, expected %s or greater, got %s", enc.Global(f.GlobalName), enc.Local(strconv.Itoa(id)), enc.Local(got))
 			}
-			id++
+			id = x + 1
 		} else {
 			// already named; nothing to do.
 		}
+		if _, ok := names[got]; ok {
+			dups[got] = true
+		}
+		names[got] = n
 		return nil
 	}
 	for _, param := range f.Params {
@@ -215,6 +240,279 @@ func (f *Function) AssignIDs() error {
 			return errors.WithStack(err)
 		}
 	}
+	if len(dups) > 0 {
+		// Sort duplicate names to produce stable error messages.
+		var idents []string
+		for name := range dups {
+			idents = append(idents, name)
+		}
+		sort.Strings(idents)
+		for i, name := range idents {
+			idents[i] = enc.Local(name)
+		}
+		return errors.Errorf("duplicate local names in function %q: %s", enc.Global(f.GlobalName), strings.Join(idents, ", "))
+	}
+	return nil
+}
+
+// ReplaceAll replaces all uses of the old value with the new value in the
+// instructions and terminators of the function. Case comparands of switch
+// terminators are only replaced if the new value is a constant. Uses wrapped as
+// metadata (e.g. "metadata i32 %x" arguments of llvm.dbg.value) are replaced
+// by new metadata as values, leaving the original wrappers unchanged.
+func (f *Function) ReplaceAll(old, new value.Value) {
+	replace := func(ops []*value.Value) {
+		for _, op := range ops {
+			if *op == old {
+				*op = new
+			} else if v, ok := wrappedValue(*op); ok && v == old {
+				*op = NewMetadataValue(NewMDValue(new))
+			}
+		}
+	}
+	for _, block := range f.Blocks {
+		for _, inst := range block.Insts {
+			replace(inst.Operands())
+		}
+		if block.Term != nil {
+			replace(block.Term.Operands())
+		}
+		if term, ok := block.Term.(*TermSwitch); ok {
+			if c, ok := new.(Constant); ok {
+				for _, op := range term.CaseOperands() {
+					if *op == old {
+						*op = c
+					}
+				}
+			}
+		}
+	}
+}
+
+// Value returns the local value (function parameter, basic block, instruction
+// or terminator) of the function with the given local name (without '%'
+// prefix). The boolean return value indicates success.
+//
+// Lookup is backed by a symbol table, as described for Module.Func.
+func (f *Function) Value(name string) (value.Value, bool) {
+	if f.valueTable == nil || f.valueCount != f.numLocals() {
+		f.indexValues()
+	}
+	pos, ok := f.valueTable[name]
+	if !ok {
+		return nil, false
+	}
+	if v := f.localAt(pos); v != nil && v.Name() == name {
+		return v, true
+	}
+	// Stale hit.
+	f.indexValues()
+	if pos, ok := f.valueTable[name]; ok {
+		return f.localAt(pos), true
+	}
+	return nil, false
+}
+
+// numLocals returns the number of local values (function parameters, basic
+// blocks, instructions and terminators) of the function.
+func (f *Function) numLocals() int {
+	n := len(f.Params)
+	for _, block := range f.Blocks {
+		n += 2 + len(block.Insts)
+	}
+	return n
+}
+
+// indexValues builds the local value symbol table of the function.
+func (f *Function) indexValues() {
+	f.valueTable = make(map[string]localPos)
+	add := func(v interface{}, pos localPos) {
+		if v, ok := v.(value.Named); ok && !isUnnamed(v.Name()) {
+			f.valueTable[v.Name()] = pos
+		}
+	}
+	for i, param := range f.Params {
+		add(param, localPos{block: -1, index: i})
+	}
+	for i, block := range f.Blocks {
+		add(block, localPos{block: i, index: -1})
+		for j, inst := range block.Insts {
+			add(inst, localPos{block: i, index: j})
+		}
+		add(block.Term, localPos{block: i, index: len(block.Insts)})
+	}
+	f.valueCount = f.numLocals()
+}
+
+// localPos is the position of a local value in a function.
+type localPos struct {
+	// Basic block index; or -1 for function parameters.
+	block int
+	// Parameter index, instruction index, or number of instructions for
+	// terminators; or -1 for basic blocks.
+	index int
+}
+
+// localAt returns the named local value at the given position of the function;
+// or nil if no named local value is present at the position.
+func (f *Function) localAt(pos localPos) value.Named {
+	if pos.block == -1 {
+		if pos.index < len(f.Params) {
+			return f.Params[pos.index]
+		}
+		return nil
+	}
+	if pos.block >= len(f.Blocks) {
+		return nil
+	}
+	block := f.Blocks[pos.block]
+	var v interface{}
+	switch {
+	case pos.index == -1:
+		v = block
+	case pos.index < len(block.Insts):
+		v = block.Insts[pos.index]
+	case pos.index == len(block.Insts):
+		v = block.Term
+	}
+	if v, ok := v.(value.Named); ok {
+		return v
+	}
+	return nil
+}
+
+// IsStaticAlloca reports whether the given alloca instruction is static; i.e.
+// located in the entry basic block of the function, with either no number of
+// elements or a constant number of elements.
+//
+// Static allocas have a fixed stack offset within the stack frame of the
+// function, while dynamic allocas adjust the stack pointer at run time.
+func (f *Function) IsStaticAlloca(inst *InstAlloca) bool {
+	if len(f.Blocks) == 0 {
+		return false
+	}
+	if inst.NElems != nil {
+		if _, ok := inst.NElems.(Constant); !ok {
+			return false
+		}
+	}
+	for _, i := range f.Blocks[0].Insts {
+		if i == inst {
+			return true
+		}
+	}
+	return false
+}
+
+// Verify reports an error if the function is invalid; e.g. if the type of a
+// returned value does not match the return type of the function signature, if
+// the linkage of the function is invalid for a function declaration or
+// definition, if parameter or return attributes are incompatible with their
+// types, or if an indirectbr terminator is invalid.
+//
+// The underlying error (see errors.Cause) is of type *IRError, which records
+// the location of the offending LLVM IR.
+func (f *Function) Verify() error {
+	if err := f.verifyLinkage(); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := f.verifyReturns(); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := f.verifyAttrs(); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := f.verifyIndirectBrs(); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// verifyIndirectBrs reports an error if an indirectbr terminator of the
+// function is invalid (see TermIndirectBr.Validate).
+func (f *Function) verifyIndirectBrs() error {
+	for _, block := range f.Blocks {
+		term, ok := block.Term.(*TermIndirectBr)
+		if !ok {
+			continue
+		}
+		if err := term.Validate(); err != nil {
+			return &IRError{Function: f, Block: block, Inst: term, Err: err}
+		}
+	}
+	return nil
+}
+
+// verifyAttrs reports an error if the return attributes of the function are
+// incompatible with the return type, or if the parameter attributes of a
+// function parameter are incompatible with the parameter type (see
+// Param.Validate); i.e. if signext or zeroext is applied to a type other than
+// an integer type narrower than the register width.
+func (f *Function) verifyAttrs() error {
+	for _, attr := range f.ReturnAttrs {
+		switch attr {
+		case enum.ReturnAttrSignExt, enum.ReturnAttrZeroExt:
+			if !isExtInt(f.Sig.RetType) {
+				err := errors.Errorf("invalid return attribute `%v` of function %v; expected integer type narrower than %d bits, got %v", attr, f.Ident(), regWidth, f.Sig.RetType)
+				return &IRError{Function: f, Err: err}
+			}
+		}
+	}
+	for _, param := range f.Params {
+		if err := param.Validate(); err != nil {
+			return &IRError{Function: f, Err: err}
+		}
+	}
+	return nil
+}
+
+// verifyLinkage reports an error if the linkage of the function is invalid; i.e.
+// if an extern_weak function has a body, or if an available_externally
+// function lacks a body.
+func (f *Function) verifyLinkage() error {
+	var err error
+	switch f.Linkage {
+	case enum.LinkageExternWeak:
+		if len(f.Blocks) > 0 {
+			err = errors.Errorf("invalid function definition %v with %v linkage; expected function declaration", f.Ident(), f.Linkage)
+		}
+	case enum.LinkageAvailableExternally:
+		if len(f.Blocks) == 0 {
+			err = errors.Errorf("invalid function declaration %v with %v linkage; expected function definition", f.Ident(), f.Linkage)
+		}
+	}
+	if err != nil {
+		return &IRError{Function: f, Err: err}
+	}
+	return nil
+}
+
+// verifyReturns reports an error if a ret terminator of the function does not
+// match the return type of the function signature; i.e. if the type of the
+// returned value differs from the return type, or if "ret void" is not used
+// exactly when the return type is void.
+func (f *Function) verifyReturns() error {
+	retType := f.Sig.RetType
+	for _, block := range f.Blocks {
+		term, ok := block.Term.(*TermRet)
+		if !ok {
+			continue
+		}
+		var err error
+		switch {
+		case term.X == nil:
+			if !retType.Equal(types.Void) {
+				err = errors.Errorf("invalid ret void in basic block %v of function %v; expected return value of type %v", block.Ident(), f.Ident(), retType)
+			}
+		case retType.Equal(types.Void):
+			err = errors.Errorf("invalid ret %v in basic block %v of function %v; expected ret void", term.X, block.Ident(), f.Ident())
+		case !term.X.Type().Equal(retType):
+			err = errors.Errorf("invalid return type in basic block %v of function %v; expected %v, got %v", block.Ident(), f.Ident(), retType, term.X.Type())
+		}
+		if err != nil {
+			return &IRError{Function: f, Block: block, Inst: term, Err: err}
+		}
+	}
 	return nil
 }
 
@@ -226,7 +524,7 @@ func headerString(hdr *Function) string {
 	// ReturnAttrs Type GlobalIdent "(" Params ")" OptUnnamedAddr FuncAttrs
 	// OptSection OptComdat OptGC OptPrefix OptPrologue OptPersonality
 	buf := &strings.Builder{}
-	if hdr.Preemption != enum.PreemptionNone {
+	if hdr.Preemption != enum.PreemptionNone && !implicitDSOLocal(hdr.Preemption, hdr.Linkage, hdr.Visibility) {
 		fmt.Fprintf(buf, " %v", hdr.Preemption)
 	}
 	if hdr.Visibility != enum.VisibilityNone {
@@ -288,8 +586,37 @@ func bodyString(body *Function) string {
 	// "{" BasicBlockList UseListOrders "}"
 	buf := &strings.Builder{}
 	buf.WriteString("{\n")
-	for _, block := range body.Blocks {
-		fmt.Fprintf(buf, "%v\n", block.Def())
+	preds := blockPreds(body)
+	for i, block := range body.Blocks {
+		if i == 0 {
+			// The label of an unnamed entry basic block is omitted, and the
+			// entry basic block may not have predecessors.
+			if !isUnnamed(block.LocalName) && !isLocalID(block.LocalName) {
+				fmt.Fprintf(buf, "%v\n", enc.Label(block.LocalName))
+			}
+		} else {
+			// Basic blocks are separated by blank lines, and labelled with
+			// their predecessors in a comment at column 50 (as done by LLVM).
+			buf.WriteString("\n")
+			if !isUnnamed(block.LocalName) {
+				label := enc.Label(block.LocalName)
+				pad := 50 - len(label)
+				if pad < 1 {
+					pad = 1
+				}
+				fmt.Fprintf(buf, "%s%s", label, strings.Repeat(" ", pad))
+				if len(preds[block]) == 0 {
+					buf.WriteString("; No predecessors!\n")
+				} else {
+					var idents []string
+					for _, pred := range preds[block] {
+						idents = append(idents, pred.Ident())
+					}
+					fmt.Fprintf(buf, "; preds = %s\n", strings.Join(idents, ", "))
+				}
+			}
+		}
+		fmt.Fprintf(buf, "%v\n", instsString(block))
 	}
 	// TODO: add support for use list orders.
 	//for _, useList := range body.UseListOrders {
@@ -299,11 +626,29 @@ func bodyString(body *Function) string {
 	return buf.String()
 }
 
+// blockPreds returns the predecessor basic blocks of each basic block of the
+// given function, as listed by LLVM; i.e. once per edge (e.g. twice for a
+// conditional br with identical target basic blocks), in reverse order of
+// occurrence.
+func blockPreds(f *Function) map[*BasicBlock][]*BasicBlock {
+	preds := make(map[*BasicBlock][]*BasicBlock)
+	for i := len(f.Blocks) - 1; i >= 0; i-- {
+		block := f.Blocks[i]
+		if block.Term == nil {
+			continue
+		}
+		for _, succ := range block.Term.Succs() {
+			preds[succ] = append(preds[succ], block)
+		}
+	}
+	return preds
+}
+
 // isVoidValue reports whether the given named value is a non-value (i.e. a call
-// instruction or invoke terminator with void-return type).
+// instruction, invoke terminator or callbr terminator with void-return type).
 func isVoidValue(n value.Named) bool {
 	switch n.(type) {
-	case *InstCall, *TermInvoke:
+	case *InstCall, *TermInvoke, *TermCallBr:
 		return n.Type().Equal(types.Void)
 	}
 	return false
@@ -0,0 +1,588 @@
+package ir
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/llir/l/ir/enum"
+	"github.com/llir/l/ir/types"
+	"github.com/llir/l/ir/value"
+	"github.com/pkg/errors"
+)
+
+func TestAssignIDsDuplicateNames(t *testing.T) {
+	// Duplicate local names are rejected, as in LLVM. The error message lists
+	// the duplicate names in sorted order, regardless of map iteration order.
+	const want = `duplicate local names in function "@f": %a, %x`
+	for i := 0; i < 10; i++ {
+		x := NewParam(types.I32, "x")
+		entry := NewBlock("entry")
+		a1 := entry.NewAdd(x, x)
+		a1.SetName("a")
+		x1 := entry.NewMul(x, x)
+		x1.SetName("x")
+		b1 := entry.NewSub(x, x)
+		b1.SetName("a")
+		entry.NewRet(b1)
+		f := &Function{
+			GlobalName: "f",
+			Sig:        types.NewFunc(types.I32, types.I32),
+			Params:     []*Param{x},
+			Blocks:     []*BasicBlock{entry},
+		}
+		err := f.AssignIDs()
+		if err == nil {
+			t.Fatalf("expected error for duplicate local names, got nil")
+		}
+		if got := err.Error(); want != got {
+			t.Errorf("error mismatch; expected `%v`, got `%v`", want, got)
+		}
+	}
+	// Distinct local names are accepted.
+	x := NewParam(types.I32, "x")
+	entry := NewBlock("entry")
+	a := entry.NewAdd(x, x)
+	a.SetName("a")
+	entry.NewRet(a)
+	f := NewFunction("f", types.I32, x)
+	f.Blocks = []*BasicBlock{entry}
+	if err := f.AssignIDs(); err != nil {
+		t.Errorf("unexpected error; %v", err)
+	}
+}
+
+func TestFunctionPreemption(t *testing.T) {
+	// Function headers as produced by clang; dso_local is omitted when implied
+	// by the linkage or visibility of the function.
+	golden := []struct {
+		linkage    enum.Linkage
+		visibility enum.Visibility
+		preemption enum.Preemption
+		want       string
+	}{
+		// int f(int x)
+		{
+			preemption: enum.PreemptionDSOLocal,
+			want:       "define dso_local i32 @f(i32 %x) {",
+		},
+		// static int f(int x)
+		{
+			linkage:    enum.LinkageInternal,
+			preemption: enum.PreemptionDSOLocal,
+			want:       "define internal i32 @f(i32 %x) {",
+		},
+		// __attribute__((visibility("hidden"))) int f(int x)
+		{
+			visibility: enum.VisibilityHidden,
+			preemption: enum.PreemptionDSOLocal,
+			want:       "define hidden i32 @f(i32 %x) {",
+		},
+		// int f(int x) with -fpic
+		{
+			preemption: enum.PreemptionNone,
+			want:       "define i32 @f(i32 %x) {",
+		},
+		// __attribute__((weak)) int f(int x) with -fpic
+		{
+			linkage:    enum.LinkageWeak,
+			preemption: enum.PreemptionDSOPreemptable,
+			want:       "define weak dso_preemptable i32 @f(i32 %x) {",
+		},
+	}
+	for _, g := range golden {
+		x := NewParam(types.I32, "x")
+		entry := NewBlock("entry")
+		entry.NewRet(x)
+		f := NewFunction("f", types.I32, x)
+		f.Blocks = []*BasicBlock{entry}
+		f.Linkage = g.linkage
+		f.Visibility = g.visibility
+		f.Preemption = g.preemption
+		got := strings.SplitN(f.Def(), "\n", 2)[0]
+		if g.want != got {
+			t.Errorf("function header mismatch; expected `%v`, got `%v`", g.want, got)
+		}
+	}
+}
+
+func TestFunctionReturnAttrs(t *testing.T) {
+	golden := []struct {
+		in   *Function
+		want string
+	}{
+		// dereferenceable return attribute.
+		{
+			in: &Function{
+				GlobalName:  "f",
+				Sig:         types.NewFunc(types.I8Ptr),
+				ReturnAttrs: []enum.ReturnAttribute{Dereferenceable{N: 16}},
+			},
+			want: "declare dereferenceable(16) i8* @f()",
+		},
+		// Return attributes after calling convention.
+		{
+			in: &Function{
+				GlobalName:  "g",
+				Sig:         types.NewFunc(types.I8Ptr),
+				CallingConv: enum.CallingConvFast,
+				ReturnAttrs: []enum.ReturnAttribute{enum.ReturnAttrNoAlias, Align(8), Dereferenceable{N: 16, DerefOrNull: true}},
+			},
+			want: "declare fastcc noalias align 8 dereferenceable_or_null(16) i8* @g()",
+		},
+	}
+	for _, g := range golden {
+		got := g.in.Def()
+		if g.want != got {
+			t.Errorf("function mismatch; expected `%v`, got `%v`", g.want, got)
+		}
+	}
+}
+
+func TestFunctionFuncAttrs(t *testing.T) {
+	golden := []struct {
+		in   []enum.FuncAttribute
+		want string
+	}{
+		{
+			in:   []enum.FuncAttribute{Memory{}},
+			want: "declare void @f() memory(none)",
+		},
+		{
+			in:   []enum.FuncAttribute{Memory{Locations: []MemoryLocationEffect{{Location: enum.MemoryLocationArgMem, Effect: enum.MemoryEffectReadWrite}}}},
+			want: "declare void @f() memory(argmem: readwrite)",
+		},
+		{
+			in:   []enum.FuncAttribute{enum.FuncAttrMustProgress, Memory{Default: enum.MemoryEffectRead, Locations: []MemoryLocationEffect{{Location: enum.MemoryLocationArgMem, Effect: enum.MemoryEffectWrite}}}, enum.FuncAttrWillReturn},
+			want: "declare void @f() mustprogress memory(read, argmem: write) willreturn",
+		},
+		{
+			in:   []enum.FuncAttribute{enum.FuncAttrNoFree, enum.FuncAttrNoSync, enum.FuncAttrNoCallback, enum.FuncAttrNoUnwind},
+			want: "declare void @f() nofree nosync nocallback nounwind",
+		},
+		{
+			in:   []enum.FuncAttribute{enum.FuncAttrSSPStrong, AttrPair{Key: "stack-protector-buffer-size", Value: "8"}},
+			want: `declare void @f() sspstrong "stack-protector-buffer-size"="8"`,
+		},
+		{
+			in:   []enum.FuncAttribute{enum.FuncAttrSSP, enum.FuncAttrSSPReq, AttrString("no-trapping-math")},
+			want: `declare void @f() ssp sspreq "no-trapping-math"`,
+		},
+	}
+	for _, g := range golden {
+		f := &Function{
+			GlobalName: "f",
+			Sig:        types.NewFunc(types.Void),
+			FuncAttrs:  g.in,
+		}
+		got := f.Def()
+		if g.want != got {
+			t.Errorf("function mismatch; expected `%v`, got `%v`", g.want, got)
+		}
+	}
+}
+
+func TestIsStaticAlloca(t *testing.T) {
+	n := NewParam(types.I32, "n")
+	entry := NewBlock("entry")
+	static := entry.NewAlloca(types.I32)
+	staticArr := entry.NewAlloca(types.I32)
+	staticArr.NElems = NewInt(types.I32, 4)
+	dynamicSize := entry.NewAlloca(types.I32)
+	dynamicSize.NElems = n
+	loop := NewBlock("loop")
+	entry.NewBr(loop)
+	dynamic := loop.NewAlloca(types.I32)
+	loop.NewRet(nil)
+	f := &Function{
+		GlobalName: "f",
+		Sig:        types.NewFunc(types.Void, types.I32),
+		Params:     []*Param{n},
+		Blocks:     []*BasicBlock{entry, loop},
+	}
+	golden := []struct {
+		in   *InstAlloca
+		want bool
+	}{
+		{in: static, want: true},
+		{in: staticArr, want: true},
+		{in: dynamicSize, want: false},
+		{in: dynamic, want: false},
+	}
+	for i, g := range golden {
+		if got := f.IsStaticAlloca(g.in); g.want != got {
+			t.Errorf("i=%d: static alloca mismatch; expected %v, got %v", i, g.want, got)
+		}
+	}
+}
+
+func TestAssignIDsIdempotent(t *testing.T) {
+	g := &Function{GlobalName: "g", Sig: types.NewFunc(types.Void, types.I32)}
+	x := NewParam(types.I32, "")
+	entry := NewBlock("")
+	a := entry.NewAdd(x, x)
+	entry.NewCall(g, a)
+	y := entry.NewMul(a, a)
+	y.SetName("y")
+	exit := NewBlock("")
+	entry.NewBr(exit)
+	b := exit.NewSub(y, a)
+	exit.NewRet(b)
+	f := &Function{
+		GlobalName: "f",
+		Sig:        types.NewFunc(types.I32, types.I32),
+		Params:     []*Param{x},
+		Blocks:     []*BasicBlock{entry, exit},
+	}
+	if err := f.AssignIDs(); err != nil {
+		t.Fatalf("unable to assign IDs; %v", err)
+	}
+	want := f.Def()
+	if err := f.AssignIDs(); err != nil {
+		t.Fatalf("unable to re-assign IDs; %v", err)
+	}
+	if got := f.Def(); want != got {
+		t.Errorf("function mismatch; expected `%v`, got `%v`", want, got)
+	}
+	const wantIDs = "%0 %1 %2 %3 %4"
+	if got := strings.Join([]string{x.Ident(), entry.Ident(), a.Ident(), exit.Ident(), b.Ident()}, " "); wantIDs != got {
+		t.Errorf("local IDs mismatch; expected `%v`, got `%v`", wantIDs, got)
+	}
+}
+
+func TestFunctionVerifyReturns(t *testing.T) {
+	// Correct void return.
+	entry := NewBlock("entry")
+	entry.NewRet(nil)
+	f := &Function{
+		GlobalName: "f",
+		Sig:        types.NewFunc(types.Void),
+		Blocks:     []*BasicBlock{entry},
+	}
+	if err := f.Verify(); err != nil {
+		t.Errorf("unexpected error for `%v`; %v", f.Def(), err)
+	}
+	// Mismatched return type.
+	golden := []struct {
+		retType types.Type
+		x       value.Value
+		want    string
+	}{
+		{
+			retType: types.I32,
+			x:       NewInt(types.I64, 1),
+			want:    "invalid return type in basic block %exit of function @g; expected i32, got i64",
+		},
+		{
+			retType: types.I32,
+			x:       nil,
+			want:    "invalid ret void in basic block %exit of function @g; expected return value of type i32",
+		},
+		{
+			retType: types.Void,
+			x:       NewInt(types.I32, 1),
+			want:    "invalid ret i32 1 in basic block %exit of function @g; expected ret void",
+		},
+	}
+	for _, g := range golden {
+		entry := NewBlock("entry")
+		exit := NewBlock("exit")
+		entry.NewBr(exit)
+		exit.NewRet(g.x)
+		f := &Function{
+			GlobalName: "g",
+			Sig:        types.NewFunc(g.retType),
+			Blocks:     []*BasicBlock{entry, exit},
+		}
+		err := f.Verify()
+		if err == nil {
+			t.Errorf("expected error for `%v`, got nil", f.Def())
+			continue
+		}
+		if got := err.Error(); g.want != got {
+			t.Errorf("error mismatch; expected `%v`, got `%v`", g.want, got)
+		}
+	}
+}
+
+func TestFunctionValue(t *testing.T) {
+	x := NewParam(types.I32, "x")
+	entry := NewBlock("entry")
+	y := entry.NewAdd(x, x)
+	y.SetName("y")
+	entry.NewRet(y)
+	f := NewFunction("f", types.I32, x)
+	f.Blocks = []*BasicBlock{entry}
+	golden := []struct {
+		name string
+		want value.Value
+	}{
+		{name: "x", want: x},
+		{name: "entry", want: entry},
+		{name: "y", want: y},
+	}
+	for _, g := range golden {
+		if got, ok := f.Value(g.name); !ok || got != g.want {
+			t.Errorf("local value lookup mismatch of %q; expected %v, got %v (ok=%v)", g.name, g.want.Ident(), got, ok)
+		}
+	}
+	// Miss.
+	if got, ok := f.Value("z"); ok {
+		t.Errorf("unexpected local value %v found", got.Ident())
+	}
+	// Hit after instructions are added and renamed.
+	z := NewMul(y, y)
+	z.SetName("z")
+	entry.Insts = append(entry.Insts, z)
+	if got, ok := f.Value("z"); !ok || got != z {
+		t.Errorf("local value lookup mismatch of %q; expected %v, got %v (ok=%v)", "z", z.Ident(), got, ok)
+	}
+	// Misses do not rebuild the symbol table.
+	valueTable := reflect.ValueOf(f.valueTable).Pointer()
+	if got, ok := f.Value("v"); ok {
+		t.Errorf("unexpected local value %v found", got.Ident())
+	}
+	if valueTable != reflect.ValueOf(f.valueTable).Pointer() {
+		t.Errorf("local value symbol table rebuilt on miss")
+	}
+	// Hit by new name after rename, once the stale hit of the old name has
+	// rebuilt the symbol table.
+	y.SetName("w")
+	if got, ok := f.Value("y"); ok {
+		t.Errorf("unexpected local value %v found", got.Ident())
+	}
+	if got, ok := f.Value("w"); !ok || got != y {
+		t.Errorf("local value lookup mismatch of %q; expected %v, got %v (ok=%v)", "w", y.Ident(), got, ok)
+	}
+	// Hit after in-place replacement with a value of the same name.
+	w := NewSub(x, x)
+	w.SetName("w")
+	entry.Insts[0] = w
+	if got, ok := f.Value("w"); !ok || got != w {
+		t.Errorf("local value lookup mismatch of %q; expected %v, got %v (ok=%v)", "w", w.Ident(), got, ok)
+	}
+}
+
+func TestFunctionVerifyLinkage(t *testing.T) {
+	golden := []struct {
+		linkage enum.Linkage
+		body    bool
+		want    string // empty if valid
+	}{
+		// Weak declaration.
+		{linkage: enum.LinkageWeak, body: false},
+		// Weak definition.
+		{linkage: enum.LinkageWeak, body: true},
+		// extern_weak declaration.
+		{linkage: enum.LinkageExternWeak, body: false},
+		// extern_weak definition.
+		{
+			linkage: enum.LinkageExternWeak,
+			body:    true,
+			want:    "invalid function definition @f with extern_weak linkage; expected function declaration",
+		},
+		// available_externally definition.
+		{linkage: enum.LinkageAvailableExternally, body: true},
+		// available_externally declaration.
+		{
+			linkage: enum.LinkageAvailableExternally,
+			body:    false,
+			want:    "invalid function declaration @f with available_externally linkage; expected function definition",
+		},
+	}
+	for _, g := range golden {
+		f := NewFunction("f", types.Void)
+		f.Linkage = g.linkage
+		if g.body {
+			entry := NewBlock("entry")
+			entry.NewRet(nil)
+			f.Blocks = []*BasicBlock{entry}
+		}
+		err := f.Verify()
+		if g.want == "" {
+			if err != nil {
+				t.Errorf("unexpected error for `%v`; %v", f.Def(), err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("expected error for `%v`, got nil", f.Def())
+			continue
+		}
+		if got := err.Error(); g.want != got {
+			t.Errorf("error mismatch; expected `%v`, got `%v`", g.want, got)
+		}
+	}
+}
+
+func TestFunctionVerifyIRError(t *testing.T) {
+	entry := NewBlock("entry")
+	exit := NewBlock("exit")
+	entry.NewBr(exit)
+	ret := exit.NewRet(NewInt(types.I64, 1))
+	f := NewFunction("f", types.I32)
+	f.Blocks = []*BasicBlock{entry, exit}
+	err := f.Verify()
+	e, ok := errors.Cause(err).(*IRError)
+	if !ok {
+		t.Fatalf("invalid error type; expected *ir.IRError, got %T", errors.Cause(err))
+	}
+	if e.Function != f {
+		t.Errorf("function mismatch; expected %v, got %v", f.Ident(), e.Function)
+	}
+	if e.Block != exit {
+		t.Errorf("basic block mismatch; expected %v, got %v", exit.Ident(), e.Block)
+	}
+	if e.Inst != ret {
+		t.Errorf("terminator mismatch; expected `%v`, got %v", ret.Def(), e.Inst)
+	}
+}
+
+func TestAssignIDsPartial(t *testing.T) {
+	golden := []struct {
+		names []string // names of parameter and instructions
+		want  []string // local IDs after AssignIDs; nil on error
+	}{
+		// Partially numbered function.
+		{names: []string{"0", "", "2", ""}, want: []string{"%0", "%1", "%2", "%3"}},
+		// Gaps in numbering are honored, but not filled; unnamed local variables
+		// are assigned the local ID following the preceding local ID.
+		{names: []string{"", "5", "", "x", ""}, want: []string{"%0", "%5", "%6", "%x", "%7"}},
+		// Conflicting local IDs.
+		{names: []string{"", "0", ""}, want: nil},
+		// Decreasing local IDs.
+		{names: []string{"3", "2"}, want: nil},
+		// Non-canonical local ID.
+		{names: []string{"01"}, want: nil},
+	}
+	for _, g := range golden {
+		x := NewParam(types.I32, g.names[0])
+		entry := NewBlock("entry")
+		vals := []value.Named{x}
+		for _, name := range g.names[1:] {
+			inst := entry.NewAdd(x, x)
+			inst.SetName(name)
+			vals = append(vals, inst)
+		}
+		entry.NewRet(nil)
+		f := NewFunction("f", types.Void, x)
+		f.Blocks = []*BasicBlock{entry}
+		err := f.AssignIDs()
+		if g.want == nil {
+			if err == nil {
+				t.Errorf("expected error for local names %q, got nil", g.names)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error for local names %q; %v", g.names, err)
+			continue
+		}
+		for i, v := range vals {
+			if got := v.Ident(); g.want[i] != got {
+				t.Errorf("local ID mismatch of value %d; expected `%v`, got `%v`", i, g.want[i], got)
+			}
+		}
+	}
+}
+
+func TestAssignIDsTermResults(t *testing.T) {
+	g := NewFunction("g", types.I32)
+	h := NewFunction("h", types.Void)
+	asm := NewInlineAsm(types.NewFunc(types.I32), "", "=r,!i")
+	entry := NewBlock("")
+	normal := NewBlock("")
+	lpad := NewBlock("")
+	indirect := NewBlock("")
+	exit := NewBlock("")
+	entry.NewInvoke(g, nil, normal, lpad)
+	// void invoke results are not numbered.
+	normal.NewInvoke(h, nil, lpad, lpad)
+	lpad.NewCallBr(asm, nil, exit, indirect)
+	indirect.NewUnreachable()
+	exit.NewUnreachable()
+	f := NewFunction("f", types.Void)
+	f.Blocks = []*BasicBlock{entry, normal, lpad, indirect, exit}
+	if err := f.AssignIDs(); err != nil {
+		t.Fatal(err)
+	}
+	want := `define void @f() {
+  %1 = invoke i32 @g() to label %2 unwind label %3
+
+2:                                                ; preds = %0
+  invoke void @h() to label %3 unwind label %3
+
+3:                                                ; preds = %2, %2, %0
+  %4 = callbr i32 asm "", "=r,!i"() to label %6 [label %5]
+
+5:                                                ; preds = %3
+  unreachable
+
+6:                                                ; preds = %3
+  unreachable
+}`
+	if got := f.Def(); want != got {
+		t.Errorf("function mismatch; expected `%v`, got `%v`", want, got)
+	}
+}
+
+func TestFunctionExtAttrs(t *testing.T) {
+	golden := []struct {
+		retType   types.Type
+		retAttr   enum.ReturnAttr
+		paramType types.Type
+		paramAttr enum.ParamAttr
+		wantDef   string
+		wantErr   string // empty if valid
+	}{
+		// signext i8 parameter and return value.
+		{
+			retType: types.I8, retAttr: enum.ReturnAttrSignExt,
+			paramType: types.I8, paramAttr: enum.ParamAttrSignExt,
+			wantDef: "declare signext i8 @f(i8 signext %x)",
+		},
+		// zeroext i16 parameter and i1 return value.
+		{
+			retType: types.I1, retAttr: enum.ReturnAttrZeroExt,
+			paramType: types.I16, paramAttr: enum.ParamAttrZeroExt,
+			wantDef: "declare zeroext i1 @f(i16 zeroext %x)",
+		},
+		// signext i64 return value.
+		{
+			retType: types.I64, retAttr: enum.ReturnAttrSignExt,
+			paramType: types.I8, paramAttr: enum.ParamAttrSignExt,
+			wantDef: "declare signext i64 @f(i8 signext %x)",
+			wantErr: "invalid return attribute `signext` of function @f; expected integer type narrower than 64 bits, got i64",
+		},
+		// zeroext float parameter.
+		{
+			retType: types.I8, retAttr: enum.ReturnAttrZeroExt,
+			paramType: types.Float, paramAttr: enum.ParamAttrZeroExt,
+			wantDef: "declare zeroext i8 @f(float zeroext %x)",
+			wantErr: "invalid parameter attribute `zeroext` of parameter %x; expected integer type narrower than 64 bits, got float",
+		},
+	}
+	for _, g := range golden {
+		x := NewParam(g.paramType, "x")
+		x.Attrs = []enum.ParamAttribute{g.paramAttr}
+		f := NewFunction("f", g.retType, x)
+		f.ReturnAttrs = []enum.ReturnAttribute{g.retAttr}
+		if got := f.Def(); g.wantDef != got {
+			t.Errorf("function mismatch; expected `%v`, got `%v`", g.wantDef, got)
+		}
+		err := f.Verify()
+		if g.wantErr == "" {
+			if err != nil {
+				t.Errorf("unexpected error for `%v`; %v", f.Def(), err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("expected error for `%v`, got nil", f.Def())
+			continue
+		}
+		if got := err.Error(); g.wantErr != got {
+			t.Errorf("error mismatch; expected `%v`, got `%v`", g.wantErr, got)
+		}
+	}
+}
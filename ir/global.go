@@ -7,6 +7,7 @@ import (
 	"github.com/llir/l/internal/enc"
 	"github.com/llir/l/ir/enum"
 	"github.com/llir/l/ir/types"
+	"github.com/pkg/errors"
 )
 
 // === [ Global variables ] ====================================================
@@ -107,8 +108,12 @@ func (g *Global) Def() string {
 	fmt.Fprintf(buf, "%s =", g.Ident())
 	if g.Linkage != enum.LinkageNone {
 		fmt.Fprintf(buf, " %s", g.Linkage)
+	} else if g.Init == nil {
+		// Global variable declarations without explicit linkage have external
+		// linkage.
+		buf.WriteString(" external")
 	}
-	if g.Preemption != enum.PreemptionNone {
+	if g.Preemption != enum.PreemptionNone && !implicitDSOLocal(g.Preemption, g.Linkage, g.Visibility) {
 		fmt.Fprintf(buf, " %s", g.Preemption)
 	}
 	if g.Visibility != enum.VisibilityNone {
@@ -123,8 +128,8 @@ func (g *Global) Def() string {
 	if g.UnnamedAddr != enum.UnnamedAddrNone {
 		fmt.Fprintf(buf, " %s", g.UnnamedAddr)
 	}
-	if g.Typ.AddrSpace != 0 {
-		fmt.Fprintf(buf, " %s", g.Typ.AddrSpace)
+	if t := g.Type().(*types.PointerType); t.AddrSpace != 0 {
+		fmt.Fprintf(buf, " %s", t.AddrSpace)
 	}
 	if g.ExternallyInitialized {
 		buf.WriteString(" externallyinitialized")
@@ -157,3 +162,13 @@ func (g *Global) Def() string {
 	//}
 	return buf.String()
 }
+
+// Validate reports an error if the global variable is invalid; i.e. if the
+// type of the initial value of a global variable definition differs from its
+// content type.
+func (g *Global) Validate() error {
+	if g.Init != nil && !g.Init.Type().Equal(g.ContentType) {
+		return errors.Errorf("invalid initial value type of global variable %s; expected %v, got %v", g.Ident(), g.ContentType, g.Init.Type())
+	}
+	return nil
+}
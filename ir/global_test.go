@@ -0,0 +1,97 @@
+package ir
+
+import (
+	"testing"
+
+	"github.com/llir/l/ir/enum"
+	"github.com/llir/l/ir/types"
+)
+
+func TestGlobalDef(t *testing.T) {
+	golden := []struct {
+		in   *Global
+		want string
+	}{
+		// External declaration.
+		{
+			in:   NewGlobalDecl("g", types.I32),
+			want: "@g = external global i32",
+		},
+		// External declaration with explicit linkage.
+		{
+			in:   &Global{GlobalName: "g", ContentType: types.I32, Linkage: enum.LinkageExternWeak},
+			want: "@g = extern_weak global i32",
+		},
+		// Thread local external declaration in non-default address space.
+		{
+			in: &Global{
+				GlobalName:  "g",
+				ContentType: types.I32,
+				TLSModel:    enum.TLSModelGeneric,
+				Typ:         &types.PointerType{ElemType: types.I32, AddrSpace: 1},
+			},
+			want: "@g = external thread_local addrspace(1) global i32",
+		},
+		// Definition.
+		{
+			in:   NewGlobalDef("g", NewInt(types.I32, 42)),
+			want: "@g = global i32 42",
+		},
+		// Immutable definition with linkage.
+		{
+			in: &Global{
+				GlobalName:  "g",
+				Immutable:   true,
+				ContentType: types.I32,
+				Init:        NewInt(types.I32, 42),
+				Linkage:     enum.LinkageInternal,
+			},
+			want: "@g = internal constant i32 42",
+		},
+		// dso_local definition.
+		{
+			in: &Global{
+				GlobalName:  "g",
+				ContentType: types.I32,
+				Init:        NewInt(types.I32, 0),
+				Preemption:  enum.PreemptionDSOLocal,
+			},
+			want: "@g = dso_local global i32 0",
+		},
+		// Implicit dso_local of internal definition.
+		{
+			in: &Global{
+				GlobalName:  "g",
+				ContentType: types.I32,
+				Init:        NewInt(types.I32, 0),
+				Linkage:     enum.LinkageInternal,
+				Preemption:  enum.PreemptionDSOLocal,
+			},
+			want: "@g = internal global i32 0",
+		},
+	}
+	for _, g := range golden {
+		got := g.in.Def()
+		if g.want != got {
+			t.Errorf("global mismatch; expected `%v`, got `%v`", g.want, got)
+		}
+	}
+}
+
+func TestGlobalValidate(t *testing.T) {
+	// Matching initializer.
+	g := NewGlobalDef("x", NewInt(types.I32, 1))
+	if err := g.Validate(); err != nil {
+		t.Errorf("unexpected error; %v", err)
+	}
+	// Declarations have no initializer.
+	if err := NewGlobalDecl("y", types.I8).Validate(); err != nil {
+		t.Errorf("unexpected error; %v", err)
+	}
+	// Mismatched initializer.
+	g.ContentType = types.I64
+	want := "invalid initial value type of global variable @x; expected i64, got i32"
+	if err := g.Validate(); err == nil || err.Error() != want {
+		t.Errorf("error mismatch; expected `%v`, got `%v`", want, err)
+	}
+}
@@ -7,15 +7,18 @@ import (
 	"github.com/llir/l/internal/enc"
 	"github.com/llir/l/ir/enum"
 	"github.com/llir/l/ir/types"
+	"github.com/llir/l/ir/value"
+	"github.com/pkg/errors"
 )
 
 // TODO: move to the right place.
 
-// TODO: figure out definition of arg.
-type Arg interface {
-	String() string
-	isArg()
-}
+// Arg is a function argument of a call, invoke or callbr, or an exception
+// argument of a catchpad or cleanuppad.
+//
+// Arg is an alias of value.Value, so that operands of argument lists may be
+// updated in place (see Operands).
+type Arg = value.Value
 
 // TODO: remove IsUnwindTarget? or unexport.
 func (*BasicBlock) IsUnwindTarget() {}
@@ -80,8 +83,103 @@ func (p *Param) Def() string {
 	return buf.String()
 }
 
+// Validate reports an error if the parameter attributes are incompatible with
+// the parameter type; type-carrying attributes (byval, sret and inalloca) may
+// only be applied to pointer parameters, and extension attributes (signext and
+// zeroext) may only be applied to integer parameters narrower than the
+// register width.
+func (p *Param) Validate() error {
+	for _, attr := range p.Attrs {
+		switch attr {
+		case enum.ParamAttrSignExt, enum.ParamAttrZeroExt:
+			if !isExtInt(p.Typ) {
+				return errors.Errorf("invalid parameter attribute `%v` of parameter %s; expected integer type narrower than %d bits, got %v", attr, p.Ident(), regWidth, p.Typ)
+			}
+		}
+		switch attr.(type) {
+		case ByVal, SRet, InAlloca:
+			if !types.IsPointer(p.Typ) {
+				return errors.Errorf("invalid parameter attribute `%v` of parameter %s; expected pointer type, got %v", attr, p.Ident(), p.Typ)
+			}
+		}
+	}
+	return nil
+}
+
 // ### [ Helper functions ] ####################################################
 
+// implicitDSOLocal reports whether the given preemption specifier is dso_local
+// and implied by the linkage and visibility of a global identifier; i.e. if the
+// global identifier has local linkage (internal or private), or non-default
+// visibility without extern_weak linkage. Implicit dso_local specifiers are
+// omitted from the output, as done by LLVM.
+func implicitDSOLocal(preemption enum.Preemption, linkage enum.Linkage, visibility enum.Visibility) bool {
+	if preemption != enum.PreemptionDSOLocal {
+		return false
+	}
+	switch linkage {
+	case enum.LinkageInternal, enum.LinkagePrivate:
+		return true
+	}
+	switch visibility {
+	case enum.VisibilityHidden, enum.VisibilityProtected:
+		return linkage != enum.LinkageExternWeak
+	}
+	return false
+}
+
+// hasParamAttr reports whether the given parameter attributes contain attr.
+func hasParamAttr(attrs []enum.ParamAttribute, attr enum.ParamAttr) bool {
+	for _, a := range attrs {
+		if a == attr {
+			return true
+		}
+	}
+	return false
+}
+
+// calleeSig returns the function signature of the given callee. An explicit
+// function type (e.g. the Typ of a call to a variadic callee) takes precedence,
+// followed by the signature of a *Function callee and the pointee type of the
+// callee. A nil signature is returned for other callees of opaque pointer type
+// (e.g. "ptr"), as their signature is unknown.
+func calleeSig(callee value.Value, typ types.Type) (*types.FuncType, error) {
+	if sig, ok := typ.(*types.FuncType); ok {
+		return sig, nil
+	}
+	if f, ok := callee.(*Function); ok {
+		return f.Sig, nil
+	}
+	t, ok := callee.Type().(*types.PointerType)
+	if !ok {
+		return nil, errors.Errorf("invalid callee type; expected *types.PointerType, got %T", callee.Type())
+	}
+	if t.ElemType == nil {
+		// Opaque pointer type.
+		return nil, nil
+	}
+	sig, ok := t.ElemType.(*types.FuncType)
+	if !ok {
+		return nil, errors.Errorf("invalid callee type; expected *types.FuncType, got %T", t.ElemType)
+	}
+	return sig, nil
+}
+
+// regWidth is the register width in bits, below which integer arguments and
+// return values may be sign or zero extended by the signext and zeroext
+// attributes.
+const regWidth = 64
+
+// isExtInt reports whether the given type is an integer type narrower than the
+// register width, and may thus be sign or zero extended by the signext and
+// zeroext attributes.
+func isExtInt(t types.Type) bool {
+	if t, ok := t.(*types.IntType); ok {
+		return t.BitSize < regWidth
+	}
+	return false
+}
+
 // isUnnamed reports whether the given identifier is unnamed.
 func isUnnamed(name string) bool {
 	return len(name) == 0
@@ -0,0 +1,91 @@
+package ir
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/llir/l/ir/enum"
+	"github.com/llir/l/ir/types"
+)
+
+func TestParamDef(t *testing.T) {
+	pair := types.NewStruct(types.I32, types.I32)
+	golden := []struct {
+		in   *Param
+		want string
+	}{
+		// byval parameter.
+		{
+			in:   &Param{Typ: types.NewPointer(pair), LocalName: "p", Attrs: []enum.ParamAttribute{ByVal{Typ: pair}}},
+			want: "{ i32, i32 }* byval({ i32, i32 }) %p",
+		},
+		// dereferenceable parameter.
+		{
+			in:   &Param{Typ: types.I8Ptr, LocalName: "p", Attrs: []enum.ParamAttribute{enum.ParamAttrNoUndef, Dereferenceable{N: 8}}},
+			want: "i8* noundef dereferenceable(8) %p",
+		},
+		// dereferenceable_or_null and alignment.
+		{
+			in:   &Param{Typ: types.I8Ptr, LocalName: "p", Attrs: []enum.ParamAttribute{Dereferenceable{N: 4, DerefOrNull: true}, Align(4)}},
+			want: "i8* dereferenceable_or_null(4) align 4 %p",
+		},
+		// sret parameter.
+		{
+			in:   &Param{Typ: types.NewPointer(pair), Attrs: []enum.ParamAttribute{SRet{Typ: pair}}},
+			want: "{ i32, i32 }* sret({ i32, i32 })",
+		},
+		// Named parameter.
+		{
+			in:   NewParam(types.I32, "x"),
+			want: "i32 %x",
+		},
+		// Unnamed parameter.
+		{
+			in:   NewParam(types.I32, ""),
+			want: "i32",
+		},
+		// Parameter with local ID.
+		{
+			in:   NewParam(types.I32, "0"),
+			want: "i32",
+		},
+	}
+	for _, g := range golden {
+		got := g.in.Def()
+		if g.want != got {
+			t.Errorf("parameter mismatch; expected `%v`, got `%v`", g.want, got)
+		}
+	}
+}
+
+func TestParamDecl(t *testing.T) {
+	params := []*Param{NewParam(types.I32, ""), NewParam(types.I8Ptr, "")}
+	f := NewFunction("f", types.Void, params...)
+	if want, got := "declare void @f(i32, i8*)", f.Def(); want != got {
+		t.Errorf("function declaration mismatch; expected `%v`, got `%v`", want, got)
+	}
+	// Unnamed parameters are assigned local IDs by AssignIDs.
+	entry := NewBlock("")
+	entry.NewRet(nil)
+	f.Blocks = []*BasicBlock{entry}
+	if err := f.AssignIDs(); err != nil {
+		t.Fatal(err)
+	}
+	for i, param := range params {
+		if want, got := fmt.Sprintf("%%%d", i), param.Ident(); want != got {
+			t.Errorf("parameter %d identifier mismatch; expected `%v`, got `%v`", i, want, got)
+		}
+	}
+}
+
+func TestParamValidate(t *testing.T) {
+	pair := types.NewStruct(types.I32, types.I32)
+	p := &Param{Typ: types.NewPointer(pair), LocalName: "p", Attrs: []enum.ParamAttribute{ByVal{Typ: pair}}}
+	if err := p.Validate(); err != nil {
+		t.Errorf("unexpected error for `%v`; %v", p.Def(), err)
+	}
+	p = &Param{Typ: types.I64, LocalName: "p", Attrs: []enum.ParamAttribute{ByVal{Typ: pair}}}
+	if err := p.Validate(); err == nil {
+		t.Errorf("expected error for byval attribute on non-pointer parameter, got nil")
+	}
+}
@@ -0,0 +1,98 @@
+package ir
+
+import (
+	"github.com/llir/l/internal/enc"
+	"github.com/llir/l/ir/value"
+	"github.com/pkg/errors"
+)
+
+// Inline inlines the callee of the given call instruction of the function,
+// replacing the call instruction by the instructions of the callee (with
+// function arguments substituted for parameters), and replacing all uses of
+// the result of the call instruction by the returned value.
+//
+// Only callees with a single basic block terminated by a ret terminator and
+// without alloca instructions are supported; an error is returned for any
+// other callee. The inlined instructions are unnamed, to prevent name
+// collisions with local values of the function. Local IDs of the function
+// (e.g. "%3") are cleared, as they are invalidated by the inlined instructions;
+// use AssignIDs to renumber the function.
+func (f *Function) Inline(call *InstCall) error {
+	callee, ok := call.Callee.(*Function)
+	if !ok {
+		return errors.Errorf("unsupported callee %v of type %T; expected *ir.Function", call.Callee.Ident(), call.Callee)
+	}
+	if len(callee.Blocks) != 1 {
+		return errors.Errorf("unsupported inlining of function %v with %d basic blocks; expected single basic block", enc.Global(callee.GlobalName), len(callee.Blocks))
+	}
+	if _, ok := callee.Blocks[0].Term.(*TermRet); !ok {
+		return errors.Errorf("unsupported inlining of function %v with terminator %T; expected *ir.TermRet", enc.Global(callee.GlobalName), callee.Blocks[0].Term)
+	}
+	for _, inst := range callee.Blocks[0].Insts {
+		if _, ok := inst.(*InstAlloca); ok {
+			return errors.Errorf("unsupported inlining of function %v with alloca instruction", enc.Global(callee.GlobalName))
+		}
+	}
+	if len(call.Args) != len(callee.Params) {
+		return errors.Errorf("invalid number of arguments in call to function %v; expected %d, got %d", enc.Global(callee.GlobalName), len(callee.Params), len(call.Args))
+	}
+	// Locate call instruction.
+	var block *BasicBlock
+	pos := -1
+loop:
+	for _, b := range f.Blocks {
+		for i, inst := range b.Insts {
+			if inst == call {
+				block, pos = b, i
+				break loop
+			}
+		}
+	}
+	if block == nil {
+		return errors.Errorf("unable to locate call instruction %v in function %v", call.Def(), enc.Global(f.GlobalName))
+	}
+	// Clone callee and substitute arguments for parameters.
+	clone := callee.Clone()
+	for i, param := range clone.Params {
+		clone.ReplaceAll(param, call.Args[i])
+	}
+	body := clone.Blocks[0]
+	for _, inst := range body.Insts {
+		if n, ok := inst.(interface{ SetName(string) }); ok {
+			n.SetName("")
+		}
+	}
+	insts := make([]Instruction, 0, len(block.Insts)-1+len(body.Insts))
+	insts = append(insts, block.Insts[:pos]...)
+	insts = append(insts, body.Insts...)
+	insts = append(insts, block.Insts[pos+1:]...)
+	block.Insts = insts
+	if ret := body.Term.(*TermRet); ret.X != nil {
+		f.ReplaceAll(call, ret.X)
+	}
+	clearLocalIDs(f)
+	return nil
+}
+
+// ### [ Helper functions ] ####################################################
+
+// clearLocalIDs clears the local IDs of the parameters, basic blocks,
+// instructions and terminators of the given function, leaving other local
+// names unchanged.
+func clearLocalIDs(f *Function) {
+	clear := func(v interface{}) {
+		if n, ok := v.(value.Named); ok && isLocalID(n.Name()) {
+			n.SetName("")
+		}
+	}
+	for _, param := range f.Params {
+		clear(param)
+	}
+	for _, block := range f.Blocks {
+		clear(block)
+		for _, inst := range block.Insts {
+			clear(inst)
+		}
+		clear(block.Term)
+	}
+}
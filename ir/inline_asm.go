@@ -0,0 +1,69 @@
+package ir
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/llir/l/internal/enc"
+	"github.com/llir/l/ir/enum"
+	"github.com/llir/l/ir/types"
+)
+
+// === [ Inline assembler expressions ] ========================================
+
+// InlineAsm is an inline assembler expression, which may be used as the callee
+// of call instructions (e.g. "asm sideeffect "nop", """).
+type InlineAsm struct {
+	// Pointer type to the function signature of the inline assembler
+	// expression.
+	Typ *types.PointerType
+	// Assembly instructions.
+	AsmString string
+	// Constraints.
+	Constraints string
+
+	// extra.
+
+	// (optional) Side effect.
+	SideEffect bool
+	// (optional) Stack alignment.
+	AlignStack bool
+	// (optional) Assembler dialect; AT&T if not present.
+	Dialect enum.AsmDialect
+}
+
+// NewInlineAsm returns a new inline assembler expression based on the given
+// function signature, assembly instructions and constraints.
+func NewInlineAsm(sig *types.FuncType, asm, constraints string) *InlineAsm {
+	return &InlineAsm{Typ: types.NewPointer(sig), AsmString: asm, Constraints: constraints}
+}
+
+// String returns the LLVM syntax representation of the inline assembler
+// expression as a type-value pair.
+func (asm *InlineAsm) String() string {
+	return fmt.Sprintf("%v %v", asm.Type(), asm.Ident())
+}
+
+// Type returns the type of the inline assembler expression.
+func (asm *InlineAsm) Type() types.Type {
+	return asm.Typ
+}
+
+// Ident returns the identifier associated with the inline assembler
+// expression.
+func (asm *InlineAsm) Ident() string {
+	// "asm" OptSideEffect OptAlignStack OptIntelDialect StringLit "," StringLit
+	buf := &strings.Builder{}
+	buf.WriteString("asm")
+	if asm.SideEffect {
+		buf.WriteString(" sideeffect")
+	}
+	if asm.AlignStack {
+		buf.WriteString(" alignstack")
+	}
+	if asm.Dialect != enum.AsmDialectATT {
+		fmt.Fprintf(buf, " %v", asm.Dialect)
+	}
+	fmt.Fprintf(buf, " %v, %v", enc.Quote([]byte(asm.AsmString)), enc.Quote([]byte(asm.Constraints)))
+	return buf.String()
+}
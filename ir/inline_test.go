@@ -0,0 +1,173 @@
+package ir
+
+import (
+	"testing"
+
+	"github.com/llir/l/ir/types"
+)
+
+func TestFunctionInline(t *testing.T) {
+	// define i32 @add(i32 %x, i32 %y) {
+	// entry:
+	// 	%z = add i32 %x, %y
+	// 	ret i32 %z
+	// }
+	x := NewParam(types.I32, "x")
+	y := NewParam(types.I32, "y")
+	add := NewFunction("add", types.I32, x, y)
+	body := NewBlock("entry")
+	z := body.NewAdd(x, y)
+	z.SetName("z")
+	body.NewRet(z)
+	add.Blocks = []*BasicBlock{body}
+	addDef := add.Def()
+
+	a := NewParam(types.I32, "a")
+	f := NewFunction("f", types.I32, a)
+	entry := NewBlock("entry")
+	call := entry.NewCall(add, a, NewInt(types.I32, 2))
+	call.SetName("sum")
+	double := entry.NewMul(call, NewInt(types.I32, 2))
+	double.SetName("double")
+	entry.NewRet(double)
+	f.Blocks = []*BasicBlock{entry}
+	if err := f.Inline(call); err != nil {
+		t.Fatalf("unable to inline call; %v", err)
+	}
+	if err := f.AssignIDs(); err != nil {
+		t.Fatal(err)
+	}
+	want := `define i32 @f(i32 %a) {
+entry:
+  %0 = add i32 %a, 2
+  %double = mul i32 %0, 2
+  ret i32 %double
+}`
+	if got := f.Def(); want != got {
+		t.Errorf("function mismatch; expected `%v`, got `%v`", want, got)
+	}
+	// The callee is unchanged.
+	if got := add.Def(); addDef != got {
+		t.Errorf("callee changed; expected `%v`, got `%v`", addDef, got)
+	}
+}
+
+func TestFunctionInlineDebugValue(t *testing.T) {
+	// Arguments of debug intrinsics wrapped as metadata refer to the inlined
+	// values.
+	m := &Module{}
+	x := NewParam(types.I32, "x")
+	inc := m.NewFunc("inc", types.I32, x)
+	body := NewBlock("entry")
+	variable := NewMDTuple(MDString("y"))
+	body.Insts = append(body.Insts, m.NewDbgValue(x, variable, NewDIExpression()))
+	y := body.NewAdd(x, NewInt(types.I32, 1))
+	y.SetName("y")
+	body.Insts = append(body.Insts, m.NewDbgValue(y, variable, NewDIExpression()))
+	body.NewRet(y)
+	inc.Blocks = []*BasicBlock{body}
+
+	a := NewParam(types.I32, "a")
+	f := m.NewFunc("f", types.I32, a)
+	entry := NewBlock("entry")
+	call := entry.NewCall(inc, a)
+	entry.NewRet(call)
+	f.Blocks = []*BasicBlock{entry}
+	m.AssignMetadataIDs()
+	incDef := inc.Def()
+	if err := f.Inline(call); err != nil {
+		t.Fatalf("unable to inline call; %v", err)
+	}
+	if err := f.AssignIDs(); err != nil {
+		t.Fatal(err)
+	}
+	want := `define i32 @f(i32 %a) {
+entry:
+  call void @llvm.dbg.value(metadata i32 %a, metadata !0, metadata !DIExpression())
+  %0 = add i32 %a, 1
+  call void @llvm.dbg.value(metadata i32 %0, metadata !0, metadata !DIExpression())
+  ret i32 %0
+}`
+	if got := f.Def(); want != got {
+		t.Errorf("function mismatch; expected `%v`, got `%v`", want, got)
+	}
+	// The callee is unchanged.
+	if got := inc.Def(); incDef != got {
+		t.Errorf("callee changed; expected `%v`, got `%v`", incDef, got)
+	}
+}
+
+func TestFunctionInlineNumbered(t *testing.T) {
+	// define i32 @inc(i32 %x) {
+	// 	%1 = add i32 %x, 1
+	// 	%2 = mul i32 %1, 2
+	// 	ret i32 %2
+	// }
+	x := NewParam(types.I32, "x")
+	inc := NewFunction("inc", types.I32, x)
+	body := NewBlock("")
+	y := body.NewAdd(x, NewInt(types.I32, 1))
+	body.NewRet(body.NewMul(y, NewInt(types.I32, 2)))
+	inc.Blocks = []*BasicBlock{body}
+	if err := inc.AssignIDs(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Caller with local IDs assigned before inlining.
+	f := NewFunction("f", types.I32, NewParam(types.I32, "a"))
+	entry := NewBlock("")
+	call := entry.NewCall(inc, f.Params[0])
+	sum := entry.NewAdd(call, call)
+	entry.NewRet(entry.NewSub(sum, NewInt(types.I32, 1)))
+	f.Blocks = []*BasicBlock{entry}
+	if err := f.AssignIDs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Inline(call); err != nil {
+		t.Fatalf("unable to inline call; %v", err)
+	}
+	if err := f.AssignIDs(); err != nil {
+		t.Fatal(err)
+	}
+	want := `define i32 @f(i32 %a) {
+  %1 = add i32 %a, 1
+  %2 = mul i32 %1, 2
+  %3 = add i32 %2, %2
+  %4 = sub i32 %3, 1
+  ret i32 %4
+}`
+	if got := f.Def(); want != got {
+		t.Errorf("function mismatch; expected `%v`, got `%v`", want, got)
+	}
+}
+
+func TestFunctionInlineUnsupported(t *testing.T) {
+	// Multi-block callee.
+	g := NewFunction("g", types.Void)
+	entry := NewBlock("entry")
+	exit := NewBlock("exit")
+	entry.NewBr(exit)
+	exit.NewRet(nil)
+	g.Blocks = []*BasicBlock{entry, exit}
+	// Callee with alloca.
+	h := NewFunction("h", types.Void)
+	body := NewBlock("entry")
+	body.NewAlloca(types.I32)
+	body.NewRet(nil)
+	h.Blocks = []*BasicBlock{body}
+
+	f := NewFunction("f", types.Void)
+	block := NewBlock("entry")
+	callG := block.NewCall(g)
+	callH := block.NewCall(h)
+	block.NewRet(nil)
+	f.Blocks = []*BasicBlock{block}
+	for _, call := range []*InstCall{callG, callH} {
+		if err := f.Inline(call); err == nil {
+			t.Errorf("expected error for inlining of `%v`, got nil", call.Def())
+		}
+	}
+	if len(block.Insts) != 2 {
+		t.Errorf("number of instructions mismatch; expected 2, got %d", len(block.Insts))
+	}
+}
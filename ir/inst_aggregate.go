@@ -7,6 +7,7 @@ import (
 	"github.com/llir/l/internal/enc"
 	"github.com/llir/l/ir/types"
 	"github.com/llir/l/ir/value"
+	"github.com/pkg/errors"
 )
 
 // --- [ Aggregate instructions ] ----------------------------------------------
@@ -74,12 +75,29 @@ func (inst *InstExtractValue) Def() string {
 	for _, index := range inst.Indices {
 		fmt.Fprintf(buf, ", %v", index)
 	}
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstExtractValue) Operands() []*value.Value {
+	return []*value.Value{&inst.X}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstExtractValue) DebugID() string {
+	return debugID("extractvalue", inst)
+}
+
+// Validate reports an error if the extractvalue instruction is invalid; e.g. if
+// an element index is out of range of the aggregate type at that level.
+func (inst *InstExtractValue) Validate() error {
+	return validateAggregateIndices(inst.X.Type(), inst.Indices)
+}
+
 // ~~~ [ insertvalue ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstInsertValue is an LLVM IR insertvalue instruction.
@@ -145,12 +163,29 @@ func (inst *InstInsertValue) Def() string {
 	for _, index := range inst.Indices {
 		fmt.Fprintf(buf, ", %v", index)
 	}
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstInsertValue) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Elem}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstInsertValue) DebugID() string {
+	return debugID("insertvalue", inst)
+}
+
+// Validate reports an error if the insertvalue instruction is invalid; e.g. if
+// an element index is out of range of the aggregate type at that level.
+func (inst *InstInsertValue) Validate() error {
+	return validateAggregateIndices(inst.X.Type(), inst.Indices)
+}
+
 // ### [ Helper functions ] ####################################################
 
 // aggregateElemType returns the element type at the position in the aggregate
@@ -169,3 +204,30 @@ func aggregateElemType(t types.Type, indices []int64) types.Type {
 		panic(fmt.Errorf("support for aggregate type %T not yet implemented", t))
 	}
 }
+
+// validateAggregateIndices reports an error if any of the given indices is out
+// of range of the aggregate type at its level.
+//
+// Note, the indices of extractvalue and insertvalue are always constant, as
+// opposed to the indices of getelementptr, which may be dynamic for arrays.
+func validateAggregateIndices(t types.Type, indices []int64) error {
+	if len(indices) == 0 {
+		return errors.New("invalid number of indices; expected at least one index")
+	}
+	for _, index := range indices {
+		var n int64
+		switch tt := t.(type) {
+		case *types.ArrayType:
+			n = tt.Len
+		case *types.StructType:
+			n = int64(len(tt.Fields))
+		default:
+			return errors.Errorf("invalid aggregate type; expected *types.ArrayType or *types.StructType, got %T", t)
+		}
+		if index < 0 || index >= n {
+			return errors.Errorf("invalid element index of aggregate type %v; expected 0 <= index < %d, got %d", t, n, index)
+		}
+		t = aggregateElemType(t, []int64{index})
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package ir
+
+import (
+	"testing"
+
+	"github.com/llir/l/ir/types"
+)
+
+func TestExtractValueValidate(t *testing.T) {
+	// {i32, [2 x i8]}
+	typ := types.NewStruct(types.I32, types.NewArray(2, types.I8))
+	x := NewParam(typ, "x")
+	golden := []struct {
+		in      *InstExtractValue
+		wantErr bool
+	}{
+		{in: NewExtractValue(x, 0), wantErr: false},
+		{in: NewExtractValue(x, 1, 1), wantErr: false},
+		// Out of bounds struct field index.
+		{in: NewExtractValue(x, 2), wantErr: true},
+		// Out of bounds array element index.
+		{in: NewExtractValue(x, 1, 2), wantErr: true},
+		{in: NewExtractValue(x, 1, -1), wantErr: true},
+		// Index into non-aggregate type.
+		{in: NewExtractValue(x, 0, 0), wantErr: true},
+		// Missing index.
+		{in: NewExtractValue(x), wantErr: true},
+	}
+	for _, g := range golden {
+		err := g.in.Validate()
+		if g.wantErr != (err != nil) {
+			t.Errorf("validation mismatch of `%v`; expected error %v, got %v", g.in.Def(), g.wantErr, err)
+		}
+	}
+}
+
+func TestInsertValueValidate(t *testing.T) {
+	// [2 x {i32, i32}]
+	typ := types.NewArray(2, types.NewStruct(types.I32, types.I32))
+	x := NewParam(typ, "x")
+	elem := NewInt(types.I32, 1)
+	golden := []struct {
+		in      *InstInsertValue
+		wantErr bool
+	}{
+		{in: NewInsertValue(x, elem, 1, 1), wantErr: false},
+		{in: NewInsertValue(x, elem, 2, 0), wantErr: true},
+		{in: NewInsertValue(x, elem, 0, 2), wantErr: true},
+	}
+	for _, g := range golden {
+		err := g.in.Validate()
+		if g.wantErr != (err != nil) {
+			t.Errorf("validation mismatch of `%v`; expected error %v, got %v", g.in.Def(), g.wantErr, err)
+		}
+	}
+}
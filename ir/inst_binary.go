@@ -75,12 +75,23 @@ func (inst *InstAdd) Def() string {
 		fmt.Fprintf(buf, " %v", flag)
 	}
 	fmt.Fprintf(buf, " %v, %v", inst.X, inst.Y.Ident())
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstAdd) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstAdd) DebugID() string {
+	return debugID("add", inst)
+}
+
 // ~~~ [ fadd ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstFAdd is an LLVM IR fadd instruction.
@@ -144,12 +155,23 @@ func (inst *InstFAdd) Def() string {
 		fmt.Fprintf(buf, " %v", flag)
 	}
 	fmt.Fprintf(buf, " %v, %v", inst.X, inst.Y.Ident())
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstFAdd) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstFAdd) DebugID() string {
+	return debugID("fadd", inst)
+}
+
 // ~~~ [ sub ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstSub is an LLVM IR sub instruction.
@@ -213,12 +235,23 @@ func (inst *InstSub) Def() string {
 		fmt.Fprintf(buf, " %v", flag)
 	}
 	fmt.Fprintf(buf, " %v, %v", inst.X, inst.Y.Ident())
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstSub) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstSub) DebugID() string {
+	return debugID("sub", inst)
+}
+
 // ~~~ [ fsub ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstFSub is an LLVM IR fsub instruction.
@@ -282,12 +315,23 @@ func (inst *InstFSub) Def() string {
 		fmt.Fprintf(buf, " %v", flag)
 	}
 	fmt.Fprintf(buf, " %v, %v", inst.X, inst.Y.Ident())
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstFSub) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstFSub) DebugID() string {
+	return debugID("fsub", inst)
+}
+
 // ~~~ [ mul ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstMul is an LLVM IR mul instruction.
@@ -351,12 +395,23 @@ func (inst *InstMul) Def() string {
 		fmt.Fprintf(buf, " %v", flag)
 	}
 	fmt.Fprintf(buf, " %v, %v", inst.X, inst.Y.Ident())
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstMul) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstMul) DebugID() string {
+	return debugID("mul", inst)
+}
+
 // ~~~ [ fmul ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstFMul is an LLVM IR fmul instruction.
@@ -420,12 +475,23 @@ func (inst *InstFMul) Def() string {
 		fmt.Fprintf(buf, " %v", flag)
 	}
 	fmt.Fprintf(buf, " %v, %v", inst.X, inst.Y.Ident())
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstFMul) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstFMul) DebugID() string {
+	return debugID("fmul", inst)
+}
+
 // ~~~ [ udiv ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstUDiv is an LLVM IR udiv instruction.
@@ -489,12 +555,23 @@ func (inst *InstUDiv) Def() string {
 		buf.WriteString(" exact")
 	}
 	fmt.Fprintf(buf, " %v, %v", inst.X, inst.Y.Ident())
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstUDiv) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstUDiv) DebugID() string {
+	return debugID("udiv", inst)
+}
+
 // ~~~ [ sdiv ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstSDiv is an LLVM IR sdiv instruction.
@@ -558,12 +635,23 @@ func (inst *InstSDiv) Def() string {
 		buf.WriteString(" exact")
 	}
 	fmt.Fprintf(buf, " %v, %v", inst.X, inst.Y.Ident())
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstSDiv) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstSDiv) DebugID() string {
+	return debugID("sdiv", inst)
+}
+
 // ~~~ [ fdiv ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstFDiv is an LLVM IR fdiv instruction.
@@ -627,12 +715,23 @@ func (inst *InstFDiv) Def() string {
 		fmt.Fprintf(buf, " %v", flag)
 	}
 	fmt.Fprintf(buf, " %v, %v", inst.X, inst.Y.Ident())
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstFDiv) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstFDiv) DebugID() string {
+	return debugID("fdiv", inst)
+}
+
 // ~~~ [ urem ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstURem is an LLVM IR urem instruction.
@@ -690,12 +789,23 @@ func (inst *InstURem) Def() string {
 	// "urem" Type Value "," Value OptCommaSepMetadataAttachmentList
 	buf := &strings.Builder{}
 	fmt.Fprintf(buf, "urem %v, %v", inst.X, inst.Y.Ident())
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstURem) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstURem) DebugID() string {
+	return debugID("urem", inst)
+}
+
 // ~~~ [ srem ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstSRem is an LLVM IR srem instruction.
@@ -753,12 +863,23 @@ func (inst *InstSRem) Def() string {
 	// "srem" Type Value "," Value OptCommaSepMetadataAttachmentList
 	buf := &strings.Builder{}
 	fmt.Fprintf(buf, "srem %v, %v", inst.X, inst.Y.Ident())
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstSRem) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstSRem) DebugID() string {
+	return debugID("srem", inst)
+}
+
 // ~~~ [ frem ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstFRem is an LLVM IR frem instruction.
@@ -822,8 +943,19 @@ func (inst *InstFRem) Def() string {
 		fmt.Fprintf(buf, " %v", flag)
 	}
 	fmt.Fprintf(buf, " %v, %v", inst.X, inst.Y.Ident())
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
+
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstFRem) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstFRem) DebugID() string {
+	return debugID("frem", inst)
+}
@@ -75,12 +75,23 @@ func (inst *InstShl) Def() string {
 		fmt.Fprintf(buf, " %v", flag)
 	}
 	fmt.Fprintf(buf, " %v, %v", inst.X, inst.Y.Ident())
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstShl) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstShl) DebugID() string {
+	return debugID("shl", inst)
+}
+
 // ~~~ [ lshr ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstLShr is an LLVM IR lshr instruction.
@@ -144,12 +155,23 @@ func (inst *InstLShr) Def() string {
 		buf.WriteString(" exact")
 	}
 	fmt.Fprintf(buf, " %v, %v", inst.X, inst.Y.Ident())
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstLShr) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstLShr) DebugID() string {
+	return debugID("lshr", inst)
+}
+
 // ~~~ [ ashr ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstAShr is an LLVM IR ashr instruction.
@@ -213,12 +235,23 @@ func (inst *InstAShr) Def() string {
 		buf.WriteString(" exact")
 	}
 	fmt.Fprintf(buf, " %v, %v", inst.X, inst.Y.Ident())
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstAShr) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstAShr) DebugID() string {
+	return debugID("ashr", inst)
+}
+
 // ~~~ [ and ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstAnd is an LLVM IR and instruction.
@@ -276,12 +309,23 @@ func (inst *InstAnd) Def() string {
 	// "and" Type Value "," Value OptCommaSepMetadataAttachmentList
 	buf := &strings.Builder{}
 	fmt.Fprintf(buf, "and %v, %v", inst.X, inst.Y.Ident())
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstAnd) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstAnd) DebugID() string {
+	return debugID("and", inst)
+}
+
 // ~~~ [ or ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstOr is an LLVM IR or instruction.
@@ -339,12 +383,23 @@ func (inst *InstOr) Def() string {
 	// "or" Type Value "," Value OptCommaSepMetadataAttachmentList
 	buf := &strings.Builder{}
 	fmt.Fprintf(buf, "or %v, %v", inst.X, inst.Y.Ident())
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstOr) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstOr) DebugID() string {
+	return debugID("or", inst)
+}
+
 // ~~~ [ xor ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstXor is an LLVM IR xor instruction.
@@ -402,8 +457,19 @@ func (inst *InstXor) Def() string {
 	// "xor" Type Value "," Value OptCommaSepMetadataAttachmentList
 	buf := &strings.Builder{}
 	fmt.Fprintf(buf, "xor %v, %v", inst.X, inst.Y.Ident())
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
+
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstXor) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstXor) DebugID() string {
+	return debugID("xor", inst)
+}
@@ -65,12 +65,23 @@ func (inst *InstTrunc) Def() string {
 	// "trunc" Type Value "to" Type OptCommaSepMetadataAttachmentList
 	buf := &strings.Builder{}
 	fmt.Fprintf(buf, "trunc %v to %v", inst.From, inst.To)
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstTrunc) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstTrunc) DebugID() string {
+	return debugID("trunc", inst)
+}
+
 // ~~~ [ zext ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstZExt is an LLVM IR zext instruction.
@@ -125,12 +136,23 @@ func (inst *InstZExt) Def() string {
 	// "zext" Type Value "to" Type OptCommaSepMetadataAttachmentList
 	buf := &strings.Builder{}
 	fmt.Fprintf(buf, "zext %v to %v", inst.From, inst.To)
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstZExt) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstZExt) DebugID() string {
+	return debugID("zext", inst)
+}
+
 // ~~~ [ sext ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstSExt is an LLVM IR sext instruction.
@@ -185,12 +207,23 @@ func (inst *InstSExt) Def() string {
 	// "sext" Type Value "to" Type OptCommaSepMetadataAttachmentList
 	buf := &strings.Builder{}
 	fmt.Fprintf(buf, "sext %v to %v", inst.From, inst.To)
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstSExt) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstSExt) DebugID() string {
+	return debugID("sext", inst)
+}
+
 // ~~~ [ fptrunc ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstFPTrunc is an LLVM IR fptrunc instruction.
@@ -245,12 +278,23 @@ func (inst *InstFPTrunc) Def() string {
 	// "fptrunc" Type Value "to" Type OptCommaSepMetadataAttachmentList
 	buf := &strings.Builder{}
 	fmt.Fprintf(buf, "fptrunc %v to %v", inst.From, inst.To)
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstFPTrunc) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstFPTrunc) DebugID() string {
+	return debugID("fptrunc", inst)
+}
+
 // ~~~ [ fpext ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstFPExt is an LLVM IR fpext instruction.
@@ -305,12 +349,23 @@ func (inst *InstFPExt) Def() string {
 	// "fpext" Type Value "to" Type OptCommaSepMetadataAttachmentList
 	buf := &strings.Builder{}
 	fmt.Fprintf(buf, "fpext %v to %v", inst.From, inst.To)
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstFPExt) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstFPExt) DebugID() string {
+	return debugID("fpext", inst)
+}
+
 // ~~~ [ fptoui ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstFPToUI is an LLVM IR fptoui instruction.
@@ -365,12 +420,23 @@ func (inst *InstFPToUI) Def() string {
 	// "fptoui" Type Value "to" Type OptCommaSepMetadataAttachmentList
 	buf := &strings.Builder{}
 	fmt.Fprintf(buf, "fptoui %v to %v", inst.From, inst.To)
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstFPToUI) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstFPToUI) DebugID() string {
+	return debugID("fptoui", inst)
+}
+
 // ~~~ [ fptosi ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstFPToSI is an LLVM IR fptosi instruction.
@@ -425,12 +491,23 @@ func (inst *InstFPToSI) Def() string {
 	// "fptosi" Type Value "to" Type OptCommaSepMetadataAttachmentList
 	buf := &strings.Builder{}
 	fmt.Fprintf(buf, "fptosi %v to %v", inst.From, inst.To)
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstFPToSI) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstFPToSI) DebugID() string {
+	return debugID("fptosi", inst)
+}
+
 // ~~~ [ uitofp ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstUIToFP is an LLVM IR uitofp instruction.
@@ -485,12 +562,23 @@ func (inst *InstUIToFP) Def() string {
 	// "uitofp" Type Value "to" Type OptCommaSepMetadataAttachmentList
 	buf := &strings.Builder{}
 	fmt.Fprintf(buf, "uitofp %v to %v", inst.From, inst.To)
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstUIToFP) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstUIToFP) DebugID() string {
+	return debugID("uitofp", inst)
+}
+
 // ~~~ [ sitofp ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstSIToFP is an LLVM IR sitofp instruction.
@@ -545,12 +633,23 @@ func (inst *InstSIToFP) Def() string {
 	// "sitofp" Type Value "to" Type OptCommaSepMetadataAttachmentList
 	buf := &strings.Builder{}
 	fmt.Fprintf(buf, "sitofp %v to %v", inst.From, inst.To)
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstSIToFP) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstSIToFP) DebugID() string {
+	return debugID("sitofp", inst)
+}
+
 // ~~~ [ ptrtoint ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstPtrToInt is an LLVM IR ptrtoint instruction.
@@ -605,12 +704,36 @@ func (inst *InstPtrToInt) Def() string {
 	// "ptrtoint" Type Value "to" Type OptCommaSepMetadataAttachmentList
 	buf := &strings.Builder{}
 	fmt.Fprintf(buf, "ptrtoint %v to %v", inst.From, inst.To)
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstPtrToInt) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstPtrToInt) DebugID() string {
+	return debugID("ptrtoint", inst)
+}
+
+// Validate reports an error if the ptrtoint instruction is invalid; i.e. if the
+// source value cannot be converted to the target type.
+func (inst *InstPtrToInt) Validate() error {
+	return validateCast("ptrtoint", inst.From.Type(), inst.To)
+}
+
+// ValidateSize reports an error if the integer type of the ptrtoint instruction
+// does not match the pointer size of the source address space, as specified by
+// the given data layout. Such conversions are legal, but often unintended.
+func (inst *InstPtrToInt) ValidateSize(dl *DataLayout) error {
+	return validatePtrIntSize("ptrtoint", inst.From.Type(), inst.To, dl)
+}
+
 // ~~~ [ inttoptr ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstIntToPtr is an LLVM IR inttoptr instruction.
@@ -665,12 +788,36 @@ func (inst *InstIntToPtr) Def() string {
 	// "inttoptr" Type Value "to" Type OptCommaSepMetadataAttachmentList
 	buf := &strings.Builder{}
 	fmt.Fprintf(buf, "inttoptr %v to %v", inst.From, inst.To)
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstIntToPtr) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstIntToPtr) DebugID() string {
+	return debugID("inttoptr", inst)
+}
+
+// Validate reports an error if the inttoptr instruction is invalid; i.e. if the
+// source value cannot be converted to the target type.
+func (inst *InstIntToPtr) Validate() error {
+	return validateCast("inttoptr", inst.From.Type(), inst.To)
+}
+
+// ValidateSize reports an error if the integer type of the inttoptr instruction
+// does not match the pointer size of the target address space, as specified by
+// the given data layout. Such conversions are legal, but often unintended.
+func (inst *InstIntToPtr) ValidateSize(dl *DataLayout) error {
+	return validatePtrIntSize("inttoptr", inst.From.Type(), inst.To, dl)
+}
+
 // ~~~ [ bitcast ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstBitCast is an LLVM IR bitcast instruction.
@@ -725,12 +872,23 @@ func (inst *InstBitCast) Def() string {
 	// "bitcast" Type Value "to" Type OptCommaSepMetadataAttachmentList
 	buf := &strings.Builder{}
 	fmt.Fprintf(buf, "bitcast %v to %v", inst.From, inst.To)
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstBitCast) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstBitCast) DebugID() string {
+	return debugID("bitcast", inst)
+}
+
 // ~~~ [ addrspacecast ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstAddrSpaceCast is an LLVM IR addrspacecast instruction.
@@ -785,8 +943,19 @@ func (inst *InstAddrSpaceCast) Def() string {
 	// "addrspacecast" Type Value "to" Type OptCommaSepMetadataAttachmentList
 	buf := &strings.Builder{}
 	fmt.Fprintf(buf, "addrspacecast %v to %v", inst.From, inst.To)
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
+
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstAddrSpaceCast) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstAddrSpaceCast) DebugID() string {
+	return debugID("addrspacecast", inst)
+}
@@ -0,0 +1,58 @@
+package ir
+
+import (
+	"testing"
+
+	"github.com/llir/l/ir/types"
+)
+
+func TestPtrIntSizeValidate(t *testing.T) {
+	dl, err := NewDataLayout("e-p1:32:32-i64:64")
+	if err != nil {
+		t.Fatalf("unable to parse data layout; %v", err)
+	}
+	p := NewParam(types.I8Ptr, "p")
+	p1 := NewParam(&types.PointerType{ElemType: types.I8, AddrSpace: 1}, "p1")
+	x := NewParam(types.I64, "x")
+	golden := []struct {
+		in interface {
+			Validate() error
+			ValidateSize(dl *DataLayout) error
+		}
+		valid bool
+	}{
+		// Matching pointer size.
+		{in: NewPtrToInt(p, types.I64), valid: true},
+		{in: NewIntToPtr(x, types.I8Ptr), valid: true},
+		{in: NewPtrToInt(p1, types.I32), valid: true},
+		// Mismatched pointer size.
+		{in: NewPtrToInt(p, types.I32), valid: false},
+		{in: NewPtrToInt(p1, types.I64), valid: false},
+		{in: NewIntToPtr(x, p1.Type()), valid: false},
+	}
+	for _, g := range golden {
+		// Mismatched pointer sizes are legal.
+		if err := g.in.Validate(); err != nil {
+			t.Errorf("unexpected error for `%v`; %v", g.in, err)
+		}
+		err := g.in.ValidateSize(dl)
+		if g.valid && err != nil {
+			t.Errorf("unexpected error for `%v`; %v", g.in, err)
+		}
+		if !g.valid && err == nil {
+			t.Errorf("expected error for `%v`, got nil", g.in)
+		}
+		// The size check is suppressed without a data layout.
+		if err := g.in.ValidateSize(nil); err != nil {
+			t.Errorf("unexpected error for `%v` without data layout; %v", g.in, err)
+		}
+	}
+	// Constant expressions.
+	g := NewGlobalDef("g", NewInt(types.I8, 0))
+	if err := NewPtrToIntExpr(g, types.I64).ValidateSize(dl); err != nil {
+		t.Errorf("unexpected error; %v", err)
+	}
+	if err := NewPtrToIntExpr(g, types.I32).ValidateSize(dl); err == nil {
+		t.Errorf("expected error for ptrtoint to i32, got nil")
+	}
+}
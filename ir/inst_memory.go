@@ -8,6 +8,7 @@ import (
 	"github.com/llir/l/ir/enum"
 	"github.com/llir/l/ir/types"
 	"github.com/llir/l/ir/value"
+	"github.com/pkg/errors"
 )
 
 // --- [ Memory instructions ] -------------------------------------------------
@@ -91,15 +92,47 @@ func (inst *InstAlloca) Def() string {
 	if inst.Alignment != 0 {
 		fmt.Fprintf(buf, ", align %v", inst.Alignment)
 	}
-	if inst.Typ.AddrSpace != 0 {
-		fmt.Fprintf(buf, ", %v", inst.Typ.AddrSpace)
+	if t := inst.Type().(*types.PointerType); t.AddrSpace != 0 {
+		fmt.Fprintf(buf, ", %v", t.AddrSpace)
 	}
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstAlloca) Operands() []*value.Value {
+	if inst.NElems != nil {
+		return []*value.Value{&inst.NElems}
+	}
+	return nil
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstAlloca) DebugID() string {
+	return debugID("alloca", inst)
+}
+
+// Validate reports an error if the alloca instruction is invalid; e.g. if the
+// element type is unsized, or if both inalloca and swifterror are set.
+func (inst *InstAlloca) Validate() error {
+	if !types.IsSized(inst.ElemType) {
+		return errors.Errorf("invalid alloca instruction %v; expected sized element type, got %v", inst.Ident(), inst.ElemType)
+	}
+	if inst.InAlloca && inst.SwiftError {
+		return errors.Errorf("invalid alloca instruction %v; inalloca and swifterror may not be used together", inst.Ident())
+	}
+	return nil
+}
+
+// NaturalAlignment returns the ABI alignment in bytes of the allocated element
+// type, as specified by the given data layout.
+func (inst *InstAlloca) NaturalAlignment(dl *DataLayout) int {
+	return dl.ABIAlignment(inst.ElemType)
+}
+
 // ~~~ [ load ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstLoad is an LLVM IR load instruction.
@@ -113,7 +146,7 @@ type InstLoad struct {
 
 	// Type of result produced by the instruction.
 	Typ types.Type
-	// (optional) Atomic.
+	// (optional) Atomic; implied by a non-zero atomic memory ordering.
 	Atomic bool
 	// (optional) Volatile.
 	Volatile bool
@@ -172,13 +205,13 @@ func (inst *InstLoad) Def() string {
 	// "load" OptVolatile Type "," Type Value OptCommaAlignment OptCommaSepMetadataAttachmentList
 	buf := &strings.Builder{}
 	buf.WriteString("load")
-	if inst.Atomic {
+	if inst.Atomic || inst.Ordering != enum.AtomicOrderingNone {
 		buf.WriteString(" atomic")
 	}
 	if inst.Volatile {
 		buf.WriteString(" volatile")
 	}
-	fmt.Fprintf(buf, " %v, %v", inst.Typ, inst.Src)
+	fmt.Fprintf(buf, " %v, %v", inst.Type(), inst.Src)
 	if len(inst.SyncScope) > 0 {
 		fmt.Fprintf(buf, " syncscope(%v)", enc.Quote([]byte(inst.SyncScope)))
 	}
@@ -188,12 +221,65 @@ func (inst *InstLoad) Def() string {
 	if inst.Alignment != 0 {
 		fmt.Fprintf(buf, ", align %v", inst.Alignment)
 	}
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstLoad) Operands() []*value.Value {
+	return []*value.Value{&inst.Src}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstLoad) DebugID() string {
+	return debugID("load", inst)
+}
+
+// Validate reports an error if the load instruction is invalid; e.g. if the
+// result type is unsized, or if an atomic load has release or acq_rel memory
+// ordering, or lacks an explicit alignment.
+func (inst *InstLoad) Validate() error {
+	if !types.IsSized(inst.Type()) {
+		return errors.Errorf("invalid load instruction %v; expected sized result type, got %v", inst.Ident(), inst.Type())
+	}
+	return validateAtomic("load", inst.Atomic, inst.Ordering, inst.Alignment, enum.AtomicOrderingRelease, enum.AtomicOrderingAcqRel)
+}
+
+// NaturalAlignment returns the ABI alignment in bytes of the loaded type, as
+// specified by the given data layout.
+func (inst *InstLoad) NaturalAlignment(dl *DataLayout) int {
+	return dl.ABIAlignment(inst.Type())
+}
+
+// SetNonNull attaches !nonnull metadata to the load instruction (e.g. "!nonnull
+// !{}"), asserting that the loaded pointer is never null. An error is returned
+// if the result type is not a pointer type.
+func (inst *InstLoad) SetNonNull() error {
+	if !types.IsPointer(inst.Type()) {
+		return errors.Errorf("invalid !nonnull metadata of load instruction %v; expected pointer result type, got %v", inst.Ident(), inst.Type())
+	}
+	setMetadataAttachment(&inst.Metadata, "nonnull", NewMDTuple())
+	return nil
+}
+
+// SetAlignMetadata attaches !align metadata to the load instruction (e.g.
+// "!align !{i64 8}"), asserting that the loaded pointer is aligned to the given
+// number of bytes. An error is returned if the result type is not a pointer
+// type, or if the alignment is not a power of two.
+func (inst *InstLoad) SetAlignMetadata(align uint64) error {
+	if !types.IsPointer(inst.Type()) {
+		return errors.Errorf("invalid !align metadata of load instruction %v; expected pointer result type, got %v", inst.Ident(), inst.Type())
+	}
+	if align == 0 || align&(align-1) != 0 {
+		return errors.Errorf("invalid !align metadata of load instruction %v; expected power of two alignment, got %d", inst.Ident(), align)
+	}
+	setMetadataAttachment(&inst.Metadata, "align", NewMDTuple(NewMDValue(NewInt(types.I64, int64(align)))))
+	return nil
+}
+
 // ~~~ [ store ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstStore is an LLVM IR store instruction.
@@ -205,7 +291,7 @@ type InstStore struct {
 
 	// extra.
 
-	// (optional) Atomic.
+	// (optional) Atomic; implied by a non-zero atomic memory ordering.
 	Atomic bool
 	// (optional) Volatile.
 	Volatile bool
@@ -231,7 +317,7 @@ func (inst *InstStore) Def() string {
 	// "store" OptVolatile Type Value "," Type Value OptCommaAlignment OptCommaSepMetadataAttachmentList
 	buf := &strings.Builder{}
 	buf.WriteString("store")
-	if inst.Atomic {
+	if inst.Atomic || inst.Ordering != enum.AtomicOrderingNone {
 		buf.WriteString(" atomic")
 	}
 	if inst.Volatile {
@@ -247,12 +333,39 @@ func (inst *InstStore) Def() string {
 	if inst.Alignment != 0 {
 		fmt.Fprintf(buf, ", align %v", inst.Alignment)
 	}
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstStore) Operands() []*value.Value {
+	return []*value.Value{&inst.Src, &inst.Dst}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstStore) DebugID() string {
+	return debugID("store", inst)
+}
+
+// Validate reports an error if the store instruction is invalid; e.g. if the
+// stored type is unsized, or if an atomic store has acquire or acq_rel memory
+// ordering, or lacks an explicit alignment.
+func (inst *InstStore) Validate() error {
+	if !types.IsSized(inst.Src.Type()) {
+		return errors.Errorf("invalid store instruction; expected sized value type, got %v", inst.Src.Type())
+	}
+	return validateAtomic("store", inst.Atomic, inst.Ordering, inst.Alignment, enum.AtomicOrderingAcquire, enum.AtomicOrderingAcqRel)
+}
+
+// NaturalAlignment returns the ABI alignment in bytes of the stored type, as
+// specified by the given data layout.
+func (inst *InstStore) NaturalAlignment(dl *DataLayout) int {
+	return dl.ABIAlignment(inst.Src.Type())
+}
+
 // ~~~ [ fence ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstFence is an LLVM IR fence instruction.
@@ -282,12 +395,24 @@ func (inst *InstFence) Def() string {
 		fmt.Fprintf(buf, " syncscope(%v)", enc.Quote([]byte(inst.SyncScope)))
 	}
 	fmt.Fprintf(buf, " %v", inst.Ordering)
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstFence) Operands() []*value.Value {
+	// no operands.
+	return nil
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstFence) DebugID() string {
+	return debugID("fence", inst)
+}
+
 // ~~~ [ cmpxchg ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstCmpXchg is an LLVM IR cmpxchg instruction.
@@ -316,6 +441,8 @@ type InstCmpXchg struct {
 	Volatile bool
 	// (optional) Sync scope; empty if not present.
 	SyncScope string
+	// (optional) Alignment; zero if not present.
+	Alignment int
 	// (optional) Metadata.
 	Metadata []MetadataAttachment
 }
@@ -360,7 +487,7 @@ func (inst *InstCmpXchg) SetName(name string) {
 
 // Def returns the LLVM syntax representation of the instruction.
 func (inst *InstCmpXchg) Def() string {
-	// "cmpxchg" OptWeak OptVolatile Type Value "," Type Value "," Type Value OptSyncScope AtomicOrdering AtomicOrdering OptCommaSepMetadataAttachmentList
+	// "cmpxchg" OptWeak OptVolatile Type Value "," Type Value "," Type Value OptSyncScope AtomicOrdering AtomicOrdering OptCommaAlignment OptCommaSepMetadataAttachmentList
 	buf := &strings.Builder{}
 	buf.WriteString("cmpxchg")
 	if inst.Weak {
@@ -375,12 +502,26 @@ func (inst *InstCmpXchg) Def() string {
 	}
 	fmt.Fprintf(buf, " %v", inst.Success)
 	fmt.Fprintf(buf, " %v", inst.Failure)
-	for _, md := range inst.Metadata {
+	if inst.Alignment != 0 {
+		fmt.Fprintf(buf, ", align %v", inst.Alignment)
+	}
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstCmpXchg) Operands() []*value.Value {
+	return []*value.Value{&inst.Ptr, &inst.Cmp, &inst.New}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstCmpXchg) DebugID() string {
+	return debugID("cmpxchg", inst)
+}
+
 // ~~~ [ atomicrmw ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstAtomicRMW is an LLVM IR atomicrmw instruction.
@@ -404,6 +545,8 @@ type InstAtomicRMW struct {
 	Volatile bool
 	// (optional) Sync scope; empty if not present.
 	SyncScope string
+	// (optional) Alignment; zero if not present.
+	Alignment int
 	// (optional) Metadata.
 	Metadata []MetadataAttachment
 }
@@ -450,7 +593,7 @@ func (inst *InstAtomicRMW) SetName(name string) {
 
 // Def returns the LLVM syntax representation of the instruction.
 func (inst *InstAtomicRMW) Def() string {
-	// "atomicrmw" OptVolatile BinOp Type Value "," Type Value OptSyncScope AtomicOrdering OptCommaSepMetadataAttachmentList
+	// "atomicrmw" OptVolatile BinOp Type Value "," Type Value OptSyncScope AtomicOrdering OptCommaAlignment OptCommaSepMetadataAttachmentList
 	buf := &strings.Builder{}
 	buf.WriteString("atomicrmw")
 	if inst.Volatile {
@@ -461,12 +604,50 @@ func (inst *InstAtomicRMW) Def() string {
 		fmt.Fprintf(buf, " syncscope(%v)", enc.Quote([]byte(inst.SyncScope)))
 	}
 	fmt.Fprintf(buf, " %v", inst.Ordering)
-	for _, md := range inst.Metadata {
+	if inst.Alignment != 0 {
+		fmt.Fprintf(buf, ", align %v", inst.Alignment)
+	}
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstAtomicRMW) Operands() []*value.Value {
+	return []*value.Value{&inst.Dst, &inst.X}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstAtomicRMW) DebugID() string {
+	return debugID("atomicrmw", inst)
+}
+
+// Validate reports an error if the operand type of the atomicrmw instruction is
+// invalid for its atomic operation; xchg requires an integer, floating-point or
+// pointer operand, fadd, fsub, fmax and fmin require a floating-point operand,
+// and all other atomic operations (including uinc_wrap, udec_wrap, usub_cond
+// and usub_sat) require an integer operand.
+func (inst *InstAtomicRMW) Validate() error {
+	t := inst.X.Type()
+	switch inst.Op {
+	case enum.AtomicOpXChg:
+		if !types.IsInt(t) && !types.IsFloat(t) && !types.IsPointer(t) {
+			return errors.Errorf("invalid operand type of atomicrmw %v; expected integer, floating-point or pointer type, got %v", inst.Op, t)
+		}
+	case enum.AtomicOpFAdd, enum.AtomicOpFSub, enum.AtomicOpFMax, enum.AtomicOpFMin:
+		if !types.IsFloat(t) {
+			return errors.Errorf("invalid operand type of atomicrmw %v; expected floating-point type, got %v", inst.Op, t)
+		}
+	default:
+		if !types.IsInt(t) {
+			return errors.Errorf("invalid operand type of atomicrmw %v; expected integer type, got %v", inst.Op, t)
+		}
+	}
+	return nil
+}
+
 // ~~~ [ getelementptr ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstGetElementPtr is an LLVM IR getelementptr instruction.
@@ -482,10 +663,16 @@ type InstGetElementPtr struct {
 
 	// extra.
 
-	// Type of result produced by the instruction.
+	// Type of result produced by the instruction; inferred from the source
+	// element type and indices if nil. May be set explicitly (e.g. for opaque
+	// pointer sources), in which case Type returns Typ directly.
 	Typ types.Type
-	// (optional) In-bounds.
+	// (optional) In-bounds; implies NUSW.
 	InBounds bool
+	// (optional) No unsigned signed wrap.
+	NUSW bool
+	// (optional) No unsigned wrap.
+	NUW bool
 	// (optional) Metadata.
 	Metadata []MetadataAttachment
 }
@@ -496,6 +683,29 @@ func NewGetElementPtr(elemType types.Type, src value.Value, indices ...value.Val
 	return &InstGetElementPtr{ElemType: elemType, Src: src, Indices: indices}
 }
 
+// NewStructGEP returns a new getelementptr instruction computing the address
+// of the given struct field, based on the given source address (pointer to
+// struct) and field index. The instruction is of the conventional form
+//
+//    getelementptr inbounds %T, %T* %src, i32 0, i32 fieldIndex
+func NewStructGEP(src value.Value, fieldIndex int) *InstGetElementPtr {
+	t, ok := src.Type().(*types.PointerType)
+	if !ok {
+		panic(fmt.Errorf("invalid source type; expected *types.PointerType, got %T", src.Type()))
+	}
+	structType, ok := t.ElemType.(*types.StructType)
+	if !ok {
+		panic(fmt.Errorf("invalid source element type; expected *types.StructType, got %T", t.ElemType))
+	}
+	if fieldIndex < 0 || fieldIndex >= len(structType.Fields) {
+		panic(fmt.Errorf("invalid field index of struct type %v; expected 0 <= index < %d, got %d", structType, len(structType.Fields), fieldIndex))
+	}
+	indices := []value.Value{NewInt(types.I32, 0), NewInt(types.I32, int64(fieldIndex))}
+	inst := NewGetElementPtr(structType, src, indices...)
+	inst.InBounds = true
+	return inst
+}
+
 // String returns the LLVM syntax representation of the instruction as a
 // type-value pair.
 func (inst *InstGetElementPtr) String() string {
@@ -506,7 +716,7 @@ func (inst *InstGetElementPtr) String() string {
 func (inst *InstGetElementPtr) Type() types.Type {
 	// Cache type if not present.
 	if inst.Typ == nil {
-		inst.Typ = types.NewPointer(inst.ElemType)
+		inst.Typ = gepType(inst.ElemType, inst.Src.Type(), inst.Indices)
 	}
 	return inst.Typ
 }
@@ -528,18 +738,198 @@ func (inst *InstGetElementPtr) SetName(name string) {
 
 // Def returns the LLVM syntax representation of the instruction.
 func (inst *InstGetElementPtr) Def() string {
-	// "getelementptr" OptInBounds Type "," Type Value GEPIndices OptCommaSepMetadataAttachmentList
+	// "getelementptr" GEPFlags Type "," Type Value GEPIndices OptCommaSepMetadataAttachmentList
 	buf := &strings.Builder{}
 	buf.WriteString("getelementptr")
-	if inst.InBounds {
-		buf.WriteString(" inbounds")
-	}
+	buf.WriteString(gepFlagsString(inst.InBounds, inst.NUSW, inst.NUW))
 	fmt.Fprintf(buf, " %v, %v", inst.ElemType, inst.Src)
 	for _, index := range inst.Indices {
 		fmt.Fprintf(buf, ", %v", index)
 	}
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
+
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstGetElementPtr) Operands() []*value.Value {
+	ops := make([]*value.Value, 0, 1+len(inst.Indices))
+	ops = append(ops, &inst.Src)
+	for i := range inst.Indices {
+		ops = append(ops, &inst.Indices[i])
+	}
+	return ops
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstGetElementPtr) DebugID() string {
+	return debugID("getelementptr", inst)
+}
+
+// ConstantOffset returns the accumulated offset in bytes from the source
+// address of the getelementptr instruction, as specified by the given data
+// layout. The boolean return value indicates whether all indices were constant.
+func (inst *InstGetElementPtr) ConstantOffset(dl *DataLayout) (int64, bool) {
+	var offset int64
+	// The first index steps through the source address in units of the source
+	// element type.
+	e := inst.ElemType
+	for i, index := range inst.Indices {
+		idx, ok := index.(*ConstInt)
+		if !ok {
+			return 0, false
+		}
+		x := idx.X.Int64()
+		if i == 0 {
+			offset += x * dl.TypeAllocSize(e)
+			continue
+		}
+		switch t := e.(type) {
+		case *types.ArrayType:
+			e = t.ElemType
+			offset += x * dl.TypeAllocSize(e)
+		case *types.VectorType:
+			e = t.ElemType
+			offset += x * dl.TypeAllocSize(e)
+		case *types.StructType:
+			offset += dl.StructFieldOffset(t, int(x))
+			e = t.Fields[x]
+		default:
+			panic(fmt.Errorf("support for indexing into type %T not yet implemented", t))
+		}
+	}
+	return offset, true
+}
+
+// IsNoOp reports whether the getelementptr instruction is a no-op; i.e. whether
+// all indices are constant zero and the result has the type of the source
+// address. The result of no-op getelementptr instructions is the source
+// address.
+func (inst *InstGetElementPtr) IsNoOp() bool {
+	for _, index := range inst.Indices {
+		idx, ok := index.(*ConstInt)
+		if !ok || idx.X.Sign() != 0 {
+			return false
+		}
+	}
+	return inst.Type().Equal(inst.Src.Type())
+}
+
+// Validate reports an error if the getelementptr instruction is invalid; i.e.
+// if the source element type is unsized, or if a struct index is not a constant
+// within the bounds of the struct type.
+func (inst *InstGetElementPtr) Validate() error {
+	if !types.IsSized(inst.ElemType) {
+		return errors.Errorf("invalid getelementptr instruction %v; expected sized source element type, got %v", inst.Ident(), inst.ElemType)
+	}
+	if inst.Typ == nil && gepType(inst.ElemType, inst.Src.Type(), inst.Indices) == nil {
+		return errors.Errorf("invalid getelementptr instruction %v; expected constant struct indices within bounds", inst.Ident())
+	}
+	return nil
+}
+
+// ### [ Helper functions ] ####################################################
+
+// gepType returns the pointer type to the element addressed by a
+// getelementptr instruction, based on the given source element type, source
+// address type and element indices. A nil type is returned if a struct index is
+// not a constant within the bounds of the struct type.
+func gepType(elemType, srcType types.Type, indices []value.Value) types.Type {
+	var addrSpace types.AddrSpace
+	if t, ok := srcType.(*types.PointerType); ok {
+		addrSpace = t.AddrSpace
+		if t.ElemType == nil {
+			// The result of getelementptr on an opaque pointer is an opaque
+			// pointer.
+			return &types.PointerType{AddrSpace: addrSpace}
+		}
+	}
+	// The first index steps through the source address and does not change the
+	// type being indexed.
+	e := elemType
+	for i := 1; i < len(indices); i++ {
+		switch t := e.(type) {
+		case *types.ArrayType:
+			e = t.ElemType
+		case *types.VectorType:
+			e = t.ElemType
+		case *types.StructType:
+			index, ok := indices[i].(*ConstInt)
+			if !ok || !index.X.IsInt64() || index.X.Int64() < 0 || index.X.Int64() >= int64(len(t.Fields)) {
+				return nil
+			}
+			e = t.Fields[index.X.Int64()]
+		default:
+			panic(fmt.Errorf("support for indexing into type %T not yet implemented", t))
+		}
+	}
+	return &types.PointerType{ElemType: e, AddrSpace: addrSpace}
+}
+
+// gepFlagsString returns the LLVM syntax representation of the given
+// getelementptr flags, with a leading space if non-empty. The nusw flag is
+// omitted if implied by inbounds.
+func gepFlagsString(inBounds, nusw, nuw bool) string {
+	buf := &strings.Builder{}
+	switch {
+	case inBounds:
+		buf.WriteString(" inbounds")
+	case nusw:
+		buf.WriteString(" nusw")
+	}
+	if nuw {
+		buf.WriteString(" nuw")
+	}
+	return buf.String()
+}
+
+// ParseGEPFlags parses the given space-separated getelementptr flags (e.g.
+// "inbounds nuw"), as present between the getelementptr keyword and the
+// element type. An error is reported for unknown or repeated flags.
+func ParseGEPFlags(s string) (inBounds, nusw, nuw bool, err error) {
+	seen := make(map[string]bool)
+	for _, flag := range strings.Fields(s) {
+		if seen[flag] {
+			return false, false, false, errors.Errorf("invalid getelementptr flags %q; repeated flag %q", s, flag)
+		}
+		seen[flag] = true
+		switch flag {
+		case "inbounds":
+			inBounds = true
+		case "nusw":
+			nusw = true
+		case "nuw":
+			nuw = true
+		default:
+			return false, false, false, errors.Errorf("invalid getelementptr flag %q; expected inbounds, nusw or nuw", flag)
+		}
+	}
+	return inBounds, nusw, nuw, nil
+}
+
+// validateAtomic reports an error if the atomic memory ordering and alignment
+// of the given atomic or non-atomic load or store instruction are invalid. The
+// invalid parameter specifies the memory orderings not permitted by the
+// instruction.
+//
+// Atomic loads and stores require a memory ordering and an explicit alignment
+// (as also required for unordered accesses).
+func validateAtomic(opcode string, atomic bool, ordering enum.AtomicOrdering, alignment int, invalid ...enum.AtomicOrdering) error {
+	if !atomic && ordering == enum.AtomicOrderingNone {
+		return nil
+	}
+	if ordering == enum.AtomicOrderingNone {
+		return errors.Errorf("invalid atomic %s; missing atomic memory ordering", opcode)
+	}
+	for _, o := range invalid {
+		if ordering == o {
+			return errors.Errorf("invalid atomic memory ordering of atomic %s; %v not permitted", opcode, ordering)
+		}
+	}
+	if alignment == 0 {
+		return errors.Errorf("invalid atomic %s with %v memory ordering; missing explicit alignment", opcode, ordering)
+	}
+	return nil
+}
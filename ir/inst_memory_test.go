@@ -0,0 +1,354 @@
+package ir
+
+import (
+	"testing"
+
+	"github.com/llir/l/ir/enum"
+	"github.com/llir/l/ir/types"
+	"github.com/llir/l/ir/value"
+)
+
+func TestAllocaDef(t *testing.T) {
+	golden := []struct {
+		in   *InstAlloca
+		want string
+	}{
+		// Plain alloca.
+		{
+			in:   &InstAlloca{ElemType: types.I32},
+			want: "alloca i32",
+		},
+		// inalloca.
+		{
+			in:   &InstAlloca{ElemType: types.I32, InAlloca: true},
+			want: "alloca inalloca i32",
+		},
+		// swifterror.
+		{
+			in:   &InstAlloca{ElemType: types.I8Ptr, SwiftError: true},
+			want: "alloca swifterror i8*",
+		},
+		// Number of elements and alignment.
+		{
+			in:   &InstAlloca{ElemType: types.I32, NElems: NewInt(types.I32, 4), Alignment: 16},
+			want: "alloca i32, i32 4, align 16",
+		},
+		// inalloca with number of elements and alignment.
+		{
+			in:   &InstAlloca{ElemType: types.I8, InAlloca: true, NElems: NewInt(types.I64, 8), Alignment: 4},
+			want: "alloca inalloca i8, i64 8, align 4",
+		},
+	}
+	for _, g := range golden {
+		got := g.in.Def()
+		if g.want != got {
+			t.Errorf("alloca mismatch; expected `%v`, got `%v`", g.want, got)
+		}
+	}
+}
+
+func TestAllocaValidate(t *testing.T) {
+	valid := []*InstAlloca{
+		{ElemType: types.I32},
+		{ElemType: types.I32, InAlloca: true},
+		{ElemType: types.I8Ptr, SwiftError: true},
+	}
+	for _, inst := range valid {
+		if err := inst.Validate(); err != nil {
+			t.Errorf("unexpected error for `%v`; %v", inst.Def(), err)
+		}
+	}
+	inst := &InstAlloca{LocalName: "x", ElemType: types.I32, InAlloca: true, SwiftError: true}
+	if err := inst.Validate(); err == nil {
+		t.Errorf("expected error for alloca with both inalloca and swifterror, got nil")
+	}
+	// Unsized element type.
+	inst = &InstAlloca{LocalName: "y", ElemType: types.Void}
+	if err := inst.Validate(); err == nil {
+		t.Errorf("expected error for alloca of unsized type, got nil")
+	}
+}
+
+func TestLoadStoreValidate(t *testing.T) {
+	p := NewParam(types.NewPointer(types.I32), "p")
+	x := NewParam(types.I32, "x")
+	valid := []interface {
+		Def() string
+		Validate() error
+	}{
+		// load i32, i32* %p
+		&InstLoad{Src: p},
+		// load atomic i32, i32* %p acquire, align 4
+		&InstLoad{Src: p, Ordering: enum.AtomicOrderingAcquire, Alignment: 4},
+		// load atomic i32, i32* %p unordered, align 4
+		&InstLoad{Src: p, Ordering: enum.AtomicOrderingUnordered, Alignment: 4},
+		// store atomic i32 %x, i32* %p release, align 4
+		&InstStore{Src: x, Dst: p, Ordering: enum.AtomicOrderingRelease, Alignment: 4},
+	}
+	for _, inst := range valid {
+		if err := inst.Validate(); err != nil {
+			t.Errorf("unexpected error for `%v`; %v", inst.Def(), err)
+		}
+	}
+	invalid := []interface {
+		Def() string
+		Validate() error
+	}{
+		// load atomic i32, i32* %p release, align 4
+		&InstLoad{Src: p, Ordering: enum.AtomicOrderingRelease, Alignment: 4},
+		// load atomic i32, i32* %p acq_rel, align 4
+		&InstLoad{Src: p, Ordering: enum.AtomicOrderingAcqRel, Alignment: 4},
+		// load atomic i32, i32* %p unordered
+		&InstLoad{Src: p, Ordering: enum.AtomicOrderingUnordered},
+		// load atomic i32, i32* %p
+		&InstLoad{Src: p, Atomic: true, Alignment: 4},
+		// store atomic i32 %x, i32* %p acquire, align 4
+		&InstStore{Src: x, Dst: p, Ordering: enum.AtomicOrderingAcquire, Alignment: 4},
+		// store atomic i32 %x, i32* %p acq_rel, align 4
+		&InstStore{Src: x, Dst: p, Ordering: enum.AtomicOrderingAcqRel, Alignment: 4},
+	}
+	for _, inst := range invalid {
+		if err := inst.Validate(); err == nil {
+			t.Errorf("expected error for `%v`, got nil", inst.Def())
+		}
+	}
+}
+
+func TestStructGEP(t *testing.T) {
+	foo := &types.StructType{Alias: "foo", Fields: []types.Type{types.I32, types.I8Ptr}}
+	src := NewParam(types.NewPointer(foo), "p")
+	block := NewBlock("entry")
+	inst := block.NewStructGEP(src, 1)
+	if len(block.Insts) != 1 || block.Insts[0] != inst {
+		t.Fatalf("struct GEP not appended to basic block")
+	}
+	if !inst.InBounds {
+		t.Errorf("expected struct GEP to be inbounds")
+	}
+	want := "getelementptr inbounds %foo, %foo* %p, i32 0, i32 1"
+	if got := inst.Def(); want != got {
+		t.Errorf("struct GEP mismatch; expected `%v`, got `%v`", want, got)
+	}
+	if want, got := "i8**", inst.Type().String(); want != got {
+		t.Errorf("struct GEP type mismatch; expected `%v`, got `%v`", want, got)
+	}
+}
+
+func TestStructGEPInvalidField(t *testing.T) {
+	defer func() {
+		if e := recover(); e == nil {
+			t.Errorf("expected panic for out-of-range field index")
+		}
+	}()
+	foo := &types.StructType{Alias: "foo", Fields: []types.Type{types.I32}}
+	src := NewParam(types.NewPointer(foo), "p")
+	NewStructGEP(src, 1)
+}
+
+func TestGetElementPtrType(t *testing.T) {
+	arr := types.NewArray(4, types.I16)
+	src := NewParam(types.NewPointer(arr), "p")
+	// Inferred from source element type and indices.
+	inst := NewGetElementPtr(arr, src, NewInt(types.I64, 0), NewInt(types.I64, 2))
+	if want, got := "i16*", inst.Type().String(); want != got {
+		t.Errorf("getelementptr type mismatch; expected `%v`, got `%v`", want, got)
+	}
+	// Explicit result type override.
+	inst = NewGetElementPtr(arr, src, NewInt(types.I64, 0), NewInt(types.I64, 2))
+	inst.Typ = types.I8Ptr
+	if want, got := "i8*", inst.Type().String(); want != got {
+		t.Errorf("getelementptr type mismatch; expected `%v`, got `%v`", want, got)
+	}
+	// Out-of-range struct index.
+	foo := types.NewStruct(types.I32, types.I8)
+	inst = NewGetElementPtr(foo, NewParam(types.NewPointer(foo), "q"), NewInt(types.I64, 0), NewInt(types.I32, 2))
+	if got := inst.Type(); got != nil {
+		t.Errorf("getelementptr type mismatch; expected nil, got `%v`", got)
+	}
+	if err := inst.Validate(); err == nil {
+		t.Errorf("expected error for out-of-range struct index, got nil")
+	}
+}
+
+func TestGetElementPtrFlags(t *testing.T) {
+	p := NewParam(types.I8Ptr, "p")
+	null := NewNull(types.I8Ptr)
+	golden := []struct {
+		flags string
+		// getelementptr instruction.
+		want string
+		// getelementptr constant expression.
+		wantExpr string
+	}{
+		{flags: "", want: "getelementptr i8, i8* %p, i64 1", wantExpr: "getelementptr (i8, i8* null, i64 1)"},
+		{flags: "inbounds", want: "getelementptr inbounds i8, i8* %p, i64 1", wantExpr: "getelementptr inbounds (i8, i8* null, i64 1)"},
+		{flags: "nusw", want: "getelementptr nusw i8, i8* %p, i64 1", wantExpr: "getelementptr nusw (i8, i8* null, i64 1)"},
+		{flags: "nuw", want: "getelementptr nuw i8, i8* %p, i64 1", wantExpr: "getelementptr nuw (i8, i8* null, i64 1)"},
+		{flags: "inbounds nuw", want: "getelementptr inbounds nuw i8, i8* %p, i64 1", wantExpr: "getelementptr inbounds nuw (i8, i8* null, i64 1)"},
+		{flags: "nusw nuw", want: "getelementptr nusw nuw i8, i8* %p, i64 1", wantExpr: "getelementptr nusw nuw (i8, i8* null, i64 1)"},
+		// inbounds implies nusw.
+		{flags: "nusw inbounds", want: "getelementptr inbounds i8, i8* %p, i64 1", wantExpr: "getelementptr inbounds (i8, i8* null, i64 1)"},
+		{flags: "nuw nusw inbounds", want: "getelementptr inbounds nuw i8, i8* %p, i64 1", wantExpr: "getelementptr inbounds nuw (i8, i8* null, i64 1)"},
+	}
+	for _, g := range golden {
+		inBounds, nusw, nuw, err := ParseGEPFlags(g.flags)
+		if err != nil {
+			t.Errorf("unable to parse getelementptr flags %q; %v", g.flags, err)
+			continue
+		}
+		inst := NewGetElementPtr(types.I8, p, NewInt(types.I64, 1))
+		inst.InBounds, inst.NUSW, inst.NUW = inBounds, nusw, nuw
+		if got := inst.Def(); g.want != got {
+			t.Errorf("getelementptr mismatch; expected `%v`, got `%v`", g.want, got)
+		}
+		expr := NewGetElementPtrExpr(types.I8, null, NewIndex(NewInt(types.I64, 1)))
+		expr.InBounds, expr.NUSW, expr.NUW = inBounds, nusw, nuw
+		if got := expr.Ident(); g.wantExpr != got {
+			t.Errorf("getelementptr expression mismatch; expected `%v`, got `%v`", g.wantExpr, got)
+		}
+	}
+	// Invalid flags.
+	for _, flags := range []string{"nuw nuw", "nsw", "inbounds exact"} {
+		if _, _, _, err := ParseGEPFlags(flags); err == nil {
+			t.Errorf("expected error for getelementptr flags %q, got nil", flags)
+		}
+	}
+}
+
+func TestGetElementPtrConstantOffset(t *testing.T) {
+	dl, err := NewDataLayout("e-m:e-i64:64-f80:128-n8:16:32:64-S128")
+	if err != nil {
+		t.Fatalf("unable to parse data layout; %v", err)
+	}
+	// %foo = type { i8, i64, [4 x i16] }
+	foo := &types.StructType{Alias: "foo", Fields: []types.Type{types.I8, types.I64, types.NewArray(4, types.I16)}}
+	src := NewParam(types.NewPointer(foo), "p")
+	// getelementptr %foo, %foo* %p, i64 1, i32 2, i64 3
+	inst := NewGetElementPtr(foo, src, NewInt(types.I64, 1), NewInt(types.I32, 2), NewInt(types.I64, 3))
+	offset, ok := inst.ConstantOffset(dl)
+	if !ok {
+		t.Fatalf("expected constant offset of `%v`", inst.Def())
+	}
+	// 24 (sizeof %foo) + 16 (offset of field 2) + 3*2 (array index).
+	if want := int64(46); want != offset {
+		t.Errorf("offset mismatch; expected %d, got %d", want, offset)
+	}
+	// Variable index.
+	i := NewParam(types.I64, "i")
+	inst = NewGetElementPtr(foo, src, NewInt(types.I64, 0), NewInt(types.I32, 2), i)
+	if _, ok := inst.ConstantOffset(dl); ok {
+		t.Errorf("expected non-constant offset of `%v`", inst.Def())
+	}
+}
+
+func TestFenceSyncScope(t *testing.T) {
+	golden := []struct {
+		in   enum.SyncScope
+		want string
+	}{
+		{in: enum.SyncScopeSystem, want: "fence seq_cst"},
+		{in: enum.SyncScopeSingleThread, want: `fence syncscope("singlethread") seq_cst`},
+		{in: enum.NewSyncScope("agent"), want: `fence syncscope("agent") seq_cst`},
+	}
+	for _, g := range golden {
+		inst := NewFence(enum.AtomicOrderingSeqCst)
+		inst.SyncScope = g.in.String()
+		if got := inst.Def(); g.want != got {
+			t.Errorf("fence mismatch; expected `%v`, got `%v`", g.want, got)
+		}
+	}
+}
+
+func TestMemoryInstDef(t *testing.T) {
+	p := NewParam(types.I32Ptr, "p")
+	x := NewParam(types.I32, "x")
+	y := NewParam(types.I32, "y")
+	golden := []struct {
+		in   Instruction
+		want string
+	}{
+		// alloca
+		{in: &InstAlloca{ElemType: types.I32, Alignment: 4}, want: "alloca i32, align 4"},
+		{in: &InstAlloca{ElemType: types.I32, Typ: &types.PointerType{ElemType: types.I32, AddrSpace: 5}}, want: "alloca i32, addrspace(5)"},
+		// load
+		{in: NewLoad(p), want: "load i32, i32* %p"},
+		{in: &InstLoad{Src: p, Volatile: true, Alignment: 4}, want: "load volatile i32, i32* %p, align 4"},
+		{in: &InstLoad{Src: p, Atomic: true, Ordering: enum.AtomicOrderingAcquire, Alignment: 4}, want: "load atomic i32, i32* %p acquire, align 4"},
+		{in: &InstLoad{Src: p, Ordering: enum.AtomicOrderingMonotonic, SyncScope: "singlethread", Alignment: 4}, want: `load atomic i32, i32* %p syncscope("singlethread") monotonic, align 4`},
+		{in: &InstLoad{Src: p, Atomic: true, Volatile: true, Ordering: enum.AtomicOrderingSeqCst, Alignment: 4}, want: "load atomic volatile i32, i32* %p seq_cst, align 4"},
+		// store
+		{in: NewStore(x, p), want: "store i32 %x, i32* %p"},
+		{in: &InstStore{Src: x, Dst: p, Volatile: true, Alignment: 4}, want: "store volatile i32 %x, i32* %p, align 4"},
+		{in: &InstStore{Src: x, Dst: p, Atomic: true, Ordering: enum.AtomicOrderingRelease, Alignment: 4}, want: "store atomic i32 %x, i32* %p release, align 4"},
+		{in: &InstStore{Src: x, Dst: p, Ordering: enum.AtomicOrderingUnordered, SyncScope: "agent", Alignment: 4}, want: `store atomic i32 %x, i32* %p syncscope("agent") unordered, align 4`},
+		// fence
+		{in: NewFence(enum.AtomicOrderingAcqRel), want: "fence acq_rel"},
+		{in: &InstFence{Ordering: enum.AtomicOrderingSeqCst, SyncScope: "singlethread"}, want: `fence syncscope("singlethread") seq_cst`},
+		// cmpxchg
+		{in: NewCmpXchg(p, x, y, enum.AtomicOrderingSeqCst, enum.AtomicOrderingMonotonic), want: "cmpxchg i32* %p, i32 %x, i32 %y seq_cst monotonic"},
+		{in: &InstCmpXchg{Ptr: p, Cmp: x, New: y, Success: enum.AtomicOrderingAcqRel, Failure: enum.AtomicOrderingAcquire, Weak: true, Volatile: true, SyncScope: "singlethread", Alignment: 4}, want: `cmpxchg weak volatile i32* %p, i32 %x, i32 %y syncscope("singlethread") acq_rel acquire, align 4`},
+		// atomicrmw
+		{in: NewAtomicRMW(enum.AtomicOpAdd, p, x, enum.AtomicOrderingSeqCst), want: "atomicrmw add i32* %p, i32 %x seq_cst"},
+		{in: &InstAtomicRMW{Op: enum.AtomicOpXChg, Dst: p, X: x, Ordering: enum.AtomicOrderingMonotonic, Volatile: true, SyncScope: "singlethread", Alignment: 4}, want: `atomicrmw volatile xchg i32* %p, i32 %x syncscope("singlethread") monotonic, align 4`},
+		// getelementptr
+		{in: NewGetElementPtr(types.I32, p, x), want: "getelementptr i32, i32* %p, i32 %x"},
+		{in: &InstGetElementPtr{ElemType: types.I32, Src: p, Indices: []value.Value{NewInt(types.I64, 1)}, InBounds: true}, want: "getelementptr inbounds i32, i32* %p, i64 1"},
+	}
+	for _, g := range golden {
+		assertIR(t, g.in, g.want)
+	}
+}
+
+func TestAtomicRMWValidate(t *testing.T) {
+	p := NewParam(types.NewPointer(types.I32), "p")
+	x := NewParam(types.I32, "x")
+	q := NewParam(types.NewPointer(types.Float), "q")
+	y := NewParam(types.Float, "y")
+	golden := []struct {
+		in    *InstAtomicRMW
+		want  string
+		valid bool
+	}{
+		// atomicrmw fmax
+		{in: NewAtomicRMW(enum.AtomicOpFMax, q, y, enum.AtomicOrderingSeqCst), want: "atomicrmw fmax float* %q, float %y seq_cst", valid: true},
+		{in: NewAtomicRMW(enum.AtomicOpFMax, p, x, enum.AtomicOrderingSeqCst), want: "atomicrmw fmax i32* %p, i32 %x seq_cst", valid: false},
+		// atomicrmw uinc_wrap
+		{in: NewAtomicRMW(enum.AtomicOpUIncWrap, p, x, enum.AtomicOrderingMonotonic), want: "atomicrmw uinc_wrap i32* %p, i32 %x monotonic", valid: true},
+		{in: NewAtomicRMW(enum.AtomicOpUIncWrap, q, y, enum.AtomicOrderingMonotonic), want: "atomicrmw uinc_wrap float* %q, float %y monotonic", valid: false},
+		// atomicrmw xchg
+		{in: NewAtomicRMW(enum.AtomicOpXChg, q, y, enum.AtomicOrderingSeqCst), want: "atomicrmw xchg float* %q, float %y seq_cst", valid: true},
+	}
+	for _, g := range golden {
+		assertIR(t, g.in, g.want)
+		err := g.in.Validate()
+		if g.valid && err != nil {
+			t.Errorf("unexpected error for `%v`; %v", g.in.Def(), err)
+		}
+		if !g.valid && err == nil {
+			t.Errorf("expected error for `%v`, got nil", g.in.Def())
+		}
+	}
+}
+
+func TestLoadNonNullAlign(t *testing.T) {
+	p := NewParam(types.NewPointer(types.I8Ptr), "p")
+	load := NewLoad(p)
+	load.SetName("x")
+	if err := load.SetNonNull(); err != nil {
+		t.Fatalf("unable to set !nonnull metadata; %v", err)
+	}
+	if err := load.SetAlignMetadata(8); err != nil {
+		t.Fatalf("unable to set !align metadata; %v", err)
+	}
+	if want, got := "%x = load i8*, i8** %p, !nonnull !{}, !align !{i64 8}", defString(load); want != got {
+		t.Errorf("load instruction mismatch; expected `%v`, got `%v`", want, got)
+	}
+	if err := load.SetAlignMetadata(3); err == nil {
+		t.Errorf("expected error for non-power of two alignment, got nil")
+	}
+	// Non-pointer result type.
+	q := NewParam(types.NewPointer(types.I32), "q")
+	if err := NewLoad(q).SetNonNull(); err == nil {
+		t.Errorf("expected error for !nonnull metadata of non-pointer load, got nil")
+	}
+}
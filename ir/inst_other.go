@@ -8,6 +8,7 @@ import (
 	"github.com/llir/l/ir/enum"
 	"github.com/llir/l/ir/types"
 	"github.com/llir/l/ir/value"
+	"github.com/pkg/errors"
 )
 
 // --- [ Other instructions ] --------------------------------------------------
@@ -51,7 +52,7 @@ func (inst *InstICmp) Type() types.Type {
 		case *types.IntType, *types.PointerType:
 			inst.Typ = types.I1
 		case *types.VectorType:
-			inst.Typ = types.NewVector(xType.Len, types.I1)
+			inst.Typ = &types.VectorType{Len: xType.Len, ElemType: types.I1, Scalable: xType.Scalable}
 		default:
 			panic(fmt.Errorf("invalid icmp operand type; expected *types.IntType, *types.PointerType or *types.VectorType, got %T", xType))
 		}
@@ -79,12 +80,23 @@ func (inst *InstICmp) Def() string {
 	// "icmp" IPred Type Value "," Value OptCommaSepMetadataAttachmentList
 	buf := &strings.Builder{}
 	fmt.Fprintf(buf, "icmp %v %v, %v", inst.Pred, inst.X, inst.Y.Ident())
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstICmp) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstICmp) DebugID() string {
+	return debugID("icmp", inst)
+}
+
 // ~~~ [ fcmp ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstFCmp is an LLVM IR fcmp instruction.
@@ -126,7 +138,7 @@ func (inst *InstFCmp) Type() types.Type {
 		case *types.FloatType:
 			inst.Typ = types.I1
 		case *types.VectorType:
-			inst.Typ = types.NewVector(xType.Len, types.I1)
+			inst.Typ = &types.VectorType{Len: xType.Len, ElemType: types.I1, Scalable: xType.Scalable}
 		default:
 			panic(fmt.Errorf("invalid fcmp operand type; expected *types.FloatType or *types.VectorType, got %T", xType))
 		}
@@ -158,12 +170,23 @@ func (inst *InstFCmp) Def() string {
 		fmt.Fprintf(buf, " %v", flag)
 	}
 	fmt.Fprintf(buf, " %v %v, %v", inst.Pred, inst.X, inst.Y.Ident())
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstFCmp) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstFCmp) DebugID() string {
+	return debugID("fcmp", inst)
+}
+
 // ~~~ [ phi ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstPhi is an LLVM IR phi instruction.
@@ -220,19 +243,34 @@ func (inst *InstPhi) SetName(name string) {
 func (inst *InstPhi) Def() string {
 	// "phi" Type IncList OptCommaSepMetadataAttachmentList
 	buf := &strings.Builder{}
-	fmt.Fprintf(buf, "phi %v ", inst.Typ)
+	fmt.Fprintf(buf, "phi %v ", inst.Type())
 	for i, inc := range inst.Incs {
 		if i != 0 {
 			buf.WriteString(", ")
 		}
 		buf.WriteString(inc.String())
 	}
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstPhi) Operands() []*value.Value {
+	ops := make([]*value.Value, 0, len(inst.Incs))
+	for _, inc := range inst.Incs {
+		ops = append(ops, &inc.X)
+	}
+	return ops
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstPhi) DebugID() string {
+	return debugID("phi", inst)
+}
+
 // ___ [ Incoming value ] ______________________________________________________
 
 // Incoming is an incoming value of a phi instruction.
@@ -314,20 +352,99 @@ func (inst *InstSelect) Def() string {
 	// "select" Type Value "," Type Value "," Type Value OptCommaSepMetadataAttachmentList
 	buf := &strings.Builder{}
 	fmt.Fprintf(buf, "select %v, %v, %v", inst.Cond, inst.X, inst.Y)
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
+		fmt.Fprintf(buf, ", %v", md)
+	}
+	return buf.String()
+}
+
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstSelect) Operands() []*value.Value {
+	return []*value.Value{&inst.Cond, &inst.X, &inst.Y}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstSelect) DebugID() string {
+	return debugID("select", inst)
+}
+
+// ~~~ [ freeze ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+
+// InstFreeze is an LLVM IR freeze instruction.
+type InstFreeze struct {
+	// Name of local variable associated with the result.
+	LocalName string
+	// Operand.
+	X value.Value
+
+	// extra.
+
+	// (optional) Metadata.
+	Metadata []MetadataAttachment
+}
+
+// NewFreeze returns a new freeze instruction based on the given operand.
+func NewFreeze(x value.Value) *InstFreeze {
+	return &InstFreeze{X: x}
+}
+
+// String returns the LLVM syntax representation of the instruction as a
+// type-value pair.
+func (inst *InstFreeze) String() string {
+	return fmt.Sprintf("%v %v", inst.Type(), inst.Ident())
+}
+
+// Type returns the type of the instruction.
+func (inst *InstFreeze) Type() types.Type {
+	return inst.X.Type()
+}
+
+// Ident returns the identifier associated with the instruction.
+func (inst *InstFreeze) Ident() string {
+	return enc.Local(inst.LocalName)
+}
+
+// Name returns the name of the instruction.
+func (inst *InstFreeze) Name() string {
+	return inst.LocalName
+}
+
+// SetName sets the name of the instruction.
+func (inst *InstFreeze) SetName(name string) {
+	inst.LocalName = name
+}
+
+// Def returns the LLVM syntax representation of the instruction.
+func (inst *InstFreeze) Def() string {
+	// "freeze" TypeValue OptCommaSepMetadataAttachmentList
+	buf := &strings.Builder{}
+	fmt.Fprintf(buf, "freeze %v", inst.X)
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstFreeze) Operands() []*value.Value {
+	return []*value.Value{&inst.X}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstFreeze) DebugID() string {
+	return debugID("freeze", inst)
+}
+
 // ~~~ [ call ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstCall is an LLVM IR call instruction.
 type InstCall struct {
 	// Name of local variable associated with the result.
 	LocalName string
-	// Callee.
-	// TODO: specify the set of underlying types of Callee.
+	// Callee; a function, a function pointer value or an inline assembler
+	// expression (*ir.InlineAsm).
 	Callee value.Value
 	// Function arguments.
 	Args []Arg
@@ -347,7 +464,8 @@ type InstCall struct {
 	ReturnAttrs []enum.ReturnAttribute
 	// (optional) Address space; zero if not present.
 	AddrSpace types.AddrSpace
-	// (optional) Function attributes.
+	// (optional) Call site function attributes (e.g. readonly), which apply in
+	// addition to the function attributes of the callee.
 	FuncAttrs []enum.FuncAttribute
 	// (optional) Operand bundles.
 	OperandBundles []enum.OperandBundle
@@ -373,13 +491,12 @@ func (inst *InstCall) String() string {
 func (inst *InstCall) Type() types.Type {
 	// Cache type if not present.
 	if inst.Typ == nil {
-		t, ok := inst.Callee.Type().(*types.PointerType)
-		if !ok {
-			panic(fmt.Errorf("invalid callee type; expected *types.PointerType, got %T", inst.Callee.Type()))
+		sig, err := calleeSig(inst.Callee, nil)
+		if err != nil {
+			panic(err)
 		}
-		sig, ok := t.ElemType.(*types.FuncType)
-		if !ok {
-			panic(fmt.Errorf("invalid callee type; expected *types.FuncType, got %T", t.ElemType))
+		if sig == nil {
+			panic(fmt.Errorf("unable to infer type of call to %s through opaque pointer; Typ must be set explicitly", inst.Callee.Ident()))
 		}
 		if sig.Variadic {
 			inst.Typ = sig
@@ -443,12 +560,178 @@ func (inst *InstCall) Def() string {
 		}
 		buf.WriteString("]")
 	}
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstCall) Operands() []*value.Value {
+	ops := make([]*value.Value, 0, 1+len(inst.Args))
+	ops = append(ops, &inst.Callee)
+	for i := range inst.Args {
+		ops = append(ops, &inst.Args[i])
+	}
+	return ops
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstCall) DebugID() string {
+	return debugID("call", inst)
+}
+
+// Validate reports an error if the arguments of the call do not match the
+// callee signature, if an argument of an immarg parameter of the callee is not
+// an integer or floating-point constant, or if fast-math flags are present on a
+// call which does not return a floating-point value.
+//
+// Arguments past the fixed parameters of the callee are only valid if the
+// callee is variadic. The arguments of indirect calls through opaque pointers
+// are not checked, as the callee signature is unknown.
+func (inst *InstCall) Validate() error {
+	sig, err := calleeSig(inst.Callee, inst.Typ)
+	if err != nil {
+		return errors.Wrapf(err, "invalid call to %s", inst.Callee.Ident())
+	}
+	if sig != nil {
+		if len(inst.Args) < len(sig.Params) || (len(inst.Args) > len(sig.Params) && !sig.Variadic) {
+			return errors.Errorf("invalid number of arguments in call to %s; expected %d, got %d", inst.Callee.Ident(), len(sig.Params), len(inst.Args))
+		}
+		for i, param := range sig.Params {
+			if arg := inst.Args[i]; !arg.Type().Equal(param) {
+				return errors.Errorf("invalid type of argument %d in call to %s; expected %v, got %v", i, inst.Callee.Ident(), param, arg.Type())
+			}
+		}
+	}
+	if callee, ok := inst.Callee.(*Function); ok {
+		for i, param := range callee.Params {
+			if !hasParamAttr(param.Attrs, enum.ParamAttrImmarg) || i >= len(inst.Args) {
+				continue
+			}
+			switch arg := inst.Args[i]; arg.(type) {
+			case *ConstInt, *ConstFloat:
+				// valid immediate argument.
+			default:
+				return errors.Errorf("invalid argument %d in call to %s; expected integer or floating-point constant for immarg parameter, got %v", i, inst.Callee.Ident(), arg)
+			}
+		}
+	}
+	if len(inst.FastMathFlags) > 0 && !isFPMathType(inst.Type()) {
+		return errors.Errorf("invalid fast-math flags on call to %s; expected floating-point return type, got %v", inst.Callee.Ident(), inst.Type())
+	}
+	return nil
+}
+
+// SetNonNull adds the nonnull return attribute to the call, asserting that the
+// returned pointer is never null (e.g. "call nonnull i8* @f()"). An error is
+// returned if the result type is not a pointer type.
+//
+// The nonnull return attribute corresponds to the !nonnull metadata of load
+// instructions (see InstLoad.SetNonNull), as LLVM only permits !nonnull
+// metadata on load instructions.
+func (inst *InstCall) SetNonNull() error {
+	if !types.IsPointer(inst.Type()) {
+		return errors.Errorf("invalid nonnull return attribute of call to %s; expected pointer return type, got %v", inst.Callee.Ident(), inst.Type())
+	}
+	for _, attr := range inst.ReturnAttrs {
+		if attr == enum.ReturnAttrNonNull {
+			return nil
+		}
+	}
+	inst.ReturnAttrs = append(inst.ReturnAttrs, enum.ReturnAttrNonNull)
+	return nil
+}
+
+// SetReturnAlign sets the align return attribute of the call, asserting that
+// the returned pointer is aligned to the given number of bytes (e.g. "call
+// align 8 i8* @f()"), replacing any existing align return attribute. An error
+// is returned if the result type is not a pointer type, or if the alignment is
+// not a power of two.
+//
+// The align return attribute corresponds to the !align metadata of load
+// instructions (see InstLoad.SetAlignMetadata), as LLVM only permits !align
+// metadata on load instructions.
+func (inst *InstCall) SetReturnAlign(align uint64) error {
+	if !types.IsPointer(inst.Type()) {
+		return errors.Errorf("invalid align return attribute of call to %s; expected pointer return type, got %v", inst.Callee.Ident(), inst.Type())
+	}
+	if align == 0 || align&(align-1) != 0 {
+		return errors.Errorf("invalid align return attribute of call to %s; expected power of two alignment, got %d", inst.Callee.Ident(), align)
+	}
+	for i, attr := range inst.ReturnAttrs {
+		if _, ok := attr.(Align); ok {
+			inst.ReturnAttrs[i] = Align(align)
+			return nil
+		}
+	}
+	inst.ReturnAttrs = append(inst.ReturnAttrs, Align(align))
+	return nil
+}
+
+// ValidateMustTail reports an error if the call is a musttail call which
+// violates the rules of musttail calls in the given basic block of the given
+// enclosing function. The call must immediately precede a ret terminator
+// (optionally separated by a bitcast of the result) which returns the result of
+// the call, and the callee must have a call compatible signature (see
+// types.FuncType.CallCompatible) and the same calling convention as the
+// enclosing function.
+func (inst *InstCall) ValidateMustTail(f *Function, block *BasicBlock) error {
+	if inst.Tail != enum.TailMustTail {
+		return nil
+	}
+	// Locate call in basic block.
+	pos := -1
+	for i, v := range block.Insts {
+		if v == inst {
+			pos = i
+			break
+		}
+	}
+	if pos == -1 {
+		return errors.Errorf("unable to locate musttail call to %s in basic block %s", inst.Callee.Ident(), block.Ident())
+	}
+	// Check that the musttail call is followed by a ret terminator, optionally
+	// separated by a bitcast of the result.
+	var result value.Value = inst
+	switch len(block.Insts) - pos {
+	case 1:
+		// musttail call is the last instruction.
+	case 2:
+		cast, ok := block.Insts[pos+1].(*InstBitCast)
+		if !ok || cast.From != value.Value(inst) {
+			return errors.Errorf("invalid musttail call to %s in basic block %s; musttail call may only be followed by a bitcast of its result before ret", inst.Callee.Ident(), block.Ident())
+		}
+		result = cast
+	default:
+		return errors.Errorf("invalid musttail call to %s in basic block %s; musttail call must immediately precede ret", inst.Callee.Ident(), block.Ident())
+	}
+	ret, ok := block.Term.(*TermRet)
+	if !ok {
+		return errors.Errorf("invalid musttail call to %s in basic block %s; expected ret terminator, got %T", inst.Callee.Ident(), block.Ident(), block.Term)
+	}
+	if ret.X == nil {
+		if !inst.Type().Equal(types.Void) {
+			return errors.Errorf("invalid musttail call to %s in basic block %s; ret must return the result of the musttail call", inst.Callee.Ident(), block.Ident())
+		}
+	} else if ret.X != result {
+		return errors.Errorf("invalid musttail call to %s in basic block %s; ret must return the result of the musttail call", inst.Callee.Ident(), block.Ident())
+	}
+	// Check that the callee signature matches the enclosing function.
+	sig, err := calleeSig(inst.Callee, inst.Typ)
+	if err != nil {
+		return errors.Wrapf(err, "invalid musttail call to %s", inst.Callee.Ident())
+	}
+	if sig != nil && !sig.CallCompatible(f.Sig) {
+		return errors.Errorf("invalid musttail call to %s in function %s; callee signature %v does not match caller signature %v", inst.Callee.Ident(), f.Ident(), sig, f.Sig)
+	}
+	if inst.CallingConv != f.CallingConv {
+		return errors.Errorf("invalid musttail call to %s in function %s; calling convention %v does not match caller calling convention %v", inst.Callee.Ident(), f.Ident(), inst.CallingConv, f.CallingConv)
+	}
+	return nil
+}
+
 // ~~~ [ va_arg ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstVAArg is an LLVM IR va_arg instruction.
@@ -503,12 +786,23 @@ func (inst *InstVAArg) Def() string {
 	// "va_arg" Type Value "," Type OptCommaSepMetadataAttachmentList
 	buf := &strings.Builder{}
 	fmt.Fprintf(buf, "va_arg %v, %v", inst.ArgList, inst.ArgType)
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstVAArg) Operands() []*value.Value {
+	return []*value.Value{&inst.ArgList}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstVAArg) DebugID() string {
+	return debugID("va_arg", inst)
+}
+
 // ~~~ [ landingpad ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstLandingPad is an LLVM IR landingpad instruction.
@@ -572,12 +866,24 @@ func (inst *InstLandingPad) Def() string {
 	for _, clause := range inst.Clauses {
 		fmt.Fprintf(buf, " %v", clause)
 	}
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstLandingPad) Operands() []*value.Value {
+	// no operands.
+	return nil
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstLandingPad) DebugID() string {
+	return debugID("landingpad", inst)
+}
+
 // ~~~ [ catchpad ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstCatchPad is an LLVM IR catchpad instruction.
@@ -639,12 +945,27 @@ func (inst *InstCatchPad) Def() string {
 		buf.WriteString(arg.String())
 	}
 	buf.WriteString("]")
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstCatchPad) Operands() []*value.Value {
+	ops := make([]*value.Value, 0, len(inst.Args))
+	for i := range inst.Args {
+		ops = append(ops, &inst.Args[i])
+	}
+	return ops
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstCatchPad) DebugID() string {
+	return debugID("catchpad", inst)
+}
+
 // ~~~ [ cleanuppad ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstCleanupPad is an LLVM IR cleanuppad instruction.
@@ -706,8 +1027,39 @@ func (inst *InstCleanupPad) Def() string {
 		buf.WriteString(arg.String())
 	}
 	buf.WriteString("]")
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
+
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstCleanupPad) Operands() []*value.Value {
+	ops := make([]*value.Value, 0, len(inst.Args))
+	for i := range inst.Args {
+		ops = append(ops, &inst.Args[i])
+	}
+	return ops
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstCleanupPad) DebugID() string {
+	return debugID("cleanuppad", inst)
+}
+
+// ### [ Helper functions ] ####################################################
+
+// isFPMathType reports whether the given type supports fast-math flags; i.e.
+// floating-point types, and vectors and arrays of floating-point types.
+func isFPMathType(t types.Type) bool {
+	switch t := t.(type) {
+	case *types.FloatType:
+		return true
+	case *types.VectorType:
+		return isFPMathType(t.ElemType)
+	case *types.ArrayType:
+		return isFPMathType(t.ElemType)
+	}
+	return false
+}
@@ -0,0 +1,240 @@
+package ir
+
+import (
+	"testing"
+
+	"github.com/llir/l/ir/enum"
+	"github.com/llir/l/ir/types"
+)
+
+func TestCallFastMathFlags(t *testing.T) {
+	fma := &Function{
+		GlobalName: "llvm.fma.f32",
+		Sig:        types.NewFunc(types.Float, types.Float, types.Float, types.Float),
+	}
+	a := NewParam(types.Float, "a")
+	b := NewParam(types.Float, "b")
+	c := NewParam(types.Float, "c")
+	entry := NewBlock("entry")
+	inst := entry.NewCall(fma, a, b, c)
+	inst.FastMathFlags = []enum.FastMathFlag{enum.FastMathFlagFast}
+	want := "call fast float @llvm.fma.f32(float %a, float %b, float %c)"
+	if got := inst.Def(); want != got {
+		t.Errorf("call mismatch; expected `%v`, got `%v`", want, got)
+	}
+	if err := inst.Validate(); err != nil {
+		t.Errorf("unexpected error for `%v`; %v", inst.Def(), err)
+	}
+}
+
+func TestCallFastMathFlagsValidate(t *testing.T) {
+	f := &Function{
+		GlobalName: "f",
+		Sig:        types.NewFunc(types.I32),
+	}
+	inst := NewCall(f)
+	inst.FastMathFlags = []enum.FastMathFlag{enum.FastMathFlagNNaN}
+	if err := inst.Validate(); err == nil {
+		t.Errorf("expected error for fast-math flags on call returning i32, got nil")
+	}
+}
+
+func TestCallValidateMustTail(t *testing.T) {
+	sig := types.NewFunc(types.I32, types.I32)
+	g := &Function{GlobalName: "g", Sig: sig}
+	// Valid musttail call.
+	x := NewParam(types.I32, "x")
+	entry := NewBlock("entry")
+	call := entry.NewCall(g, x)
+	call.Tail = enum.TailMustTail
+	entry.NewRet(call)
+	f := &Function{GlobalName: "f", Sig: sig, Params: []*Param{x}, Blocks: []*BasicBlock{entry}}
+	if err := call.ValidateMustTail(f, entry); err != nil {
+		t.Errorf("unexpected error for valid musttail call; %v", err)
+	}
+	// Misplaced musttail call.
+	y := NewParam(types.I32, "y")
+	entry = NewBlock("entry")
+	call = entry.NewCall(g, y)
+	call.Tail = enum.TailMustTail
+	sum := entry.NewAdd(call, y)
+	entry.NewRet(sum)
+	f = &Function{GlobalName: "f", Sig: sig, Params: []*Param{y}, Blocks: []*BasicBlock{entry}}
+	if err := call.ValidateMustTail(f, entry); err == nil {
+		t.Errorf("expected error for musttail call not preceding ret, got nil")
+	}
+	// Mismatched signature.
+	h := &Function{GlobalName: "h", Sig: types.NewFunc(types.I32, types.I64)}
+	z := NewParam(types.I32, "z")
+	entry = NewBlock("entry")
+	call = entry.NewCall(h, NewInt(types.I64, 0))
+	call.Tail = enum.TailMustTail
+	entry.NewRet(call)
+	f = &Function{GlobalName: "f", Sig: sig, Params: []*Param{z}, Blocks: []*BasicBlock{entry}}
+	if err := call.ValidateMustTail(f, entry); err == nil {
+		t.Errorf("expected error for musttail call with mismatched signature, got nil")
+	}
+}
+
+func TestCallValidateArgs(t *testing.T) {
+	f := &Function{
+		GlobalName: "f",
+		Sig:        types.NewFunc(types.Void, types.I32, types.I8Ptr),
+	}
+	printf := &Function{
+		GlobalName: "printf",
+		Sig:        &types.FuncType{RetType: types.I32, Params: []types.Type{types.I8Ptr}, Variadic: true},
+	}
+	s := NewParam(types.I8Ptr, "s")
+	golden := []struct {
+		in      *InstCall
+		wantErr string
+	}{
+		// Valid call.
+		{in: NewCall(f, NewInt(types.I32, 1), s)},
+		// Valid variadic call.
+		{in: NewCall(printf, s, NewInt(types.I32, 1), NewInt(types.I64, 2))},
+		// Arity mismatch.
+		{
+			in:      NewCall(f, NewInt(types.I32, 1)),
+			wantErr: "invalid number of arguments in call to @f; expected 2, got 1",
+		},
+		// Extra arguments to non-variadic callee.
+		{
+			in:      NewCall(f, NewInt(types.I32, 1), s, s),
+			wantErr: "invalid number of arguments in call to @f; expected 2, got 3",
+		},
+		// Type mismatch.
+		{
+			in:      NewCall(f, NewInt(types.I64, 1), s),
+			wantErr: "invalid type of argument 0 in call to @f; expected i32, got i64",
+		},
+		// Missing fixed argument of variadic callee.
+		{
+			in:      NewCall(printf),
+			wantErr: "invalid number of arguments in call to @printf; expected 1, got 0",
+		},
+	}
+	for _, g := range golden {
+		err := g.in.Validate()
+		var got string
+		if err != nil {
+			got = err.Error()
+		}
+		if g.wantErr != got {
+			t.Errorf("error mismatch; expected `%v`, got `%v`", g.wantErr, got)
+		}
+	}
+}
+
+func TestCallTailCallingConv(t *testing.T) {
+	f := &Function{
+		GlobalName: "f",
+		Sig:        types.NewFunc(types.I32, types.I32),
+	}
+	x := NewParam(types.I32, "x")
+	golden := []struct {
+		tail enum.Tail
+		want string
+	}{
+		{tail: enum.TailNone, want: "call fastcc i32 @f(i32 %x)"},
+		{tail: enum.TailTail, want: "tail call fastcc i32 @f(i32 %x)"},
+		{tail: enum.TailMustTail, want: "musttail call fastcc i32 @f(i32 %x)"},
+		{tail: enum.TailNoTail, want: "notail call fastcc i32 @f(i32 %x)"},
+	}
+	for _, g := range golden {
+		inst := NewCall(f, x)
+		inst.Tail = g.tail
+		inst.CallingConv = enum.CallingConvFast
+		assertIR(t, inst, g.want)
+	}
+}
+
+func TestCallInlineAsm(t *testing.T) {
+	nop := NewInlineAsm(types.NewFunc(types.Void), "nop", "")
+	nop.SideEffect = true
+	x := NewParam(types.I32, "x")
+	bswap := NewInlineAsm(types.NewFunc(types.I32, types.I32), "bswap $0", "=r,r")
+	bswap.AlignStack = true
+	bswap.Dialect = enum.AsmDialectIntel
+	golden := []struct {
+		in   *InstCall
+		want string
+	}{
+		{in: NewCall(nop), want: `call void asm sideeffect "nop", ""()`},
+		{in: NewCall(bswap, x), want: `call i32 asm alignstack inteldialect "bswap $0", "=r,r"(i32 %x)`},
+	}
+	for _, g := range golden {
+		assertIR(t, g.in, g.want)
+		if err := g.in.Validate(); err != nil {
+			t.Errorf("unexpected error for `%v`; %v", g.in.Def(), err)
+		}
+	}
+}
+
+func TestCallNonNullAlign(t *testing.T) {
+	f := NewFunction("f", types.I8Ptr)
+	call := NewCall(f)
+	call.SetName("x")
+	if err := call.SetNonNull(); err != nil {
+		t.Fatalf("unable to set nonnull return attribute; %v", err)
+	}
+	if err := call.SetReturnAlign(4); err != nil {
+		t.Fatalf("unable to set align return attribute; %v", err)
+	}
+	// Repeated calls replace existing attributes.
+	if err := call.SetNonNull(); err != nil {
+		t.Fatalf("unable to set nonnull return attribute; %v", err)
+	}
+	if err := call.SetReturnAlign(8); err != nil {
+		t.Fatalf("unable to set align return attribute; %v", err)
+	}
+	if want, got := "%x = call nonnull align 8 i8* @f()", defString(call); want != got {
+		t.Errorf("call instruction mismatch; expected `%v`, got `%v`", want, got)
+	}
+	// Non-pointer return type.
+	g := NewFunction("g", types.I32)
+	if err := NewCall(g).SetNonNull(); err == nil {
+		t.Errorf("expected error for nonnull return attribute of non-pointer call, got nil")
+	}
+}
+
+func TestCallFuncAttrs(t *testing.T) {
+	f := NewFunction("f", types.I32, NewParam(types.I32, "x"))
+	golden := []struct {
+		attrs       []enum.FuncAttribute
+		want        string
+		sideEffects bool
+	}{
+		{
+			want:        "%y = call i32 @f(i32 1)",
+			sideEffects: true,
+		},
+		{
+			attrs:       []enum.FuncAttribute{enum.FuncAttrReadOnly},
+			want:        "%y = call i32 @f(i32 1) readonly",
+			sideEffects: false,
+		},
+		{
+			attrs:       []enum.FuncAttribute{enum.FuncAttrReadNone, enum.FuncAttrNoUnwind},
+			want:        "%y = call i32 @f(i32 1) readnone nounwind",
+			sideEffects: false,
+		},
+		{
+			attrs:       []enum.FuncAttribute{enum.FuncAttrWriteOnly},
+			want:        "%y = call i32 @f(i32 1) writeonly",
+			sideEffects: true,
+		},
+	}
+	for _, g := range golden {
+		call := NewCall(f, NewInt(types.I32, 1))
+		call.SetName("y")
+		call.FuncAttrs = g.attrs
+		if got := defString(call); g.want != got {
+			t.Errorf("call instruction mismatch; expected `%v`, got `%v`", g.want, got)
+		}
+		if got := MayHaveSideEffects(call); g.sideEffects != got {
+			t.Errorf("side effects mismatch of `%v`; expected %v, got %v", g.want, g.sideEffects, got)
+		}
+	}
+}
@@ -2,11 +2,13 @@ package ir
 
 import (
 	"fmt"
+	"math/big"
 	"strings"
 
 	"github.com/llir/l/internal/enc"
 	"github.com/llir/l/ir/types"
 	"github.com/llir/l/ir/value"
+	"github.com/pkg/errors"
 )
 
 // --- [ Vector instructions ] -------------------------------------------------
@@ -75,12 +77,29 @@ func (inst *InstExtractElement) Def() string {
 	// "extractelement" Type Value "," Type Value OptCommaSepMetadataAttachmentList
 	buf := &strings.Builder{}
 	fmt.Fprintf(buf, "extractelement %v, %v", inst.X, inst.Index)
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstExtractElement) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Index}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstExtractElement) DebugID() string {
+	return debugID("extractelement", inst)
+}
+
+// Validate reports an error if the extractelement instruction is invalid; e.g. if a
+// constant element index is out of range of a fixed-length vector.
+func (inst *InstExtractElement) Validate() error {
+	return validateElemIndex(inst.X, inst.Index)
+}
+
 // ~~~ [ insertelement ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstInsertElement is an LLVM IR insertelement instruction.
@@ -147,12 +166,29 @@ func (inst *InstInsertElement) Def() string {
 	// "insertelement" Type Value "," Type Value "," Type Value OptCommaSepMetadataAttachmentList
 	buf := &strings.Builder{}
 	fmt.Fprintf(buf, "insertelement %v, %v, %v", inst.X, inst.Elem, inst.Index)
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstInsertElement) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Elem, &inst.Index}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstInsertElement) DebugID() string {
+	return debugID("insertelement", inst)
+}
+
+// Validate reports an error if the insertelement instruction is invalid; e.g. if a
+// constant element index is out of range of a fixed-length vector.
+func (inst *InstInsertElement) Validate() error {
+	return validateElemIndex(inst.X, inst.Index)
+}
+
 // ~~~ [ shufflevector ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstShuffleVector is an LLVM IR shufflevector instruction.
@@ -196,7 +232,7 @@ func (inst *InstShuffleVector) Type() types.Type {
 		if !ok {
 			panic(fmt.Errorf("invalid vector type; expected *types.VectorType, got %T", inst.Mask.Type()))
 		}
-		inst.Typ = types.NewVector(maskType.Len, xType.ElemType)
+		inst.Typ = &types.VectorType{Len: maskType.Len, ElemType: xType.ElemType, Scalable: maskType.Scalable}
 	}
 	return inst.Typ
 }
@@ -221,8 +257,41 @@ func (inst *InstShuffleVector) Def() string {
 	// "shufflevector" Type Value "," Type Value "," Type Value OptCommaSepMetadataAttachmentList
 	buf := &strings.Builder{}
 	fmt.Fprintf(buf, "shufflevector %v, %v, %v", inst.X, inst.Y, inst.Mask)
-	for _, md := range inst.Metadata {
+	for _, md := range canonicalMetadata(inst.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
+
+// Operands returns a mutable list of operands of the instruction.
+func (inst *InstShuffleVector) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y, &inst.Mask}
+}
+
+// DebugID returns a stable debug identifier of the instruction, which is
+// independent of the local name of the instruction.
+func (inst *InstShuffleVector) DebugID() string {
+	return debugID("shufflevector", inst)
+}
+
+// ### [ Helper functions ] ####################################################
+
+// validateElemIndex reports an error if the given element index is a constant
+// out of range of the given vector. The number of elements of scalable vectors
+// is not known at compile time, and their element indices are thus not
+// checked.
+func validateElemIndex(x, index value.Value) error {
+	t, ok := x.Type().(*types.VectorType)
+	if !ok {
+		return errors.Errorf("invalid vector type; expected *types.VectorType, got %T", x.Type())
+	}
+	if t.Scalable {
+		return nil
+	}
+	if c, ok := index.(*ConstInt); ok {
+		if c.X.Sign() < 0 || c.X.Cmp(big.NewInt(t.Len)) >= 0 {
+			return errors.Errorf("invalid element index of vector type %v; expected 0 <= index < %d, got %v", t, t.Len, c.X)
+		}
+	}
+	return nil
+}
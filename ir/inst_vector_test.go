@@ -0,0 +1,31 @@
+package ir
+
+import (
+	"testing"
+
+	"github.com/llir/l/ir/types"
+)
+
+func TestExtractElementValidate(t *testing.T) {
+	fixed := NewParam(types.NewVector(4, types.I32), "v")
+	scalable := NewParam(types.NewScalableVector(4, types.I32), "w")
+	golden := []struct {
+		in      *InstExtractElement
+		wantErr bool
+	}{
+		{in: NewExtractElement(fixed, NewInt(types.I32, 3)), wantErr: false},
+		{in: NewExtractElement(fixed, NewInt(types.I32, 4)), wantErr: true},
+		// The number of elements of scalable vectors is not known at compile
+		// time.
+		{in: NewExtractElement(scalable, NewInt(types.I32, 4)), wantErr: false},
+	}
+	for _, g := range golden {
+		err := g.in.Validate()
+		if g.wantErr != (err != nil) {
+			t.Errorf("validation mismatch of `%v`; expected error %v, got %v", g.in.Def(), g.wantErr, err)
+		}
+	}
+	if want, got := "i32", NewExtractElement(scalable, NewInt(types.I32, 0)).Type().String(); want != got {
+		t.Errorf("extractelement type mismatch; expected `%v`, got `%v`", want, got)
+	}
+}
@@ -1,5 +1,12 @@
 package ir
 
+import (
+	"fmt"
+
+	"github.com/llir/l/ir/enum"
+	"github.com/llir/l/ir/value"
+)
+
 // === [ Instructions ] ========================================================
 
 // Instruction is an LLVM IR instruction. All instructions (except store and
@@ -89,6 +96,7 @@ package ir
 //    *ir.InstFCmp         // https://godoc.org/github.com/llir/l/ir#InstFCmp
 //    *ir.InstPhi          // https://godoc.org/github.com/llir/l/ir#InstPhi
 //    *ir.InstSelect       // https://godoc.org/github.com/llir/l/ir#InstSelect
+//    *ir.InstFreeze       // https://godoc.org/github.com/llir/l/ir#InstFreeze
 //    *ir.InstCall         // https://godoc.org/github.com/llir/l/ir#InstCall
 //    *ir.InstVAArg        // https://godoc.org/github.com/llir/l/ir#InstVAArg
 //    *ir.InstLandingPad   // https://godoc.org/github.com/llir/l/ir#InstLandingPad
@@ -97,11 +105,114 @@ package ir
 type Instruction interface {
 	// Def returns the LLVM syntax representation of the instruction.
 	Def() string
+	// Operands returns a mutable list of operands of the instruction.
+	Operands() []*value.Value
+	// DebugID returns a stable debug identifier of the instruction, which is
+	// independent of the local name of the instruction.
+	DebugID() string
 	// isInstruction ensures that only instructions can be assigned to the
 	// instruction.Instruction interface.
 	isInstruction()
 }
 
+// Definer is an LLVM IR value or instruction with a definition.
+//
+// The Def method returns the LLVM syntax representation of the definition
+// (e.g. "load i32, i32* %p") while the Ident method of value.Value returns the
+// identifier used to refer to the value as an operand (e.g. "%x"). The
+// defining line of a named value instruction within a basic block combines the
+// two (e.g. "%x = load i32, i32* %p").
+type Definer interface {
+	// Def returns the LLVM syntax representation of the definition.
+	Def() string
+}
+
+// SafeString returns the LLVM syntax representation of the instruction, or an
+// "<invalid: reason>" marker if the instruction is malformed (e.g. a load with
+// a non-pointer source). SafeString never panics, which makes it suitable for
+// debugging dumps of partially built IR.
+func SafeString(inst Instruction) (s string) {
+	defer func() {
+		if e := recover(); e != nil {
+			s = fmt.Sprintf("<invalid: %v>", e)
+		}
+	}()
+	if v, ok := inst.(value.Value); ok {
+		// Compute the type of value instructions to detect malformed operands.
+		v.Type()
+	}
+	return inst.Def()
+}
+
+// MayHaveSideEffects reports whether the given instruction may have side
+// effects; i.e. whether it may write to memory or otherwise affect the state of
+// the program beyond producing its result.
+//
+// Store, fence, cmpxchg, atomicrmw and va_arg instructions, volatile and atomic
+// load instructions, and exception handling pad instructions may have side
+// effects. Call instructions may have side effects unless the call or the
+// callee function is known to only read memory, as specified by the readnone
+// and readonly function attributes or a memory attribute with at most read
+// effects. All other instructions (e.g. binary, bitwise, conversion,
+// getelementptr, icmp and non-volatile load instructions) are free of side
+// effects.
+func MayHaveSideEffects(inst Instruction) bool {
+	switch inst := inst.(type) {
+	case *InstStore, *InstFence, *InstCmpXchg, *InstAtomicRMW, *InstVAArg:
+		return true
+	case *InstLoad:
+		return inst.Volatile || inst.Atomic || inst.Ordering != enum.AtomicOrderingNone
+	case *InstCall:
+		if onlyReadsMemory(inst.FuncAttrs) {
+			return false
+		}
+		if callee, ok := inst.Callee.(*Function); ok && onlyReadsMemory(callee.FuncAttrs) {
+			return false
+		}
+		return true
+	case *InstLandingPad, *InstCatchPad, *InstCleanupPad:
+		return true
+	}
+	return false
+}
+
+// onlyReadsMemory reports whether the given function attributes specify that
+// the function at most reads memory; i.e. if the readnone or readonly function
+// attribute is present, or a memory attribute without write effects.
+func onlyReadsMemory(attrs []enum.FuncAttribute) bool {
+	for _, attr := range attrs {
+		switch attr := attr.(type) {
+		case enum.FuncAttr:
+			if attr == enum.FuncAttrReadNone || attr == enum.FuncAttrReadOnly {
+				return true
+			}
+		case Memory:
+			if !readsOnly(attr.Default) {
+				return false
+			}
+			for _, loc := range attr.Locations {
+				if !readsOnly(loc.Effect) {
+					return false
+				}
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// readsOnly reports whether the given memory effect is free of writes.
+func readsOnly(effect enum.MemoryEffect) bool {
+	return effect == enum.MemoryEffectNone || effect == enum.MemoryEffectRead
+}
+
+// debugID returns a debug identifier based on the given instruction kind and
+// the address of the given instruction, which remains stable for the lifetime
+// of the instruction.
+func debugID(kind string, inst interface{}) string {
+	return fmt.Sprintf("%s#%p", kind, inst)
+}
+
 // Binary instructions.
 func (*InstAdd) isInstruction()  {}
 func (*InstFAdd) isInstruction() {}
@@ -162,6 +273,7 @@ func (*InstICmp) isInstruction()       {}
 func (*InstFCmp) isInstruction()       {}
 func (*InstPhi) isInstruction()        {}
 func (*InstSelect) isInstruction()     {}
+func (*InstFreeze) isInstruction()     {}
 func (*InstCall) isInstruction()       {}
 func (*InstVAArg) isInstruction()      {}
 func (*InstLandingPad) isInstruction() {}
@@ -1,5 +1,13 @@
 package ir
 
+import (
+	"strings"
+	"testing"
+
+	"github.com/llir/l/ir/enum"
+	"github.com/llir/l/ir/types"
+)
+
 // Assert that each instruction implements the ir.Instruction interface.
 var (
 	// Binary instructions.
@@ -56,6 +64,7 @@ var (
 	_ Instruction = (*InstFCmp)(nil)
 	_ Instruction = (*InstPhi)(nil)
 	_ Instruction = (*InstSelect)(nil)
+	_ Instruction = (*InstFreeze)(nil)
 	_ Instruction = (*InstCall)(nil)
 	_ Instruction = (*InstVAArg)(nil)
 	_ Instruction = (*InstLandingPad)(nil)
@@ -72,9 +81,118 @@ var (
 	_ Terminator = (*TermSwitch)(nil)
 	_ Terminator = (*TermIndirectBr)(nil)
 	_ Terminator = (*TermInvoke)(nil)
+	_ Terminator = (*TermCallBr)(nil)
 	_ Terminator = (*TermResume)(nil)
 	_ Terminator = (*TermCatchSwitch)(nil)
 	_ Terminator = (*TermCatchRet)(nil)
 	_ Terminator = (*TermCleanupRet)(nil)
 	_ Terminator = (*TermUnreachable)(nil)
 )
+
+func TestSafeString(t *testing.T) {
+	golden := []struct {
+		in   Instruction
+		want string
+	}{
+		// Valid load.
+		{
+			in:   NewLoad(NewParam(types.I32Ptr, "p")),
+			want: "load i32, i32* %p",
+		},
+		// Load with non-pointer source.
+		{
+			in:   NewLoad(NewParam(types.I32, "x")),
+			want: "<invalid: invalid source type; expected *types.PointerType, got *types.IntType>",
+		},
+		// Store without a value result.
+		{
+			in:   NewStore(NewInt(types.I32, 1), NewParam(types.I32Ptr, "p")),
+			want: "store i32 1, i32* %p",
+		},
+	}
+	for _, g := range golden {
+		got := SafeString(g.in)
+		if g.want != got {
+			t.Errorf("instruction mismatch; expected `%v`, got `%v`", g.want, got)
+		}
+	}
+}
+
+func TestDebugID(t *testing.T) {
+	p := NewParam(types.I32Ptr, "p")
+	x := NewLoad(p)
+	y := NewLoad(p)
+	xID := x.DebugID()
+	if !strings.HasPrefix(xID, "load#") {
+		t.Errorf("debug ID mismatch; expected `load#` prefix, got `%v`", xID)
+	}
+	if xID == y.DebugID() {
+		t.Errorf("expected distinct debug IDs, got `%v` for both", xID)
+	}
+	// Debug IDs are stable and independent of local names.
+	x.SetName("x")
+	if got := x.DebugID(); xID != got {
+		t.Errorf("debug ID mismatch; expected `%v`, got `%v`", xID, got)
+	}
+	if got := (&InstStore{}).DebugID(); !strings.HasPrefix(got, "store#") {
+		t.Errorf("debug ID mismatch; expected `store#` prefix, got `%v`", got)
+	}
+}
+
+// assertIR reports a test error if the LLVM syntax representation of the given
+// instruction differs from want.
+func assertIR(t *testing.T, inst Instruction, want string) {
+	t.Helper()
+	if got := inst.Def(); want != got {
+		t.Errorf("%T mismatch; expected `%v`, got `%v`", inst, want, got)
+	}
+}
+
+func TestMayHaveSideEffects(t *testing.T) {
+	p := NewParam(types.I32Ptr, "p")
+	x := NewParam(types.I32, "x")
+	volatileLoad := NewLoad(p)
+	volatileLoad.Volatile = true
+	atomicLoad := NewLoad(p)
+	atomicLoad.Ordering = enum.AtomicOrderingAcquire
+	f := NewFunction("f", types.I32)
+	readnone := NewFunction("readnone", types.I32)
+	readnone.FuncAttrs = []enum.FuncAttribute{enum.FuncAttrNoUnwind, enum.FuncAttrReadNone}
+	readonly := NewFunction("readonly", types.I32)
+	readonly.FuncAttrs = []enum.FuncAttribute{enum.FuncAttrReadOnly}
+	memRead := NewFunction("mem_read", types.I32)
+	memRead.FuncAttrs = []enum.FuncAttribute{Memory{Default: enum.MemoryEffectRead}}
+	memArgWrite := NewFunction("mem_argmem_write", types.I32)
+	memArgWrite.FuncAttrs = []enum.FuncAttribute{Memory{Locations: []MemoryLocationEffect{{Location: enum.MemoryLocationArgMem, Effect: enum.MemoryEffectWrite}}}}
+	readnoneCall := NewCall(f)
+	readnoneCall.FuncAttrs = []enum.FuncAttribute{enum.FuncAttrReadNone}
+	golden := []struct {
+		in   Instruction
+		want bool
+	}{
+		// Instructions with side effects.
+		{in: NewStore(x, p), want: true},
+		{in: volatileLoad, want: true},
+		{in: atomicLoad, want: true},
+		{in: NewFence(enum.AtomicOrderingSeqCst), want: true},
+		{in: NewCmpXchg(p, x, x, enum.AtomicOrderingSeqCst, enum.AtomicOrderingSeqCst), want: true},
+		{in: NewAtomicRMW(enum.AtomicOpAdd, p, x, enum.AtomicOrderingSeqCst), want: true},
+		{in: NewCall(f), want: true},
+		{in: NewCall(memArgWrite), want: true},
+		// Instructions without side effects.
+		{in: NewAdd(x, x), want: false},
+		{in: NewGetElementPtr(types.I32, p, x), want: false},
+		{in: NewSExt(x, types.I64), want: false},
+		{in: NewICmp(enum.IPredEQ, x, x), want: false},
+		{in: NewLoad(p), want: false},
+		{in: NewCall(readnone), want: false},
+		{in: NewCall(readonly), want: false},
+		{in: NewCall(memRead), want: false},
+		{in: readnoneCall, want: false},
+	}
+	for _, g := range golden {
+		if got := MayHaveSideEffects(g.in); g.want != got {
+			t.Errorf("side effects mismatch of `%v`; expected %v, got %v", g.in.Def(), g.want, got)
+		}
+	}
+}
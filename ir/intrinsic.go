@@ -0,0 +1,304 @@
+package ir
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/llir/l/ir/enum"
+	"github.com/llir/l/ir/types"
+	"github.com/llir/l/ir/value"
+	"github.com/pkg/errors"
+)
+
+// === [ Intrinsic functions ] =================================================
+
+// Intrinsic returns the declaration of the intrinsic function with the given
+// name (e.g. "llvm.ctpop.i32"), appending a new function declaration to the
+// module if not already present.
+//
+// The signature of overloaded intrinsic functions is derived from the overload
+// type suffix of the name (e.g. ".i32", ".v4f32" or ".p0i8"), and parameters
+// are given the parameter attributes required by the intrinsic function (e.g.
+// nocapture on pointer parameters and immarg on immediate arguments). An error
+// is returned if the intrinsic function is unknown, or if the overload type
+// suffix is invalid.
+func (m *Module) Intrinsic(name string) (*Function, error) {
+	if f, ok := m.Func(name); ok {
+		return f, nil
+	}
+	sig, err := intrinsicSig(name)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	f := &Function{GlobalName: name, Sig: sig}
+	attrs := intrinsicParamAttrs[intrinsicBase(name)]
+	for i, paramType := range sig.Params {
+		param := NewParam(paramType, "")
+		if i < len(attrs) {
+			// Copy the parameter attributes, so that modifications of the
+			// declaration do not affect intrinsicParamAttrs.
+			param.Attrs = append([]enum.ParamAttribute(nil), attrs[i]...)
+		}
+		f.Params = append(f.Params, param)
+	}
+	m.Funcs = append(m.Funcs, f)
+	return f, nil
+}
+
+// NewDbgDeclare returns a new call to the llvm.dbg.declare intrinsic function,
+// describing the local variable stored at the given address by the given debug
+// info variable and expression metadata nodes (e.g. "call void
+// @llvm.dbg.declare(metadata i32* %x, metadata !12, metadata
+// !DIExpression())"). The intrinsic function is declared in the module if not
+// already present. The call instruction is not appended to any basic block.
+func (m *Module) NewDbgDeclare(addr value.Value, variable, expr MDNode) *InstCall {
+	return m.newDbgCall("llvm.dbg.declare", addr, variable, expr)
+}
+
+// NewDbgValue returns a new call to the llvm.dbg.value intrinsic function,
+// describing the current value of a local variable by the given debug info
+// variable and expression metadata nodes (e.g. "call void
+// @llvm.dbg.value(metadata i32 %x, metadata !12, metadata !DIExpression())").
+// The intrinsic function is declared in the module if not already present. The
+// call instruction is not appended to any basic block.
+func (m *Module) NewDbgValue(v value.Value, variable, expr MDNode) *InstCall {
+	return m.newDbgCall("llvm.dbg.value", v, variable, expr)
+}
+
+// newDbgCall returns a new call to the debug info intrinsic function with the
+// given name, passing the given value, variable and expression as metadata
+// arguments.
+func (m *Module) newDbgCall(name string, v value.Value, variable, expr MDNode) *InstCall {
+	callee, err := m.Intrinsic(name)
+	if err != nil {
+		panic(fmt.Errorf("unable to declare intrinsic function %q; %v", name, err))
+	}
+	args := []value.Value{
+		NewMetadataValue(NewMDValue(v)),
+		NewMetadataValue(variable),
+		NewMetadataValue(expr),
+	}
+	return NewCall(callee, args...)
+}
+
+// intrinsic specifies the signature of an intrinsic function.
+type intrinsic struct {
+	// Number of overload types of the intrinsic function name.
+	noverloads int
+	// sig returns the function signature based on the given overload types.
+	sig func(ts []types.Type) *types.FuncType
+}
+
+// intrinsics maps from intrinsic function name (without overload type suffix)
+// to signature.
+var intrinsics = map[string]intrinsic{
+	// Bit manipulation intrinsics.
+	"llvm.bitreverse": {1, unaryIntrinsic},
+	"llvm.bswap":      {1, unaryIntrinsic},
+	"llvm.ctpop":      {1, unaryIntrinsic},
+	"llvm.ctlz":       {1, zeroUndefIntrinsic},
+	"llvm.cttz":       {1, zeroUndefIntrinsic},
+	"llvm.abs":        {1, zeroUndefIntrinsic},
+	"llvm.smax":       {1, binaryIntrinsic},
+	"llvm.smin":       {1, binaryIntrinsic},
+	"llvm.umax":       {1, binaryIntrinsic},
+	"llvm.umin":       {1, binaryIntrinsic},
+	// Arithmetic with overflow intrinsics.
+	"llvm.sadd.with.overflow": {1, overflowIntrinsic},
+	"llvm.uadd.with.overflow": {1, overflowIntrinsic},
+	"llvm.ssub.with.overflow": {1, overflowIntrinsic},
+	"llvm.usub.with.overflow": {1, overflowIntrinsic},
+	"llvm.smul.with.overflow": {1, overflowIntrinsic},
+	"llvm.umul.with.overflow": {1, overflowIntrinsic},
+	// Floating-point intrinsics.
+	"llvm.sqrt":     {1, unaryIntrinsic},
+	"llvm.fabs":     {1, unaryIntrinsic},
+	"llvm.floor":    {1, unaryIntrinsic},
+	"llvm.ceil":     {1, unaryIntrinsic},
+	"llvm.trunc":    {1, unaryIntrinsic},
+	"llvm.round":    {1, unaryIntrinsic},
+	"llvm.sin":      {1, unaryIntrinsic},
+	"llvm.cos":      {1, unaryIntrinsic},
+	"llvm.exp":      {1, unaryIntrinsic},
+	"llvm.log":      {1, unaryIntrinsic},
+	"llvm.pow":      {1, binaryIntrinsic},
+	"llvm.minnum":   {1, binaryIntrinsic},
+	"llvm.maxnum":   {1, binaryIntrinsic},
+	"llvm.copysign": {1, binaryIntrinsic},
+	"llvm.fma":      {1, func(ts []types.Type) *types.FuncType { return types.NewFunc(ts[0], ts[0], ts[0], ts[0]) }},
+	// Memory intrinsics.
+	"llvm.memcpy": {3, func(ts []types.Type) *types.FuncType {
+		return types.NewFunc(types.Void, ts[0], ts[1], ts[2], types.I1)
+	}},
+	"llvm.memmove": {3, func(ts []types.Type) *types.FuncType {
+		return types.NewFunc(types.Void, ts[0], ts[1], ts[2], types.I1)
+	}},
+	"llvm.memset": {2, func(ts []types.Type) *types.FuncType {
+		return types.NewFunc(types.Void, ts[0], types.I8, ts[1], types.I1)
+	}},
+	// Variable argument intrinsics.
+	"llvm.va_start": {0, func([]types.Type) *types.FuncType { return types.NewFunc(types.Void, types.I8Ptr) }},
+	"llvm.va_end":   {0, func([]types.Type) *types.FuncType { return types.NewFunc(types.Void, types.I8Ptr) }},
+	"llvm.va_copy": {0, func([]types.Type) *types.FuncType {
+		return types.NewFunc(types.Void, types.I8Ptr, types.I8Ptr)
+	}},
+	// Debug info intrinsics.
+	"llvm.dbg.declare": {0, debugIntrinsic},
+	"llvm.dbg.value":   {0, debugIntrinsic},
+	// Other intrinsics.
+	"llvm.trap":         {0, func([]types.Type) *types.FuncType { return types.NewFunc(types.Void) }},
+	"llvm.debugtrap":    {0, func([]types.Type) *types.FuncType { return types.NewFunc(types.Void) }},
+	"llvm.donothing":    {0, func([]types.Type) *types.FuncType { return types.NewFunc(types.Void) }},
+	"llvm.stacksave":    {0, func([]types.Type) *types.FuncType { return types.NewFunc(types.I8Ptr) }},
+	"llvm.stackrestore": {0, func([]types.Type) *types.FuncType { return types.NewFunc(types.Void, types.I8Ptr) }},
+}
+
+// intrinsicParamAttrs maps from intrinsic function name (without overload type
+// suffix) to the parameter attributes of each parameter of the intrinsic
+// function.
+var intrinsicParamAttrs = map[string][][]enum.ParamAttribute{
+	// Bit manipulation intrinsics.
+	"llvm.ctlz": {nil, {enum.ParamAttrImmarg}},
+	"llvm.cttz": {nil, {enum.ParamAttrImmarg}},
+	"llvm.abs":  {nil, {enum.ParamAttrImmarg}},
+	// Memory intrinsics.
+	"llvm.memcpy": {
+		{enum.ParamAttrNoAlias, enum.ParamAttrNoCapture, enum.ParamAttrWriteOnly},
+		{enum.ParamAttrNoAlias, enum.ParamAttrNoCapture, enum.ParamAttrReadOnly},
+		nil,
+		{enum.ParamAttrImmarg},
+	},
+	"llvm.memmove": {
+		{enum.ParamAttrNoCapture, enum.ParamAttrWriteOnly},
+		{enum.ParamAttrNoCapture, enum.ParamAttrReadOnly},
+		nil,
+		{enum.ParamAttrImmarg},
+	},
+	"llvm.memset": {
+		{enum.ParamAttrNoCapture, enum.ParamAttrWriteOnly},
+		nil,
+		nil,
+		{enum.ParamAttrImmarg},
+	},
+}
+
+// unaryIntrinsic returns the signature of an intrinsic function with one
+// operand of the overload type (e.g. "i32 @llvm.ctpop.i32(i32)").
+func unaryIntrinsic(ts []types.Type) *types.FuncType {
+	return types.NewFunc(ts[0], ts[0])
+}
+
+// binaryIntrinsic returns the signature of an intrinsic function with two
+// operands of the overload type (e.g. "i32 @llvm.smax.i32(i32, i32)").
+func binaryIntrinsic(ts []types.Type) *types.FuncType {
+	return types.NewFunc(ts[0], ts[0], ts[0])
+}
+
+// zeroUndefIntrinsic returns the signature of an intrinsic function with one
+// operand of the overload type and an i1 flag (e.g. "i32 @llvm.ctlz.i32(i32,
+// i1)").
+func zeroUndefIntrinsic(ts []types.Type) *types.FuncType {
+	return types.NewFunc(ts[0], ts[0], types.I1)
+}
+
+// overflowIntrinsic returns the signature of an arithmetic with overflow
+// intrinsic function (e.g. "{ i32, i1 } @llvm.sadd.with.overflow.i32(i32,
+// i32)").
+func overflowIntrinsic(ts []types.Type) *types.FuncType {
+	return types.NewFunc(types.NewStruct(ts[0], types.I1), ts[0], ts[0])
+}
+
+// debugIntrinsic returns the signature of a debug info intrinsic function
+// (e.g. "void @llvm.dbg.value(metadata, metadata, metadata)").
+func debugIntrinsic([]types.Type) *types.FuncType {
+	return types.NewFunc(types.Void, types.Metadata, types.Metadata, types.Metadata)
+}
+
+// intrinsicSig returns the signature of the intrinsic function with the given
+// name.
+func intrinsicSig(name string) (*types.FuncType, error) {
+	base := intrinsicBase(name)
+	if len(base) == 0 {
+		return nil, errors.Errorf("unknown intrinsic function %q", name)
+	}
+	in := intrinsics[base]
+	var ts []types.Type
+	if len(name) > len(base) {
+		for _, suffix := range strings.Split(name[len(base)+1:], ".") {
+			t, err := parseOverloadType(suffix)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid overload type of intrinsic function %q", name)
+			}
+			ts = append(ts, t)
+		}
+	}
+	if len(ts) != in.noverloads {
+		return nil, errors.Errorf("invalid number of overload types of intrinsic function %q; expected %d, got %d", name, in.noverloads, len(ts))
+	}
+	return in.sig(ts), nil
+}
+
+// intrinsicBase returns the name of the intrinsic function with the given name,
+// without overload type suffix; or an empty string if unknown.
+func intrinsicBase(name string) string {
+	// Locate the longest intrinsic function name which is a prefix of name, as
+	// intrinsic function names may contain dots (e.g.
+	// "llvm.sadd.with.overflow").
+	base := ""
+	for key := range intrinsics {
+		if (name == key || strings.HasPrefix(name, key+".")) && len(key) > len(base) {
+			base = key
+		}
+	}
+	return base
+}
+
+// parseOverloadType parses the given overload type suffix of an intrinsic
+// function name (e.g. "i32", "f64", "v4f32" or "p0i8").
+func parseOverloadType(s string) (types.Type, error) {
+	switch s {
+	case "f16":
+		return types.Half, nil
+	case "bf16":
+		return types.BFloat, nil
+	case "f32":
+		return types.Float, nil
+	case "f64":
+		return types.Double, nil
+	case "f80":
+		return types.X86FP80, nil
+	case "f128":
+		return types.FP128, nil
+	}
+	if len(s) < 2 {
+		return nil, errors.Errorf("invalid overload type %q", s)
+	}
+	switch s[0] {
+	case 'i':
+		n, err := strconv.ParseInt(s[1:], 10, 64)
+		if err != nil || n <= 0 {
+			return nil, errors.Errorf("invalid integer overload type %q", s)
+		}
+		return types.NewInt(n), nil
+	case 'v', 'p':
+		// Split leading decimal length or address space from element type.
+		end := 1
+		for end < len(s) && '0' <= s[end] && s[end] <= '9' {
+			end++
+		}
+		n, err := strconv.ParseInt(s[1:end], 10, 64)
+		if err != nil {
+			return nil, errors.Errorf("invalid overload type %q", s)
+		}
+		elem, err := parseOverloadType(s[end:])
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if s[0] == 'v' {
+			return types.NewVector(n, elem), nil
+		}
+		return &types.PointerType{ElemType: elem, AddrSpace: types.AddrSpace(n)}, nil
+	}
+	return nil, errors.Errorf("invalid overload type %q", s)
+}
@@ -0,0 +1,160 @@
+package ir
+
+import (
+	"testing"
+
+	"github.com/llir/l/ir/enum"
+	"github.com/llir/l/ir/types"
+)
+
+func TestModuleIntrinsic(t *testing.T) {
+	golden := []struct {
+		name string
+		want string
+	}{
+		{name: "llvm.ctpop.i32", want: "declare i32 @llvm.ctpop.i32(i32)"},
+		{name: "llvm.sadd.with.overflow.i64", want: "declare { i64, i1 } @llvm.sadd.with.overflow.i64(i64, i64)"},
+		{name: "llvm.sqrt.v4f32", want: "declare <4 x float> @llvm.sqrt.v4f32(<4 x float>)"},
+		{name: "llvm.memcpy.p0i8.p0i8.i64", want: "declare void @llvm.memcpy.p0i8.p0i8.i64(i8* noalias nocapture writeonly, i8* noalias nocapture readonly, i64, i1 immarg)"},
+		{name: "llvm.memset.p0i8.i32", want: "declare void @llvm.memset.p0i8.i32(i8* nocapture writeonly, i8, i32, i1 immarg)"},
+		{name: "llvm.ctlz.i64", want: "declare i64 @llvm.ctlz.i64(i64, i1 immarg)"},
+		{name: "llvm.trap", want: "declare void @llvm.trap()"},
+	}
+	m := &Module{}
+	for _, g := range golden {
+		f, err := m.Intrinsic(g.name)
+		if err != nil {
+			t.Errorf("unable to create intrinsic %q; %v", g.name, err)
+			continue
+		}
+		if got := f.Def(); g.want != got {
+			t.Errorf("intrinsic declaration mismatch; expected `%v`, got `%v`", g.want, got)
+		}
+		// Declarations are reused.
+		if f2, err := m.Intrinsic(g.name); err != nil || f != f2 {
+			t.Errorf("intrinsic %q declared more than once", g.name)
+		}
+	}
+	if want, got := len(golden), len(m.Funcs); want != got {
+		t.Errorf("number of functions mismatch; expected %d, got %d", want, got)
+	}
+}
+
+func TestModuleIntrinsicParamAttrs(t *testing.T) {
+	const name = "llvm.memcpy.p0i8.p0i8.i64"
+	const want = "declare void @llvm.memcpy.p0i8.p0i8.i64(i8* noalias nocapture writeonly, i8* noalias nocapture readonly, i64, i1 immarg)"
+	m1 := &Module{}
+	f1, err := m1.Intrinsic(name)
+	if err != nil {
+		t.Fatalf("unable to create intrinsic %q; %v", name, err)
+	}
+	// Modify the parameter attributes of the declaration.
+	f1.Params[0].Attrs[0] = enum.ParamAttrNonNull
+	f1.Params[3].Attrs = append(f1.Params[3].Attrs[:0], enum.ParamAttrZeroExt)
+	// Declarations of other modules are not affected.
+	m2 := &Module{}
+	f2, err := m2.Intrinsic(name)
+	if err != nil {
+		t.Fatalf("unable to create intrinsic %q; %v", name, err)
+	}
+	if got := f2.Def(); want != got {
+		t.Errorf("intrinsic declaration mismatch; expected `%v`, got `%v`", want, got)
+	}
+}
+
+func TestModuleIntrinsicUnknown(t *testing.T) {
+	golden := []struct {
+		name string
+		want string
+	}{
+		{name: "llvm.foo.i32", want: `unknown intrinsic function "llvm.foo.i32"`},
+		{name: "llvm.ctpop", want: `invalid number of overload types of intrinsic function "llvm.ctpop"; expected 1, got 0`},
+		{name: "llvm.ctpop.x32", want: `invalid overload type of intrinsic function "llvm.ctpop.x32": invalid overload type "x32"`},
+	}
+	m := &Module{}
+	for _, g := range golden {
+		_, err := m.Intrinsic(g.name)
+		if err == nil {
+			t.Errorf("expected error for intrinsic %q", g.name)
+			continue
+		}
+		if got := err.Error(); g.want != got {
+			t.Errorf("error mismatch; expected `%v`, got `%v`", g.want, got)
+		}
+	}
+	if len(m.Funcs) != 0 {
+		t.Errorf("unexpected function declarations of unknown intrinsics; got %d", len(m.Funcs))
+	}
+}
+
+func TestModuleNewDbgDeclare(t *testing.T) {
+	m := &Module{}
+	x := NewParam(types.I32, "x")
+	addr := NewAlloca(types.I32)
+	addr.SetName("x.addr")
+	variable := NewMDTuple(MDString("x"))
+	expr := NewDIExpression()
+	declare := m.NewDbgDeclare(addr, variable, expr)
+	value := m.NewDbgValue(x, variable, expr)
+	entry := NewBlock("entry")
+	entry.Insts = append(entry.Insts, addr, declare, value)
+	entry.NewRet(nil)
+	f := NewFunction("f", types.Void, x)
+	f.Blocks = []*BasicBlock{entry}
+	m.Funcs = append(m.Funcs, f)
+	m.AssignMetadataIDs()
+	golden := []struct {
+		in   *InstCall
+		want string
+	}{
+		{in: declare, want: "call void @llvm.dbg.declare(metadata i32* %x.addr, metadata !0, metadata !DIExpression())"},
+		{in: value, want: "call void @llvm.dbg.value(metadata i32 %x, metadata !0, metadata !DIExpression())"},
+	}
+	for _, g := range golden {
+		if got := g.in.Def(); g.want != got {
+			t.Errorf("debug intrinsic call mismatch; expected `%v`, got `%v`", g.want, got)
+		}
+	}
+	// The debug intrinsics are declared once, and metadata arguments other than
+	// debug expressions are assigned metadata IDs.
+	if len(m.MetadataDefs) != 1 {
+		t.Errorf("number of metadata definitions mismatch; expected 1, got %d", len(m.MetadataDefs))
+	}
+	want := []string{
+		"declare void @llvm.dbg.declare(metadata, metadata, metadata)",
+		"declare void @llvm.dbg.value(metadata, metadata, metadata)",
+	}
+	if len(m.Funcs) != len(want)+1 {
+		t.Fatalf("number of functions mismatch; expected %d, got %d", len(want)+1, len(m.Funcs))
+	}
+	for i, f := range m.Funcs[:len(want)] {
+		if got := f.Def(); want[i] != got {
+			t.Errorf("intrinsic declaration mismatch; expected `%v`, got `%v`", want[i], got)
+		}
+	}
+}
+
+func TestCallValidateImmArg(t *testing.T) {
+	m := &Module{}
+	memcpy, err := m.Intrinsic("llvm.memcpy.p0i8.p0i8.i64")
+	if err != nil {
+		t.Fatalf("unable to create intrinsic; %v", err)
+	}
+	dst := NewParam(types.I8Ptr, "dst")
+	src := NewParam(types.I8Ptr, "src")
+	isVolatile := NewParam(types.I1, "volatile")
+	n := NewInt(types.I64, 8)
+	call := NewCall(memcpy, dst, src, n, NewInt(types.I1, 0))
+	if err := call.Validate(); err != nil {
+		t.Errorf("unexpected error for `%v`; %v", call.Def(), err)
+	}
+	call = NewCall(memcpy, dst, src, n, isVolatile)
+	want := "invalid argument 3 in call to @llvm.memcpy.p0i8.p0i8.i64; expected integer or floating-point constant for immarg parameter, got i1 %volatile"
+	err = call.Validate()
+	if err == nil {
+		t.Fatalf("expected error for non-constant immarg argument, got nil")
+	}
+	if got := err.Error(); want != got {
+		t.Errorf("error mismatch; expected `%v`, got `%v`", want, got)
+	}
+}
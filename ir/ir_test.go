@@ -1,9 +1,11 @@
 package ir
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 
+	"github.com/llir/l/ir/enum"
 	"github.com/llir/l/ir/types"
 )
 
@@ -27,6 +29,41 @@ func TestModuleString(t *testing.T) {
 			},
 			want: "%foo = type { i32 }",
 		},
+		// Source filename.
+		{
+			in: &Module{
+				SourceFilename: "foo.c",
+				TypeDefs: []types.Type{&types.StructType{
+					Alias:  "foo",
+					Fields: []types.Type{types.I32},
+				}},
+			},
+			want: "source_filename = \"foo.c\"\n\n%foo = type { i32 }",
+		},
+		// Module ID.
+		{
+			in: &Module{
+				ModuleID:       "foo.c",
+				SourceFilename: "foo.c",
+			},
+			want: "; ModuleID = 'foo.c'\nsource_filename = \"foo.c\"",
+		},
+		// Data layout and target triple.
+		{
+			in: &Module{
+				SourceFilename: "foo.c",
+				DataLayout:     "e-m:e-i64:64-f80:128-n8:16:32:64-S128",
+				TargetTriple:   "x86_64-pc-linux-gnu",
+			},
+			want: "source_filename = \"foo.c\"\ntarget datalayout = \"e-m:e-i64:64-f80:128-n8:16:32:64-S128\"\ntarget triple = \"x86_64-pc-linux-gnu\"",
+		},
+		// Global variable definition.
+		{
+			in: &Module{
+				Globals: []*Global{NewGlobalDef("x", NewInt(types.I32, 42))},
+			},
+			want: "@x = global i32 42",
+		},
 	}
 	for _, g := range golden {
 		got := strings.TrimSpace(g.in.Def())
@@ -35,3 +72,384 @@ func TestModuleString(t *testing.T) {
 		}
 	}
 }
+
+func TestModuleByLinkage(t *testing.T) {
+	m := &Module{}
+	f := addTestFunc(m, "f", enum.LinkageNone)
+	g := addTestFunc(m, "g", enum.LinkageInternal)
+	h := addTestFunc(m, "h", enum.LinkageExternal)
+	i := addTestFunc(m, "i", enum.LinkageInternal)
+	x := m.NewGlobalDef("x", NewInt(types.I32, 1))
+	y := m.NewGlobalDef("y", NewInt(types.I32, 2))
+	y.Linkage = enum.LinkageInternal
+	golden := []struct {
+		linkage     enum.Linkage
+		wantFuncs   []*Function
+		wantGlobals []*Global
+	}{
+		{linkage: enum.LinkageInternal, wantFuncs: []*Function{g, i}, wantGlobals: []*Global{y}},
+		{linkage: enum.LinkageExternal, wantFuncs: []*Function{f, h}, wantGlobals: []*Global{x}},
+		{linkage: enum.LinkagePrivate},
+	}
+	for _, g := range golden {
+		funcs := m.FuncsByLinkage(g.linkage)
+		if len(g.wantFuncs) != len(funcs) {
+			t.Errorf("number of %v functions mismatch; expected %d, got %d", g.linkage, len(g.wantFuncs), len(funcs))
+			continue
+		}
+		for j := range funcs {
+			if g.wantFuncs[j] != funcs[j] {
+				t.Errorf("%v function mismatch; expected %v, got %v", g.linkage, g.wantFuncs[j].Ident(), funcs[j].Ident())
+			}
+		}
+		globals := m.GlobalsByLinkage(g.linkage)
+		if len(g.wantGlobals) != len(globals) {
+			t.Errorf("number of %v globals mismatch; expected %d, got %d", g.linkage, len(g.wantGlobals), len(globals))
+			continue
+		}
+		for j := range globals {
+			if g.wantGlobals[j] != globals[j] {
+				t.Errorf("%v global mismatch; expected %v, got %v", g.linkage, g.wantGlobals[j].Ident(), globals[j].Ident())
+			}
+		}
+	}
+}
+
+func TestModuleLookup(t *testing.T) {
+	m := &Module{}
+	f := addTestFunc(m, "f", enum.LinkageNone)
+	x := m.NewGlobalDef("x", NewInt(types.I32, 1))
+	// Hit.
+	if got, ok := m.Func("f"); !ok || got != f {
+		t.Errorf("function lookup mismatch; expected %v, got %v (ok=%v)", f.Ident(), got, ok)
+	}
+	if got, ok := m.Global("x"); !ok || got != x {
+		t.Errorf("global lookup mismatch; expected %v, got %v (ok=%v)", x.Ident(), got, ok)
+	}
+	// Miss.
+	if got, ok := m.Func("g"); ok {
+		t.Errorf("unexpected function %v found", got.Ident())
+	}
+	if got, ok := m.Global("y"); ok {
+		t.Errorf("unexpected global %v found", got.Ident())
+	}
+	// Hit after definitions are added.
+	g := addTestFunc(m, "g", enum.LinkageNone)
+	if got, ok := m.Func("g"); !ok || got != g {
+		t.Errorf("function lookup mismatch; expected %v, got %v (ok=%v)", g.Ident(), got, ok)
+	}
+	y := m.NewGlobalDef("y", NewInt(types.I32, 2))
+	if got, ok := m.Global("y"); !ok || got != y {
+		t.Errorf("global lookup mismatch; expected %v, got %v (ok=%v)", y.Ident(), got, ok)
+	}
+	// Misses do not rebuild the symbol table.
+	funcTable := reflect.ValueOf(m.funcTable).Pointer()
+	if got, ok := m.Func("h"); ok {
+		t.Errorf("unexpected function %v found", got.Ident())
+	}
+	if funcTable != reflect.ValueOf(m.funcTable).Pointer() {
+		t.Errorf("function symbol table rebuilt on miss")
+	}
+	// Hit by new name after rename, once the stale hit of the old name has
+	// rebuilt the symbol table.
+	f.SetName("f2")
+	if got, ok := m.Func("f"); ok {
+		t.Errorf("unexpected function %v found", got.Ident())
+	}
+	if got, ok := m.Func("f2"); !ok || got != f {
+		t.Errorf("function lookup mismatch; expected %v, got %v (ok=%v)", f.Ident(), got, ok)
+	}
+	x.SetName("x2")
+	if got, ok := m.Global("x"); ok {
+		t.Errorf("unexpected global %v found", got.Ident())
+	}
+	if got, ok := m.Global("x2"); !ok || got != x {
+		t.Errorf("global lookup mismatch; expected %v, got %v (ok=%v)", x.Ident(), got, ok)
+	}
+	// Hit after in-place replacement, once the number of symbols has changed.
+	h := NewFunction("h", types.Void)
+	m.Funcs[1] = h
+	addTestFunc(m, "i", enum.LinkageNone)
+	if got, ok := m.Func("h"); !ok || got != h {
+		t.Errorf("function lookup mismatch; expected %v, got %v (ok=%v)", h.Ident(), got, ok)
+	}
+	if got, ok := m.Func("g"); ok {
+		t.Errorf("unexpected function %v found", got.Ident())
+	}
+	z := NewGlobalDef("z", NewInt(types.I32, 3))
+	m.Globals[1] = z
+	m.NewGlobalDecl("w", types.I32)
+	if got, ok := m.Global("z"); !ok || got != z {
+		t.Errorf("global lookup mismatch; expected %v, got %v (ok=%v)", z.Ident(), got, ok)
+	}
+}
+
+func TestModuleNewFunc(t *testing.T) {
+	m := &Module{}
+	f := m.NewFunc("f", types.Void)
+	x := NewParam(types.I32, "x")
+	g := m.NewFunc("g", types.I32, x)
+	entry := NewBlock("entry")
+	entry.NewRet(x)
+	g.Blocks = append(g.Blocks, entry)
+	want := `declare void @f()
+
+define i32 @g(i32 %x) {
+entry:
+  ret i32 %x
+}`
+	if got := strings.TrimSpace(m.Def()); want != got {
+		t.Errorf("module mismatch; expected `%v`, got `%v`", want, got)
+	}
+	// Replace function without changing the number of functions of the module.
+	if got, ok := m.Func("f"); !ok || got != f {
+		t.Errorf("function lookup mismatch; expected %v, got %v (ok=%v)", f.Ident(), got, ok)
+	}
+	m.Funcs = m.Funcs[:1]
+	m.Funcs[0] = g
+	h := m.NewFunc("h", types.Void)
+	if got, ok := m.Func("h"); !ok || got != h {
+		t.Errorf("function lookup mismatch; expected %v, got %v (ok=%v)", h.Ident(), got, ok)
+	}
+	// Module.NewFunction is kept for backwards compatibility.
+	i := m.NewFunction("i", types.Void)
+	if got, ok := m.Func("i"); !ok || got != i {
+		t.Errorf("function lookup mismatch; expected %v, got %v (ok=%v)", i.Ident(), got, ok)
+	}
+}
+
+func TestModuleVerify(t *testing.T) {
+	// Valid module.
+	foo := &ComdatDef{Name: "foo", Kind: enum.SelectionKindAny}
+	m := &Module{ComdatDefs: []*ComdatDef{foo}}
+	x := m.NewGlobalDef("x", NewInt(types.I32, 1))
+	x.Comdat = foo
+	m.NewFunc("f", types.Void)
+	if err := m.Verify(); err != nil {
+		t.Errorf("unexpected error; %v", err)
+	}
+	// Duplicate global identifier and dangling comdat reference.
+	bar := &ComdatDef{Name: "bar", Kind: enum.SelectionKindAny}
+	m.NewGlobalDef("y", NewInt(types.I32, 2)).Comdat = bar
+	m.NewFunc("x", types.Void)
+	err := m.Verify()
+	errs, ok := err.(Errors)
+	if !ok {
+		t.Fatalf("invalid error type; expected ir.Errors, got %T", err)
+	}
+	want := []string{
+		"undefined comdat $bar referenced by @y",
+		"duplicate global identifier @x",
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("number of errors mismatch; expected %d, got %d (%v)", len(want), len(errs), err)
+	}
+	for i := range want {
+		if got := errs[i].Error(); want[i] != got {
+			t.Errorf("error %d mismatch; expected `%v`, got `%v`", i, want[i], got)
+		}
+	}
+}
+
+func TestModuleCanonicalize(t *testing.T) {
+	// build returns a module with the given functions, numbered global
+	// variables and comdats, added in the given order.
+	build := func(funcs []string, comdats []string) *Module {
+		m := &Module{}
+		for _, name := range comdats {
+			m.ComdatDefs = append(m.ComdatDefs, &ComdatDef{Name: name, Kind: enum.SelectionKindAny})
+		}
+		for _, name := range funcs {
+			m.NewFunc(name, types.Void)
+		}
+		return m
+	}
+	m1 := build([]string{"b", "0", "a", "1", "c"}, []string{"y", "x"})
+	m2 := build([]string{"c", "0", "1", "a", "b"}, []string{"x", "y"})
+	m1.Canonicalize()
+	m2.Canonicalize()
+	want := `$x = comdat any
+
+$y = comdat any
+
+declare void @0()
+
+declare void @1()
+
+declare void @a()
+
+declare void @b()
+
+declare void @c()`
+	for _, m := range []*Module{m1, m2} {
+		if got := strings.TrimSpace(m.Def()); want != got {
+			t.Errorf("module mismatch; expected `%v`, got `%v`", want, got)
+		}
+	}
+}
+
+func TestModuleHoistStructTypes(t *testing.T) {
+	// Structurally identical literal struct types of distinct instances.
+	pair := func() *types.StructType {
+		return types.NewStruct(types.I32, types.I8)
+	}
+	m := &Module{}
+	p := NewParam(types.NewPointer(pair()), "p")
+	f := m.NewFunc("f", pair(), p)
+	entry := NewBlock("entry")
+	x := entry.NewLoad(p)
+	entry.NewRet(x)
+	f.Blocks = []*BasicBlock{entry}
+	// Unique literal struct type.
+	m.NewFunc("g", types.NewStruct(types.I64))
+	want := `define { i32, i8 } @f({ i32, i8 }* %p) {
+entry:
+  %0 = load { i32, i8 }, { i32, i8 }* %p
+  ret { i32, i8 } %0
+}
+
+declare { i64 } @g()`
+	if err := f.AssignIDs(); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(m.Def()); want != got {
+		t.Errorf("module mismatch; expected `%v`, got `%v`", want, got)
+	}
+	m.HoistStructTypes = true
+	want = `%anon.0 = type { i32, i8 }
+
+define %anon.0 @f(%anon.0* %p) {
+entry:
+  %0 = load %anon.0, %anon.0* %p
+  ret %anon.0 %0
+}
+
+declare { i64 } @g()`
+	if got := strings.TrimSpace(m.Def()); want != got {
+		t.Errorf("module mismatch; expected `%v`, got `%v`", want, got)
+	}
+	// Literal struct types of the module are left unchanged.
+	if got := x.Type().String(); got != "{ i32, i8 }" {
+		t.Errorf("type mismatch; expected `{ i32, i8 }`, got `%v`", got)
+	}
+}
+
+func TestModuleHoistStructTypesUses(t *testing.T) {
+	golden := []struct {
+		build func(m *Module)
+		want  string
+	}{
+		// Single use; the alloca element type and result type are printed as
+		// one use.
+		{
+			build: func(m *Module) {
+				f := m.NewFunc("f", types.Void)
+				entry := NewBlock("entry")
+				entry.NewAlloca(types.NewStruct(types.I32, types.I8))
+				entry.NewRet(nil)
+				f.Blocks = []*BasicBlock{entry}
+			},
+			want: `define void @f() {
+entry:
+  %0 = alloca { i32, i8 }
+  ret void
+}`,
+		},
+		// Nested struct types; the struct type of the field is only used in the
+		// hoisted definition of the enclosing struct type.
+		{
+			build: func(m *Module) {
+				pair := func() *types.StructType {
+					return types.NewStruct(types.NewStruct(types.I32), types.I8)
+				}
+				m.NewFunc("f", types.Void, NewParam(pair(), "x"), NewParam(pair(), "y"))
+			},
+			want: `%anon.0 = type { { i32 }, i8 }
+
+declare void @f(%anon.0 %x, %anon.0 %y)`,
+		},
+		// Uses within quoted strings are not counted.
+		{
+			build: func(m *Module) {
+				m.NewGlobalDef("s", NewCharArrayFromString("{ i32 }"))
+				m.NewFunc("f", types.NewStruct(types.I32))
+			},
+			want: `@s = global [7 x i8] c"{ i32 }"
+
+declare { i32 } @f()`,
+		},
+		// Struct types of global variables.
+		{
+			build: func(m *Module) {
+				pair := func() *types.StructType {
+					return types.NewStruct(types.I32, types.I8)
+				}
+				m.NewGlobalDecl("x", pair())
+				m.NewGlobalDef("y", NewStruct(pair(), NewInt(types.I32, 1), NewInt(types.I8, 2)))
+			},
+			want: `%anon.0 = type { i32, i8 }
+
+@x = external global %anon.0
+@y = global %anon.0 { i32 1, i8 2 }`,
+		},
+		// Struct types of array elements of global variables.
+		{
+			build: func(m *Module) {
+				pair := func() *types.StructType {
+					return types.NewStruct(types.I32, types.I8)
+				}
+				zero := NewZeroInitializer(pair())
+				arrayType := types.NewArray(2, pair())
+				m.NewGlobalDef("x", NewArray(arrayType, zero, zero))
+			},
+			want: `%anon.0 = type { i32, i8 }
+
+@x = global [2 x %anon.0] [%anon.0 zeroinitializer, %anon.0 zeroinitializer]`,
+		},
+	}
+	for _, g := range golden {
+		m := &Module{HoistStructTypes: true}
+		g.build(m)
+		for _, f := range m.Funcs {
+			if err := f.AssignIDs(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := strings.TrimSpace(m.Def()); g.want != got {
+			t.Errorf("module mismatch; expected `%v`, got `%v`", g.want, got)
+		}
+	}
+}
+
+// addTestFunc appends a new function declaration with the given name and
+// linkage to the module.
+func addTestFunc(m *Module, name string, linkage enum.Linkage) *Function {
+	f := &Function{GlobalName: name, Sig: types.NewFunc(types.Void), Linkage: linkage}
+	m.Funcs = append(m.Funcs, f)
+	return f
+}
+
+func TestModuleAddGlobalCtor(t *testing.T) {
+	m := &Module{}
+	init1 := m.NewFunc("init1", types.Void)
+	init2 := m.NewFunc("init2", types.Void)
+	x := m.NewGlobalDef("x", NewInt(types.I32, 0))
+	g1 := m.AddGlobalCtor(65535, init1, nil)
+	g2 := m.AddGlobalCtor(101, init2, NewBitCastExpr(x, types.I8Ptr))
+	if g1 != g2 {
+		t.Errorf("global variable mismatch; expected %v to be reused", g1.Ident())
+	}
+	want := "@llvm.global_ctors = appending global [2 x { i32, void ()*, i8* }] [{ i32, void ()*, i8* } { i32 65535, void ()* @init1, i8* null }, { i32, void ()*, i8* } { i32 101, void ()* @init2, i8* bitcast (i32* @x to i8*) }]"
+	if got := g2.Def(); want != got {
+		t.Errorf("global variable mismatch; expected `%v`, got `%v`", want, got)
+	}
+	if want, got := "[2 x { i32, void ()*, i8* }]*", g2.Type().String(); want != got {
+		t.Errorf("global variable type mismatch; expected `%v`, got `%v`", want, got)
+	}
+	if err := g2.Validate(); err != nil {
+		t.Errorf("unexpected error for `%v`; %v", g2.Def(), err)
+	}
+	if len(m.Globals) != 2 {
+		t.Errorf("number of global variables mismatch; expected 2, got %d", len(m.Globals))
+	}
+}
@@ -0,0 +1,132 @@
+package ir
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/llir/l/ir/value"
+)
+
+// === [ JSON ] ================================================================
+
+// MarshalJSON returns a JSON representation of the structure of the module.
+// Functions, basic blocks, instructions and terminators are encoded in order,
+// with operands referenced by identifier.
+//
+// The JSON representation is intended for tooling that post-processes LLVM IR,
+// and does not round-trip back to a module.
+func (m *Module) MarshalJSON() ([]byte, error) {
+	jm := &jsonModule{}
+	for _, g := range m.Globals {
+		jg := &jsonGlobal{
+			Name:        g.Ident(),
+			ContentType: g.ContentType.String(),
+		}
+		if g.Init != nil {
+			jg.Init = g.Init.Ident()
+		}
+		jm.Globals = append(jm.Globals, jg)
+	}
+	for _, f := range m.Funcs {
+		jm.Funcs = append(jm.Funcs, newJSONFunc(f))
+	}
+	return json.Marshal(jm)
+}
+
+// jsonModule is the JSON representation of a module.
+type jsonModule struct {
+	Globals []*jsonGlobal `json:"globals,omitempty"`
+	Funcs   []*jsonFunc   `json:"funcs,omitempty"`
+}
+
+// jsonGlobal is the JSON representation of a global variable.
+type jsonGlobal struct {
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Init        string `json:"init,omitempty"`
+}
+
+// jsonFunc is the JSON representation of a function.
+type jsonFunc struct {
+	Name   string       `json:"name"`
+	Sig    string       `json:"sig"`
+	Params []*jsonParam `json:"params,omitempty"`
+	Blocks []*jsonBlock `json:"blocks,omitempty"`
+}
+
+// jsonParam is the JSON representation of a function parameter.
+type jsonParam struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// jsonBlock is the JSON representation of a basic block.
+type jsonBlock struct {
+	Name  string      `json:"name"`
+	Insts []*jsonInst `json:"insts,omitempty"`
+	Term  *jsonInst   `json:"term,omitempty"`
+}
+
+// jsonInst is the JSON representation of an instruction or terminator.
+type jsonInst struct {
+	Kind     string   `json:"kind"`
+	Name     string   `json:"name,omitempty"`
+	Type     string   `json:"type,omitempty"`
+	Operands []string `json:"operands,omitempty"`
+	Succs    []string `json:"succs,omitempty"`
+}
+
+// newJSONFunc returns the JSON representation of the given function.
+func newJSONFunc(f *Function) *jsonFunc {
+	jf := &jsonFunc{
+		Name: f.Ident(),
+		Sig:  f.Sig.String(),
+	}
+	for _, param := range f.Params {
+		jp := &jsonParam{
+			Name: param.Ident(),
+			Type: param.Type().String(),
+		}
+		jf.Params = append(jf.Params, jp)
+	}
+	for _, block := range f.Blocks {
+		jb := &jsonBlock{Name: block.Ident()}
+		for _, inst := range block.Insts {
+			ji := newJSONInst(inst, inst.Operands())
+			jb.Insts = append(jb.Insts, ji)
+		}
+		if block.Term != nil {
+			jt := newJSONInst(block.Term, block.Term.Operands())
+			for _, succ := range block.Term.Succs() {
+				jt.Succs = append(jt.Succs, succ.Ident())
+			}
+			jb.Term = jt
+		}
+		jf.Blocks = append(jf.Blocks, jb)
+	}
+	return jf
+}
+
+// newJSONInst returns the JSON representation of the given instruction or
+// terminator, with the given operands.
+func newJSONInst(inst interface{}, ops []*value.Value) *jsonInst {
+	ji := &jsonInst{Kind: instKind(inst)}
+	if n, ok := inst.(value.Named); ok && !isVoidValue(n) {
+		ji.Name = n.Ident()
+		ji.Type = n.Type().String()
+	}
+	for _, op := range ops {
+		ji.Operands = append(ji.Operands, (*op).Ident())
+	}
+	return ji
+}
+
+// instKind returns the kind of the given instruction or terminator (e.g.
+// "alloca" for *ir.InstAlloca and "condbr" for *ir.TermCondBr).
+func instKind(inst interface{}) string {
+	name := reflect.TypeOf(inst).Elem().Name()
+	name = strings.TrimPrefix(name, "Inst")
+	name = strings.TrimPrefix(name, "Term")
+	return strings.ToLower(name)
+}
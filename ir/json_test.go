@@ -0,0 +1,50 @@
+package ir
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/llir/l/ir/types"
+)
+
+func TestModuleMarshalJSON(t *testing.T) {
+	p := NewParam(types.I32Ptr, "p")
+	entry := NewBlock("entry")
+	x := entry.NewAlloca(types.I32)
+	x.SetName("x")
+	v := entry.NewLoad(p)
+	v.SetName("v")
+	entry.NewStore(v, x)
+	exit := NewBlock("exit")
+	entry.NewBr(exit)
+	y := exit.NewLoad(x)
+	y.SetName("y")
+	exit.NewRet(y)
+	f := &Function{
+		GlobalName: "f",
+		Sig:        types.NewFunc(types.I32, types.I32Ptr),
+		Params:     []*Param{p},
+		Blocks:     []*BasicBlock{entry, exit},
+	}
+	m := &Module{
+		Globals: []*Global{NewGlobalDef("g", NewInt(types.I32, 42))},
+		Funcs:   []*Function{f},
+	}
+	buf, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("unable to marshal module; %v", err)
+	}
+	want := `{"globals":[{"name":"@g","content_type":"i32","init":"42"}],` +
+		`"funcs":[{"name":"@f","sig":"i32 (i32*)","params":[{"name":"%p","type":"i32*"}],"blocks":[` +
+		`{"name":"%entry","insts":[` +
+		`{"kind":"alloca","name":"%x","type":"i32*"},` +
+		`{"kind":"load","name":"%v","type":"i32","operands":["%p"]},` +
+		`{"kind":"store","operands":["%v","%x"]}],` +
+		`"term":{"kind":"br","succs":["%exit"]}},` +
+		`{"name":"%exit","insts":[` +
+		`{"kind":"load","name":"%y","type":"i32","operands":["%x"]}],` +
+		`"term":{"kind":"ret","operands":["%y"]}}]}]}`
+	if got := string(buf); want != got {
+		t.Errorf("JSON mismatch; expected `%v`, got `%v`", want, got)
+	}
+}
@@ -0,0 +1,176 @@
+package ir
+
+import (
+	"github.com/llir/l/ir/value"
+)
+
+// LivenessInfo records the local values live on entry to and exit from each
+// basic block of a function.
+type LivenessInfo struct {
+	// Values live on entry to each basic block.
+	liveIn map[*BasicBlock]*value.Set
+	// Values live on exit from each basic block.
+	liveOut map[*BasicBlock]*value.Set
+}
+
+// LiveIn returns the set of local values live on entry to the given basic
+// block, in order of definition.
+func (info *LivenessInfo) LiveIn(block *BasicBlock) *value.Set {
+	if s, ok := info.liveIn[block]; ok {
+		return s
+	}
+	return value.NewSet()
+}
+
+// LiveOut returns the set of local values live on exit from the given basic
+// block, in order of definition.
+func (info *LivenessInfo) LiveOut(block *BasicBlock) *value.Set {
+	if s, ok := info.liveOut[block]; ok {
+		return s
+	}
+	return value.NewSet()
+}
+
+// Liveness computes the live-in and live-out sets of local values (function
+// parameters and instruction results) of each basic block of the function,
+// using backward dataflow analysis over the control flow graph.
+//
+// Incoming values of phi instructions are live on exit from the corresponding
+// predecessor basic block, rather than on entry to the basic block of the phi
+// instruction.
+func (f *Function) Liveness() *LivenessInfo {
+	// liveIns records values live on entry to each basic block.
+	liveIns := make(map[*BasicBlock]map[value.Value]bool)
+	// liveOuts records values live on exit from each basic block.
+	liveOuts := make(map[*BasicBlock]map[value.Value]bool)
+	// uses records values used in each basic block before being defined.
+	uses := make(map[*BasicBlock]map[value.Value]bool)
+	// defs records values defined in each basic block.
+	defs := make(map[*BasicBlock]map[value.Value]bool)
+	// phiUses records incoming values of phi instructions, indexed by the basic
+	// block of the phi instruction and predecessor basic block.
+	phiUses := make(map[*BasicBlock]map[*BasicBlock][]value.Value)
+	for _, block := range f.Blocks {
+		use := make(map[value.Value]bool)
+		def := make(map[value.Value]bool)
+		addUses := func(ops []*value.Value) {
+			for _, op := range ops {
+				if isLocalValue(*op) && !def[*op] {
+					use[*op] = true
+				}
+			}
+		}
+		for _, inst := range block.Insts {
+			if phi, ok := inst.(*InstPhi); ok {
+				if phiUses[block] == nil {
+					phiUses[block] = make(map[*BasicBlock][]value.Value)
+				}
+				for _, inc := range phi.Incs {
+					if isLocalValue(inc.X) {
+						phiUses[block][inc.Pred] = append(phiUses[block][inc.Pred], inc.X)
+					}
+				}
+			} else {
+				addUses(inst.Operands())
+			}
+			if v, ok := inst.(value.Value); ok {
+				def[v] = true
+			}
+		}
+		if block.Term != nil {
+			addUses(block.Term.Operands())
+			if v, ok := block.Term.(value.Value); ok {
+				def[v] = true
+			}
+		}
+		uses[block] = use
+		defs[block] = def
+		liveIns[block] = make(map[value.Value]bool)
+		liveOuts[block] = make(map[value.Value]bool)
+	}
+	// Iterate until a fixed point is reached, visiting basic blocks in reverse
+	// order to speed up convergence of the backward analysis.
+	for changed := true; changed; {
+		changed = false
+		for i := len(f.Blocks) - 1; i >= 0; i-- {
+			block := f.Blocks[i]
+			liveOut := liveOuts[block]
+			liveIn := liveIns[block]
+			if block.Term != nil {
+				for _, succ := range block.Term.Succs() {
+					for v := range liveIns[succ] {
+						if !liveOut[v] {
+							liveOut[v] = true
+							changed = true
+						}
+					}
+					for _, v := range phiUses[succ][block] {
+						if !liveOut[v] {
+							liveOut[v] = true
+							changed = true
+						}
+					}
+				}
+			}
+			for v := range uses[block] {
+				if !liveIn[v] {
+					liveIn[v] = true
+					changed = true
+				}
+			}
+			for v := range liveOut {
+				if !defs[block][v] && !liveIn[v] {
+					liveIn[v] = true
+					changed = true
+				}
+			}
+		}
+	}
+	// Record live values in order of definition, to keep the output
+	// deterministic.
+	var locals []value.Value
+	for _, param := range f.Params {
+		locals = append(locals, param)
+	}
+	for _, block := range f.Blocks {
+		for _, inst := range block.Insts {
+			if v, ok := inst.(value.Value); ok {
+				locals = append(locals, v)
+			}
+		}
+		if v, ok := block.Term.(value.Value); ok {
+			locals = append(locals, v)
+		}
+	}
+	info := &LivenessInfo{
+		liveIn:  make(map[*BasicBlock]*value.Set),
+		liveOut: make(map[*BasicBlock]*value.Set),
+	}
+	for _, block := range f.Blocks {
+		liveIn, liveOut := value.NewSet(), value.NewSet()
+		for _, v := range locals {
+			if liveIns[block][v] {
+				liveIn.Add(v)
+			}
+			if liveOuts[block][v] {
+				liveOut.Add(v)
+			}
+		}
+		info.liveIn[block] = liveIn
+		info.liveOut[block] = liveOut
+	}
+	return info
+}
+
+// ### [ Helper functions ] ####################################################
+
+// isLocalValue reports whether the given value is a local value defined within
+// a function; i.e. a function parameter or the result of an instruction or
+// terminator.
+func isLocalValue(v value.Value) bool {
+	switch v.(type) {
+	case *Param, Instruction, *TermInvoke, *TermCallBr, *TermCatchSwitch:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package ir
+
+import (
+	"testing"
+
+	"github.com/llir/l/ir/enum"
+	"github.com/llir/l/ir/types"
+	"github.com/llir/l/ir/value"
+)
+
+func TestLiveness(t *testing.T) {
+	// entry:
+	//    br label %loop
+	// loop:
+	//    %i = phi i32 [ 0, %entry ], [ %next, %loop ]
+	//    %next = add i32 %i, %step
+	//    %cond = icmp slt i32 %next, 10
+	//    br i1 %cond, label %loop, label %exit
+	// exit:
+	//    ret i32 %next
+	step := NewParam(types.I32, "step")
+	entry := NewBlock("entry")
+	loop := NewBlock("loop")
+	exit := NewBlock("exit")
+	entry.NewBr(loop)
+	i := loop.NewPhi()
+	i.SetName("i")
+	next := loop.NewAdd(i, step)
+	next.SetName("next")
+	i.Incs = []*Incoming{NewIncoming(NewInt(types.I32, 0), entry), NewIncoming(next, loop)}
+	cond := loop.NewICmp(enum.IPredSLT, next, NewInt(types.I32, 10))
+	cond.SetName("cond")
+	loop.NewCondBr(cond, loop, exit)
+	exit.NewRet(next)
+	f := &Function{
+		GlobalName: "f",
+		Sig:        types.NewFunc(types.I32, types.I32),
+		Params:     []*Param{step},
+		Blocks:     []*BasicBlock{entry, loop, exit},
+	}
+	info := f.Liveness()
+	// The induction value is live across the back-edge.
+	if !info.LiveOut(loop).Has(next) {
+		t.Errorf("expected %v to be live-out of %v", next.Ident(), loop.Ident())
+	}
+	// The phi result is defined in the loop header, and thus not live-in.
+	if info.LiveIn(loop).Has(i) {
+		t.Errorf("expected %v not to be live-in of %v", i.Ident(), loop.Ident())
+	}
+	// Loop invariant parameter is live throughout the loop.
+	if !info.LiveIn(loop).Has(step) || !info.LiveOut(loop).Has(step) || !info.LiveOut(entry).Has(step) {
+		t.Errorf("expected %v to be live throughout %v", step.Ident(), loop.Ident())
+	}
+	if info.LiveOut(exit).Has(next) || !info.LiveIn(exit).Has(next) {
+		t.Errorf("expected %v to be live-in but not live-out of %v", next.Ident(), exit.Ident())
+	}
+	if info.LiveOut(loop).Has(cond) {
+		t.Errorf("expected %v not to be live-out of %v", cond.Ident(), loop.Ident())
+	}
+	if info.LiveIn(entry).Len() != 1 {
+		t.Errorf("number of live-in values of %v mismatch; expected 1, got %d", entry.Ident(), info.LiveIn(entry).Len())
+	}
+	// Live values are ordered by definition.
+	want := []value.Value{step, next}
+	got := info.LiveOut(loop).Slice()
+	if len(want) != len(got) {
+		t.Fatalf("number of live-out values of %v mismatch; expected %d, got %d", loop.Ident(), len(want), len(got))
+	}
+	for j := range want {
+		if want[j] != got[j] {
+			t.Errorf("live-out value %d of %v mismatch; expected %v, got %v", j, loop.Ident(), want[j].Ident(), got[j].Ident())
+		}
+	}
+}
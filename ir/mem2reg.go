@@ -0,0 +1,148 @@
+package ir
+
+import (
+	"github.com/llir/l/ir/value"
+)
+
+// PromoteTrivialAllocas promotes alloca instructions, which are only used by
+// non-volatile and non-atomic load and store instructions of the allocated type
+// within a single basic block, to SSA values. Loads are replaced by the last
+// value stored to the alloca (or undef if no value has been stored), and the
+// alloca together with its loads and stores is removed. The number of promoted
+// allocas is returned.
+//
+// Allocas whose address escapes (e.g. used by a getelementptr, call or cmpxchg
+// instruction, or stored to memory) are left intact, as are allocas loaded
+// before the first store in a basic block which is part of a loop.
+func (f *Function) PromoteTrivialAllocas() int {
+	promoted := make(map[*InstAlloca]bool)
+	for _, block := range f.Blocks {
+		for _, inst := range block.Insts {
+			alloca, ok := inst.(*InstAlloca)
+			if !ok {
+				continue
+			}
+			if useBlock, ok := f.trivialAllocaUseBlock(alloca); ok {
+				promoteAlloca(f, alloca, useBlock)
+				promoted[alloca] = true
+			}
+		}
+	}
+	if len(promoted) > 0 {
+		// Remove promoted allocas.
+		for _, block := range f.Blocks {
+			var insts []Instruction
+			for _, inst := range block.Insts {
+				if alloca, ok := inst.(*InstAlloca); ok && promoted[alloca] {
+					continue
+				}
+				insts = append(insts, inst)
+			}
+			block.Insts = insts
+		}
+	}
+	return len(promoted)
+}
+
+// ### [ Helper functions ] ####################################################
+
+// trivialAllocaUseBlock returns the basic block containing all uses of the
+// given alloca instruction. The boolean return value is false if the alloca is
+// not trivially promotable; i.e. if it is used by anything other than simple
+// loads and stores of the allocated type, used in more than one basic block, or
+// loaded before the first store in a basic block which is part of a loop (as
+// the load would observe the value stored in the previous iteration).
+func (f *Function) trivialAllocaUseBlock(alloca *InstAlloca) (*BasicBlock, bool) {
+	if alloca.NElems != nil {
+		return nil, false
+	}
+	var useBlock *BasicBlock
+	stored, loadBeforeStore := false, false
+	for _, block := range f.Blocks {
+		for _, inst := range block.Insts {
+			used := false
+			for _, op := range inst.Operands() {
+				if *op == value.Value(alloca) {
+					used = true
+				}
+			}
+			if !used {
+				continue
+			}
+			switch inst := inst.(type) {
+			case *InstLoad:
+				if inst.Volatile || inst.Atomic || !inst.Type().Equal(alloca.ElemType) {
+					return nil, false
+				}
+				if !stored {
+					loadBeforeStore = true
+				}
+			case *InstStore:
+				// Storing the address of the alloca escapes it.
+				if inst.Src == value.Value(alloca) || inst.Volatile || inst.Atomic || !inst.Src.Type().Equal(alloca.ElemType) {
+					return nil, false
+				}
+				stored = true
+			default:
+				return nil, false
+			}
+			if useBlock != nil && useBlock != block {
+				return nil, false
+			}
+			useBlock = block
+		}
+		if block.Term != nil {
+			for _, op := range block.Term.Operands() {
+				if *op == value.Value(alloca) {
+					return nil, false
+				}
+			}
+		}
+	}
+	if useBlock != nil && loadBeforeStore && inLoop(useBlock) {
+		return nil, false
+	}
+	return useBlock, true
+}
+
+// inLoop reports whether the given basic block is reachable from any of its
+// successor basic blocks.
+func inLoop(block *BasicBlock) bool {
+	if block.Term == nil {
+		return false
+	}
+	for _, succ := range block.Term.Succs() {
+		if reachableBlocks(succ)[block] {
+			return true
+		}
+	}
+	return false
+}
+
+// promoteAlloca promotes the given alloca instruction to SSA values, replacing
+// loads with the last stored value within the basic block containing all uses
+// of the alloca.
+func promoteAlloca(f *Function, alloca *InstAlloca, useBlock *BasicBlock) {
+	if useBlock == nil {
+		// Unused alloca.
+		return
+	}
+	var cur value.Value = NewUndef(alloca.ElemType)
+	var insts []Instruction
+	for _, inst := range useBlock.Insts {
+		switch inst := inst.(type) {
+		case *InstLoad:
+			if inst.Src == value.Value(alloca) {
+				f.ReplaceAll(inst, cur)
+				continue
+			}
+		case *InstStore:
+			if inst.Dst == value.Value(alloca) {
+				cur = inst.Src
+				continue
+			}
+		}
+		insts = append(insts, inst)
+	}
+	useBlock.Insts = insts
+}
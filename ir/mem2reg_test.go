@@ -0,0 +1,95 @@
+package ir
+
+import (
+	"testing"
+
+	"github.com/llir/l/ir/enum"
+	"github.com/llir/l/ir/types"
+)
+
+func TestPromoteTrivialAllocas(t *testing.T) {
+	entry := NewBlock("entry")
+	counter := entry.NewAlloca(types.I32)
+	entry.NewStore(NewInt(types.I32, 0), counter)
+	v1 := entry.NewLoad(counter)
+	inc := entry.NewAdd(v1, NewInt(types.I32, 1))
+	entry.NewStore(inc, counter)
+	v2 := entry.NewLoad(counter)
+	ret := entry.NewRet(v2)
+	f := &Function{
+		GlobalName: "f",
+		Sig:        types.NewFunc(types.I32),
+		Blocks:     []*BasicBlock{entry},
+	}
+	if n := f.PromoteTrivialAllocas(); n != 1 {
+		t.Errorf("number of promoted allocas mismatch; expected 1, got %d", n)
+	}
+	// Only the add instruction remains.
+	if len(entry.Insts) != 1 || entry.Insts[0] != inc {
+		t.Fatalf("instructions mismatch; expected only add instruction, got %d instructions", len(entry.Insts))
+	}
+	if want, got := "i32 0", inc.X.String(); want != got {
+		t.Errorf("add operand mismatch; expected `%v`, got `%v`", want, got)
+	}
+	if ret.X != inc {
+		t.Errorf("return value mismatch; expected add instruction, got %v", ret.X)
+	}
+}
+
+func TestPromoteTrivialAllocasEscape(t *testing.T) {
+	entry := NewBlock("entry")
+	arr := entry.NewAlloca(types.NewArray(2, types.I32))
+	entry.NewStore(NewZeroInitializer(arr.ElemType), arr)
+	entry.NewGetElementPtr(arr.ElemType, arr, NewInt(types.I64, 0), NewInt(types.I64, 1))
+	entry.NewRet(nil)
+	f := &Function{
+		GlobalName: "f",
+		Sig:        types.NewFunc(types.Void),
+		Blocks:     []*BasicBlock{entry},
+	}
+	if n := f.PromoteTrivialAllocas(); n != 0 {
+		t.Errorf("number of promoted allocas mismatch; expected 0, got %d", n)
+	}
+	if len(entry.Insts) != 3 {
+		t.Errorf("number of instructions mismatch; expected 3, got %d", len(entry.Insts))
+	}
+}
+
+func TestPromoteTrivialAllocasLoop(t *testing.T) {
+	// The load in the loop observes the value stored in the previous iteration.
+	i := NewParam(types.I32, "i")
+	entry := NewBlock("entry")
+	loop := NewBlock("loop")
+	p := entry.NewAlloca(types.I32)
+	entry.NewBr(loop)
+	x := loop.NewLoad(p)
+	loop.NewStore(i, p)
+	cond := loop.NewICmp(enum.IPredEQ, x, i)
+	exit := NewBlock("exit")
+	loop.NewCondBr(cond, exit, loop)
+	exit.NewRet(x)
+	f := NewFunction("f", types.I32, i)
+	f.Blocks = []*BasicBlock{entry, loop, exit}
+	if n := f.PromoteTrivialAllocas(); n != 0 {
+		t.Errorf("number of promoted allocas mismatch; expected 0, got %d", n)
+	}
+	if len(loop.Insts) != 3 {
+		t.Errorf("number of instructions mismatch; expected 3, got %d", len(loop.Insts))
+	}
+}
+
+func TestPromoteTrivialAllocasTypeMismatch(t *testing.T) {
+	// Loads of a different type than the stored value are not promoted.
+	entry := NewBlock("entry")
+	p := entry.NewAlloca(types.I64)
+	entry.NewStore(NewInt(types.I64, 1), p)
+	x := NewLoad(p)
+	x.Typ = types.I32
+	entry.Insts = append(entry.Insts, x)
+	entry.NewRet(x)
+	f := NewFunction("f", types.I32)
+	f.Blocks = []*BasicBlock{entry}
+	if n := f.PromoteTrivialAllocas(); n != 0 {
+		t.Errorf("number of promoted allocas mismatch; expected 0, got %d", n)
+	}
+}
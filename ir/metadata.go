@@ -2,6 +2,686 @@
 
 package ir
 
-// TODO: implement.
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/llir/l/internal/enc"
+	"github.com/llir/l/ir/types"
+	"github.com/llir/l/ir/value"
+)
+
+// === [ Metadata ] ============================================================
+
+// Metadata is an LLVM IR metadata value.
+//
+// A Metadata has one of the following underlying types.
+//
+//    *ir.MDTuple      // https://godoc.org/github.com/llir/l/ir#MDTuple
+//    ir.MDString      // https://godoc.org/github.com/llir/l/ir#MDString
+//    *ir.MDValue      // https://godoc.org/github.com/llir/l/ir#MDValue
+//    *ir.DILocation   // https://godoc.org/github.com/llir/l/ir#DILocation
+//    *ir.DIExpression // https://godoc.org/github.com/llir/l/ir#DIExpression
+type Metadata interface {
+	// String returns the LLVM syntax representation of the metadata as used
+	// when referenced by other metadata or metadata attachments.
+	fmt.Stringer
+	// isMetadata ensures that only metadata values can be assigned to the
+	// ir.Metadata interface.
+	isMetadata()
+}
+
+// MDNode is a metadata node, which may be assigned a metadata ID and
+// referenced by it (e.g. "!42").
+//
+// A MDNode has one of the following underlying types.
+//
+//    *ir.MDTuple      // https://godoc.org/github.com/llir/l/ir#MDTuple
+//    *ir.DILocation   // https://godoc.org/github.com/llir/l/ir#DILocation
+//    *ir.DIExpression // https://godoc.org/github.com/llir/l/ir#DIExpression
+type MDNode interface {
+	Metadata
+	// Ident returns the identifier associated with the metadata node.
+	Ident() string
+	// ID returns the metadata ID of the metadata node; or -1 if not assigned.
+	ID() int64
+	// SetID sets the metadata ID of the metadata node.
+	SetID(id int64)
+	// Def returns the LLVM syntax representation of the metadata node
+	// definition.
+	Def() string
+	// MDFields returns the metadata operands of the metadata node.
+	MDFields() []Metadata
+}
+
+// --- [ Metadata tuples ] -----------------------------------------------------
+
+// MDTuple is a generic metadata node (e.g. "!{i32 1, !"foo"}").
+type MDTuple struct {
+	// Metadata ID; or -1 if not yet assigned.
+	MetadataID int64
+	// Metadata fields; nil fields are rendered as null.
+	Fields []Metadata
+
+	// extra.
+
+	// (optional) Distinct metadata node.
+	Distinct bool
+}
+
+// NewMDTuple returns a new metadata tuple based on the given fields.
+func NewMDTuple(fields ...Metadata) *MDTuple {
+	return &MDTuple{MetadataID: -1, Fields: fields}
+}
+
+// String returns the LLVM syntax representation of the metadata tuple.
+func (md *MDTuple) String() string {
+	return md.Ident()
+}
+
+// Ident returns the identifier associated with the metadata tuple. Metadata
+// tuples without an assigned metadata ID are rendered inline.
+func (md *MDTuple) Ident() string {
+	if md.MetadataID < 0 {
+		if isCyclicMD(md) {
+			panic(fmt.Errorf("invalid metadata tuple; cyclic metadata nodes must be assigned metadata IDs before printing (see Module.AssignMetadataIDs)"))
+		}
+		return md.Def()
+	}
+	return enc.Metadata(fmt.Sprint(md.MetadataID))
+}
+
+// ID returns the metadata ID of the metadata tuple; or -1 if not assigned.
+func (md *MDTuple) ID() int64 {
+	return md.MetadataID
+}
+
+// SetID sets the metadata ID of the metadata tuple.
+func (md *MDTuple) SetID(id int64) {
+	md.MetadataID = id
+}
+
+// Def returns the LLVM syntax representation of the metadata tuple definition.
+func (md *MDTuple) Def() string {
+	// OptDistinct "!" "{" MDFields "}"
+	buf := &strings.Builder{}
+	if md.Distinct {
+		buf.WriteString("distinct ")
+	}
+	buf.WriteString("!{")
+	for i, field := range md.Fields {
+		if i != 0 {
+			buf.WriteString(", ")
+		}
+		if field == nil {
+			buf.WriteString("null")
+		} else {
+			buf.WriteString(field.String())
+		}
+	}
+	buf.WriteString("}")
+	return buf.String()
+}
+
+// MDFields returns the metadata operands of the metadata tuple.
+func (md *MDTuple) MDFields() []Metadata {
+	return md.Fields
+}
+
+// --- [ Metadata strings ] ----------------------------------------------------
+
+// MDString is a metadata string (e.g. "!"foo"").
+type MDString string
+
+// String returns the LLVM syntax representation of the metadata string.
+func (md MDString) String() string {
+	// "!" StringLit
+	return "!" + quote(string(md))
+}
+
+// --- [ Metadata values ] -----------------------------------------------------
+
+// MDValue is an LLVM IR value used as metadata (e.g. "i32 42").
+type MDValue struct {
+	// Value.
+	Value value.Value
+}
+
+// NewMDValue returns a new metadata value based on the given value.
+func NewMDValue(v value.Value) *MDValue {
+	return &MDValue{Value: v}
+}
+
+// String returns the LLVM syntax representation of the metadata value.
+func (md *MDValue) String() string {
+	// Type Value
+	return md.Value.String()
+}
+
+// --- [ Metadata as values ] --------------------------------------------------
+
+// MetadataValue is metadata used as an LLVM IR value (e.g. "metadata !42"), as
+// passed as arguments to intrinsic functions.
+type MetadataValue struct {
+	// Metadata.
+	Metadata Metadata
+}
+
+// NewMetadataValue returns a new metadata as value based on the given metadata.
+func NewMetadataValue(md Metadata) *MetadataValue {
+	return &MetadataValue{Metadata: md}
+}
+
+// String returns the LLVM syntax representation of the metadata as value as a
+// type-value pair.
+func (v *MetadataValue) String() string {
+	return fmt.Sprintf("%v %v", v.Type(), v.Ident())
+}
+
+// Type returns the type of the metadata as value.
+func (v *MetadataValue) Type() types.Type {
+	return types.Metadata
+}
+
+// Ident returns the identifier associated with the metadata as value.
+func (v *MetadataValue) Ident() string {
+	return v.Metadata.String()
+}
+
+// --- [ Debug locations ] -----------------------------------------------------
+
+// DILocation is a debug location metadata node (e.g. "!DILocation(line: 2,
+// column: 8, scope: !3)"), as attached to instructions and terminators by !dbg
+// metadata attachments.
+type DILocation struct {
+	// Metadata ID; or -1 if not yet assigned.
+	MetadataID int64
+	// Source line number.
+	Line int64
+	// Scope of the debug location.
+	Scope MDNode
+
+	// extra.
+
+	// (optional) Source column number; zero if not present.
+	Column int64
+	// (optional) Debug location of the call site this location was inlined
+	// at; nil if not present.
+	InlinedAt MDNode
+	// (optional) Distinct metadata node.
+	Distinct bool
+}
+
+// NewDILocation returns a new debug location based on the given source line
+// and column numbers and scope.
+func NewDILocation(line, column int64, scope MDNode) *DILocation {
+	return &DILocation{MetadataID: -1, Line: line, Column: column, Scope: scope}
+}
+
+// String returns the LLVM syntax representation of the debug location.
+func (md *DILocation) String() string {
+	return md.Ident()
+}
+
+// Ident returns the identifier associated with the debug location. Debug
+// locations without an assigned metadata ID are rendered inline.
+func (md *DILocation) Ident() string {
+	if md.MetadataID < 0 {
+		if isCyclicMD(md) {
+			panic(fmt.Errorf("invalid debug location; cyclic metadata nodes must be assigned metadata IDs before printing (see Module.AssignMetadataIDs)"))
+		}
+		return md.Def()
+	}
+	return enc.Metadata(fmt.Sprint(md.MetadataID))
+}
+
+// ID returns the metadata ID of the debug location; or -1 if not assigned.
+func (md *DILocation) ID() int64 {
+	return md.MetadataID
+}
+
+// SetID sets the metadata ID of the debug location.
+func (md *DILocation) SetID(id int64) {
+	md.MetadataID = id
+}
+
+// Def returns the LLVM syntax representation of the debug location definition.
+func (md *DILocation) Def() string {
+	// OptDistinct "!DILocation" "(" DILocationFields ")"
+	buf := &strings.Builder{}
+	if md.Distinct {
+		buf.WriteString("distinct ")
+	}
+	fmt.Fprintf(buf, "!DILocation(line: %d", md.Line)
+	if md.Column != 0 {
+		fmt.Fprintf(buf, ", column: %d", md.Column)
+	}
+	fmt.Fprintf(buf, ", scope: %v", md.Scope.Ident())
+	if md.InlinedAt != nil {
+		fmt.Fprintf(buf, ", inlinedAt: %v", md.InlinedAt.Ident())
+	}
+	buf.WriteString(")")
+	return buf.String()
+}
+
+// MDFields returns the metadata operands of the debug location.
+func (md *DILocation) MDFields() []Metadata {
+	fields := []Metadata{md.Scope}
+	if md.InlinedAt != nil {
+		fields = append(fields, md.InlinedAt)
+	}
+	return fields
+}
+
+// --- [ Debug expressions ] ---------------------------------------------------
+
+// DWARF expression operation codes of debug expressions.
+const (
+	DwOpDeref        int64 = 0x06   // DW_OP_deref
+	DwOpConstu       int64 = 0x10   // DW_OP_constu
+	DwOpConsts       int64 = 0x11   // DW_OP_consts
+	DwOpMinus        int64 = 0x1C   // DW_OP_minus
+	DwOpPlus         int64 = 0x22   // DW_OP_plus
+	DwOpPlusUconst   int64 = 0x23   // DW_OP_plus_uconst
+	DwOpStackValue   int64 = 0x9F   // DW_OP_stack_value
+	DwOpLLVMFragment int64 = 0x1000 // DW_OP_LLVM_fragment
+)
+
+// dwOps maps from DWARF expression operation codes to their names and number
+// of operands.
+var dwOps = map[int64]struct {
+	name      string
+	noperands int
+}{
+	0x03:   {"DW_OP_addr", 1},
+	0x06:   {"DW_OP_deref", 0},
+	0x10:   {"DW_OP_constu", 1},
+	0x11:   {"DW_OP_consts", 1},
+	0x12:   {"DW_OP_dup", 0},
+	0x16:   {"DW_OP_swap", 0},
+	0x18:   {"DW_OP_xderef", 0},
+	0x1A:   {"DW_OP_and", 0},
+	0x1B:   {"DW_OP_div", 0},
+	0x1C:   {"DW_OP_minus", 0},
+	0x1D:   {"DW_OP_mod", 0},
+	0x1E:   {"DW_OP_mul", 0},
+	0x1F:   {"DW_OP_neg", 0},
+	0x20:   {"DW_OP_not", 0},
+	0x21:   {"DW_OP_or", 0},
+	0x22:   {"DW_OP_plus", 0},
+	0x23:   {"DW_OP_plus_uconst", 1},
+	0x24:   {"DW_OP_shl", 0},
+	0x25:   {"DW_OP_shr", 0},
+	0x26:   {"DW_OP_shra", 0},
+	0x27:   {"DW_OP_xor", 0},
+	0x94:   {"DW_OP_deref_size", 1},
+	0x96:   {"DW_OP_nop", 0},
+	0x97:   {"DW_OP_push_object_address", 0},
+	0x9F:   {"DW_OP_stack_value", 0},
+	0x1000: {"DW_OP_LLVM_fragment", 2},
+	0x1001: {"DW_OP_LLVM_convert", 2},
+	0x1002: {"DW_OP_LLVM_tag_offset", 1},
+	0x1003: {"DW_OP_LLVM_entry_value", 1},
+	0x1004: {"DW_OP_LLVM_implicit_pointer", 0},
+	0x1005: {"DW_OP_LLVM_arg", 1},
+}
+
+// DIExpression is a debug expression metadata node (e.g.
+// "!DIExpression(DW_OP_plus_uconst, 4)"), describing how to compute the
+// location or value of a variable from the operand of a debug intrinsic call.
+type DIExpression struct {
+	// Metadata ID; or -1 if not yet assigned.
+	MetadataID int64
+	// DWARF expression operation codes, each followed by its operands.
+	Ops []int64
+}
+
+// NewDIExpression returns a new debug expression based on the given DWARF
+// expression operation codes and operands.
+func NewDIExpression(ops ...int64) *DIExpression {
+	return &DIExpression{MetadataID: -1, Ops: ops}
+}
+
+// NewDIExpressionOffset returns a new debug expression adding the given
+// constant offset to the location of a variable (e.g.
+// "!DIExpression(DW_OP_plus_uconst, 4)"). Negative offsets are subtracted (e.g.
+// "!DIExpression(DW_OP_constu, 4, DW_OP_minus)"), and a zero offset results in
+// an empty expression.
+func NewDIExpressionOffset(offset int64) *DIExpression {
+	switch {
+	case offset > 0:
+		return NewDIExpression(DwOpPlusUconst, offset)
+	case offset < 0:
+		return NewDIExpression(DwOpConstu, -offset, DwOpMinus)
+	}
+	return NewDIExpression()
+}
+
+// NewDIExpressionFragment returns a new debug expression describing a fragment
+// of a variable, located at the given bit offset and of the given bit size
+// (e.g. "!DIExpression(DW_OP_LLVM_fragment, 0, 32)").
+func NewDIExpressionFragment(offset, size int64) *DIExpression {
+	return NewDIExpression(DwOpLLVMFragment, offset, size)
+}
+
+// String returns the LLVM syntax representation of the debug expression.
+func (md *DIExpression) String() string {
+	return md.Ident()
+}
+
+// Ident returns the identifier associated with the debug expression. Debug
+// expressions without an assigned metadata ID are rendered inline.
+func (md *DIExpression) Ident() string {
+	if md.MetadataID < 0 {
+		return md.Def()
+	}
+	return enc.Metadata(fmt.Sprint(md.MetadataID))
+}
+
+// ID returns the metadata ID of the debug expression; or -1 if not assigned.
+func (md *DIExpression) ID() int64 {
+	return md.MetadataID
+}
+
+// SetID sets the metadata ID of the debug expression.
+func (md *DIExpression) SetID(id int64) {
+	md.MetadataID = id
+}
+
+// Def returns the LLVM syntax representation of the debug expression
+// definition.
+func (md *DIExpression) Def() string {
+	// "!DIExpression" "(" DIExpressionFields ")"
+	buf := &strings.Builder{}
+	buf.WriteString("!DIExpression(")
+	for i := 0; i < len(md.Ops); i++ {
+		if i != 0 {
+			buf.WriteString(", ")
+		}
+		op, ok := dwOps[md.Ops[i]]
+		if !ok {
+			panic(fmt.Errorf("support for DWARF expression operation code 0x%X not yet implemented", md.Ops[i]))
+		}
+		buf.WriteString(op.name)
+		for j := 0; j < op.noperands && i+1 < len(md.Ops); j++ {
+			i++
+			fmt.Fprintf(buf, ", %d", md.Ops[i])
+		}
+	}
+	buf.WriteString(")")
+	return buf.String()
+}
+
+// MDFields returns the metadata operands of the debug expression.
+func (md *DIExpression) MDFields() []Metadata {
+	return nil
+}
+
+// isMetadata ensures that only metadata values can be assigned to the
+// ir.Metadata interface.
+func (*MDTuple) isMetadata()      {}
+func (MDString) isMetadata()      {}
+func (*MDValue) isMetadata()      {}
+func (*DILocation) isMetadata()   {}
+func (*DIExpression) isMetadata() {}
+
+// --- [ Named metadata ] ------------------------------------------------------
+
+// NamedMetadataDef is a named metadata definition (e.g. "!llvm.ident =
+// !{!0}").
+type NamedMetadataDef struct {
+	// Metadata name (without '!' prefix).
+	Name string
+	// Metadata nodes.
+	Nodes []MDNode
+}
+
+// Def returns the LLVM syntax representation of the named metadata definition.
+func (def *NamedMetadataDef) Def() string {
+	// MetadataName "=" "!" "{" MetadataNodes "}"
+	buf := &strings.Builder{}
+	fmt.Fprintf(buf, "%s = !{", enc.Metadata(def.Name))
+	for i, node := range def.Nodes {
+		if i != 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(node.Ident())
+	}
+	buf.WriteString("}")
+	return buf.String()
+}
+
+// --- [ Metadata attachments ] ------------------------------------------------
+
+// MetadataAttachment is a metadata attachment of an instruction or terminator
+// (e.g. "!dbg !42").
 type MetadataAttachment struct {
+	// Metadata attachment name (without '!' prefix).
+	Name string
+	// Metadata node.
+	Node MDNode
+}
+
+// String returns the LLVM syntax representation of the metadata attachment.
+func (md MetadataAttachment) String() string {
+	// MetadataName MDNode
+	return fmt.Sprintf("%s %s", enc.Metadata(md.Name), md.Node.Ident())
+}
+
+// --- [ Branch weights ] ------------------------------------------------------
+
+// NewBranchWeights returns a new branch weights metadata tuple based on the
+// given weights of successor basic blocks (e.g. "!{!"branch_weights", i32 100,
+// i32 1}"), as attached to terminators by !prof metadata attachments.
+func NewBranchWeights(weights ...uint32) *MDTuple {
+	fields := []Metadata{MDString("branch_weights")}
+	for _, weight := range weights {
+		fields = append(fields, NewMDValue(NewInt(types.I32, int64(weight))))
+	}
+	return NewMDTuple(fields...)
+}
+
+// --- [ Metadata IDs ] --------------------------------------------------------
+
+// AssignMetadataIDs assigns metadata IDs to the distinct metadata nodes of the
+// module, and records their definitions in MetadataDefs.
+//
+// Metadata nodes are numbered in order of first reference; named metadata
+// definitions are visited first, followed by the metadata attachments and
+// metadata operands (e.g. arguments of debug intrinsic calls) of the
+// instructions and terminators of each function. Metadata nodes are visited at
+// most once, which ensures that cyclic references terminate.
+//
+// Debug expressions are not assigned metadata IDs, and are rendered inline
+// (e.g. "metadata !DIExpression()"), as done by LLVM.
+func (m *Module) AssignMetadataIDs() {
+	m.MetadataDefs = nil
+	visited := make(map[MDNode]bool)
+	var visit func(md Metadata)
+	visit = func(md Metadata) {
+		if expr, ok := md.(*DIExpression); ok {
+			expr.SetID(-1)
+			return
+		}
+		node, ok := md.(MDNode)
+		if !ok || visited[node] {
+			return
+		}
+		visited[node] = true
+		node.SetID(int64(len(m.MetadataDefs)))
+		m.MetadataDefs = append(m.MetadataDefs, node)
+		for _, field := range node.MDFields() {
+			visit(field)
+		}
+	}
+	for _, def := range m.NamedMetadataDefs {
+		for _, node := range def.Nodes {
+			visit(node)
+		}
+	}
+	for _, f := range m.Funcs {
+		for _, block := range f.Blocks {
+			for _, inst := range block.Insts {
+				for _, md := range canonicalMetadata(*metadataAttachments(inst)) {
+					visit(md.Node)
+				}
+				for _, op := range inst.Operands() {
+					if v, ok := (*op).(*MetadataValue); ok {
+						visit(v.Metadata)
+					}
+				}
+			}
+			if block.Term != nil {
+				for _, md := range canonicalMetadata(*metadataAttachments(block.Term)) {
+					visit(md.Node)
+				}
+			}
+		}
+	}
+}
+
+// ### [ Helper functions ] ####################################################
+
+// isCyclicMD reports whether the given metadata node is reachable from itself
+// through metadata nodes without assigned metadata IDs; i.e. whether rendering
+// the metadata node inline would not terminate.
+func isCyclicMD(node MDNode) bool {
+	visited := make(map[MDNode]bool)
+	var reaches func(md Metadata) bool
+	reaches = func(md Metadata) bool {
+		n, ok := md.(MDNode)
+		if !ok || n.ID() >= 0 {
+			return false
+		}
+		if n == node {
+			return true
+		}
+		if visited[n] {
+			return false
+		}
+		visited[n] = true
+		for _, field := range n.MDFields() {
+			if reaches(field) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, field := range node.MDFields() {
+		if reaches(field) {
+			return true
+		}
+	}
+	return false
+}
+
+// setMetadataAttachment sets the metadata attachment with the given name in the
+// list of metadata attachments, replacing any existing metadata attachment of
+// the same name.
+func setMetadataAttachment(mds *[]MetadataAttachment, name string, node MDNode) {
+	for i, md := range *mds {
+		if md.Name == name {
+			(*mds)[i].Node = node
+			return
+		}
+	}
+	*mds = append(*mds, MetadataAttachment{Name: name, Node: node})
+}
+
+// metadataKindIDs maps from the names of fixed metadata kinds to their metadata
+// kind IDs, as defined by LLVM.
+var metadataKindIDs = map[string]int{
+	"dbg":                           0,
+	"tbaa":                          1,
+	"prof":                          2,
+	"fpmath":                        3,
+	"range":                         4,
+	"tbaa.struct":                   5,
+	"invariant.load":                6,
+	"alias.scope":                   7,
+	"noalias":                       8,
+	"nontemporal":                   9,
+	"llvm.mem.parallel_loop_access": 10,
+	"nonnull":                       11,
+	"dereferenceable":               12,
+	"dereferenceable_or_null":       13,
+	"make.implicit":                 14,
+	"unpredictable":                 15,
+	"invariant.group":               16,
+	"align":                         17,
+	"llvm.loop":                     18,
+	"type":                          19,
+	"section_prefix":                20,
+	"absolute_symbol":               21,
+	"associated":                    22,
+	"callees":                       23,
+	"irr_loop":                      24,
+	"llvm.access.group":             25,
+	"callback":                      26,
+	"llvm.preserve.access.index":    27,
+	"vcall_visibility":              28,
+	"noundef":                       29,
+	"annotation":                    30,
+	"nosanitize":                    31,
+	"func_sanitize":                 32,
+	"exclude":                       33,
+	"memprof":                       34,
+	"callsite":                      35,
+	"kcfi_type":                     36,
+	"pcsections":                    37,
+	"DIAssignID":                    38,
+	"coro.outside.frame":            39,
+}
+
+// canonicalMetadata returns the given metadata attachments in canonical order;
+// i.e. sorted by metadata kind ID, with custom metadata kinds following fixed
+// metadata kinds in their original order, and !dbg last. The given metadata
+// attachments are left unchanged.
+func canonicalMetadata(mds []MetadataAttachment) []MetadataAttachment {
+	if len(mds) < 2 {
+		return mds
+	}
+	rank := func(name string) int {
+		if name == "dbg" {
+			return len(metadataKindIDs) + 1
+		}
+		if id, ok := metadataKindIDs[name]; ok {
+			return id
+		}
+		return len(metadataKindIDs)
+	}
+	sorted := make([]MetadataAttachment, len(mds))
+	copy(sorted, mds)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return rank(sorted[i].Name) < rank(sorted[j].Name)
+	})
+	return sorted
+}
+
+// metadataAttachments returns a pointer to the list of metadata attachments of
+// the given instruction or terminator.
+func metadataAttachments(v interface{}) *[]MetadataAttachment {
+	field := reflect.ValueOf(v).Elem().FieldByName("Metadata")
+	mds, ok := field.Addr().Interface().(*[]MetadataAttachment)
+	if !ok {
+		panic(fmt.Errorf("invalid metadata attachments of %T; expected *[]ir.MetadataAttachment, got %v", v, field.Type()))
+	}
+	return mds
+}
+
+// wrappedValue returns the value wrapped by the given metadata as value (e.g.
+// %x of "metadata i32 %x"), as passed to debug intrinsics. The boolean return
+// value indicates success.
+func wrappedValue(v value.Value) (value.Value, bool) {
+	mv, ok := v.(*MetadataValue)
+	if !ok {
+		return nil, false
+	}
+	md, ok := mv.Metadata.(*MDValue)
+	if !ok {
+		return nil, false
+	}
+	return md.Value, true
 }
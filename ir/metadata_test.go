@@ -0,0 +1,146 @@
+package ir
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/llir/l/ir/types"
+)
+
+func TestAssignMetadataIDs(t *testing.T) {
+	// Shared metadata node, referenced by both a named metadata definition and
+	// an instruction attachment.
+	shared := NewMDTuple(MDString("shared"))
+	// Cyclic metadata nodes.
+	a := NewMDTuple()
+	b := NewMDTuple(a)
+	a.Fields = []Metadata{nil, b, NewMDValue(NewInt(types.I32, 1))}
+	a.Distinct = true
+	entry := NewBlock("entry")
+	x := entry.NewAdd(NewInt(types.I32, 1), NewInt(types.I32, 2))
+	x.SetName("x")
+	x.Metadata = []MetadataAttachment{{Name: "foo", Node: shared}, {Name: "bar", Node: a}}
+	ret := entry.NewRet(x)
+	ret.Metadata = []MetadataAttachment{{Name: "baz", Node: b}}
+	f := &Function{
+		GlobalName: "f",
+		Sig:        types.NewFunc(types.I32),
+		Blocks:     []*BasicBlock{entry},
+	}
+	m := &Module{
+		Funcs:             []*Function{f},
+		NamedMetadataDefs: []*NamedMetadataDef{{Name: "named", Nodes: []MDNode{shared}}},
+	}
+	want := `define i32 @f() {
+entry:
+  %x = add i32 1, 2, !foo !0, !bar !1
+  ret i32 %x, !baz !2
+}
+
+!named = !{!0}
+
+!0 = !{!"shared"}
+!1 = distinct !{null, !2, i32 1}
+!2 = !{!1}`
+	// Assign metadata IDs twice to verify that numbering is stable.
+	for i := 0; i < 2; i++ {
+		m.AssignMetadataIDs()
+		got := strings.TrimSpace(m.Def())
+		if want != got {
+			t.Errorf("module mismatch; expected `%v`, got `%v`", want, got)
+		}
+	}
+}
+
+func TestMDTupleCyclic(t *testing.T) {
+	// Cyclic metadata nodes without metadata IDs cannot be rendered inline.
+	a := NewMDTuple()
+	b := NewMDTuple(a)
+	a.Fields = []Metadata{b}
+	func() {
+		defer func() {
+			if e := recover(); e == nil {
+				t.Errorf("expected panic for cyclic metadata tuple without metadata ID")
+			}
+		}()
+		_ = a.String()
+	}()
+	// Acyclic metadata nodes are rendered inline.
+	c := NewMDTuple(NewMDTuple(MDString("foo")), NewMDTuple(MDString("foo")))
+	if want, got := `!{!{!"foo"}, !{!"foo"}}`, c.String(); want != got {
+		t.Errorf("metadata tuple mismatch; expected `%v`, got `%v`", want, got)
+	}
+	// Cycles are rendered once a metadata ID is assigned.
+	a.SetID(0)
+	if want, got := "!{!0}", b.String(); want != got {
+		t.Errorf("metadata tuple mismatch; expected `%v`, got `%v`", want, got)
+	}
+}
+
+func TestTermDebugLocation(t *testing.T) {
+	scope := NewMDTuple(MDString("scope"))
+	entry := NewBlock("entry")
+	ret := entry.NewRet(nil)
+	ret.Metadata = []MetadataAttachment{{Name: "dbg", Node: NewDILocation(3, 5, scope)}}
+	f := &Function{
+		GlobalName: "f",
+		Sig:        types.NewFunc(types.Void),
+		Blocks:     []*BasicBlock{entry},
+	}
+	// Debug location rendered inline before metadata IDs are assigned.
+	if want, got := `ret void, !dbg !DILocation(line: 3, column: 5, scope: !{!"scope"})`, ret.Def(); want != got {
+		t.Errorf("terminator mismatch; expected `%v`, got `%v`", want, got)
+	}
+	m := &Module{Funcs: []*Function{f}}
+	m.AssignMetadataIDs()
+	want := `define void @f() {
+entry:
+  ret void, !dbg !0
+}
+
+!0 = !DILocation(line: 3, column: 5, scope: !1)
+!1 = !{!"scope"}`
+	if got := strings.TrimSpace(m.Def()); want != got {
+		t.Errorf("module mismatch; expected `%v`, got `%v`", want, got)
+	}
+}
+
+func TestCanonicalMetadata(t *testing.T) {
+	p := NewParam(types.I32Ptr, "p")
+	load := NewLoad(p)
+	load.SetName("x")
+	load.Metadata = []MetadataAttachment{
+		{Name: "dbg", Node: NewMDTuple(MDString("dbg"))},
+		{Name: "custom", Node: NewMDTuple(MDString("custom"))},
+		{Name: "range", Node: NewMDTuple(MDString("range"))},
+		{Name: "tbaa", Node: NewMDTuple(MDString("tbaa"))},
+	}
+	want := `load i32, i32* %p, !tbaa !{!"tbaa"}, !range !{!"range"}, !custom !{!"custom"}, !dbg !{!"dbg"}`
+	if got := load.Def(); want != got {
+		t.Errorf("instruction mismatch; expected `%v`, got `%v`", want, got)
+	}
+	// The metadata attachments of the instruction are left unchanged.
+	if got := load.Metadata[0].Name; got != "dbg" {
+		t.Errorf("metadata attachment name mismatch; expected `dbg`, got `%v`", got)
+	}
+}
+
+func TestDIExpression(t *testing.T) {
+	golden := []struct {
+		in   *DIExpression
+		want string
+	}{
+		{in: NewDIExpression(), want: "!DIExpression()"},
+		{in: NewDIExpression(DwOpPlusUconst, 4), want: "!DIExpression(DW_OP_plus_uconst, 4)"},
+		{in: NewDIExpression(DwOpDeref, DwOpPlusUconst, 8, DwOpStackValue), want: "!DIExpression(DW_OP_deref, DW_OP_plus_uconst, 8, DW_OP_stack_value)"},
+		{in: NewDIExpressionOffset(4), want: "!DIExpression(DW_OP_plus_uconst, 4)"},
+		{in: NewDIExpressionOffset(-4), want: "!DIExpression(DW_OP_constu, 4, DW_OP_minus)"},
+		{in: NewDIExpressionOffset(0), want: "!DIExpression()"},
+		{in: NewDIExpressionFragment(32, 16), want: "!DIExpression(DW_OP_LLVM_fragment, 32, 16)"},
+	}
+	for _, g := range golden {
+		if got := g.in.String(); g.want != got {
+			t.Errorf("debug expression mismatch; expected `%v`, got `%v`", g.want, got)
+		}
+	}
+}
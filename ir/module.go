@@ -3,11 +3,13 @@ package ir
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/llir/l/internal/enc"
 	"github.com/llir/l/ir/enum"
 	"github.com/llir/l/ir/types"
+	"github.com/pkg/errors"
 )
 
 // === [ Modules ] =============================================================
@@ -23,28 +25,42 @@ type Module struct {
 
 	// extra.
 
+	// (optional) Module ID; or empty if not present.
+	ModuleID string
 	// (optional) Source filename; or empty if not present.
 	SourceFilename string
+	// (optional) Data layout; or empty if not present.
+	DataLayout string
+	// (optional) Target triple; or empty if not present.
+	TargetTriple string
+	// (optional) Comdat definitions.
+	ComdatDefs []*ComdatDef
+	// (optional) Named metadata definitions.
+	NamedMetadataDefs []*NamedMetadataDef
+	// (optional) Metadata definitions; populated by AssignMetadataIDs.
+	MetadataDefs []MDNode
+
+	// Hoist repeated literal struct types into generated type definitions
+	// (e.g. "%anon.0 = type { i32, i8 }") when printing the module.
+	HoistStructTypes bool
+
+	// Symbol table from function name to index in Funcs; lazily built by Func.
+	funcTable map[string]int
+	// Number of functions in Funcs when funcTable was built.
+	funcCount int
+	// Symbol table from global variable name to index in Globals; lazily built
+	// by Global.
+	globalTable map[string]int
+	// Number of global variables in Globals when globalTable was built.
+	globalCount int
 	/*
-		// (optional) Data layout; or empty if not present.
-		DataLayout string
-		// (optional) Target triple; or empty if not present.
-		TargetTriple string
 		// (optional) Module-level inline assembly.
 		ModuleAsms []string
-		// (optional) Comdat definitions.
-		ComdatDefs []*enum.ComdatDef
 		// (optional) Indirect symbol definitions (aliases and IFuncs).
 		// TODO: figure out how to represent aliases and IFuncs.
 		//IndirectSymbols []*IndirectSymbol
 		// (optional) Attribute group definitions.
 		AttrGroupDefs []*enum.AttrGroupDef
-		// (optional) Named metadata definitions.
-		// TODO: figure out how to represent metadata.
-		//NamedMetadataDefs []*metadata.NamedMetadataDef
-		// (optional) Metadata definitions.
-		// TODO: figure out how to represent metadata.
-		//MetadataDefs []*metadata.MetadataDef
 		// (optional) Use-list order directives.
 		UseListOrders []*enum.UseListOrder
 		// (optional) Basic block specific use-list order directives.
@@ -53,23 +69,183 @@ type Module struct {
 }
 
 // Def returns the LLVM syntax representation of the module.
+//
+// If HoistStructTypes is set, literal struct types occurring more than once in
+// the module are printed as references to generated type definitions, which
+// follow the type definitions of the module.
 func (m *Module) Def() string {
 	buf := &strings.Builder{}
+	// Module ID.
+	if len(m.ModuleID) > 0 {
+		// "; ModuleID = '" ModuleID "'"
+		fmt.Fprintf(buf, "; ModuleID = '%s'\n", m.ModuleID)
+	}
+	// Source filename.
+	if len(m.SourceFilename) > 0 {
+		// "source_filename" "=" StringLit
+		fmt.Fprintf(buf, "source_filename = %v\n", quote(m.SourceFilename))
+	}
+	// Data layout.
+	if len(m.DataLayout) > 0 {
+		// "target" "datalayout" "=" StringLit
+		fmt.Fprintf(buf, "target datalayout = %v\n", quote(m.DataLayout))
+	}
+	// Target triple.
+	if len(m.TargetTriple) > 0 {
+		// "target" "triple" "=" StringLit
+		fmt.Fprintf(buf, "target triple = %v\n", quote(m.TargetTriple))
+	}
 	// Type definitions.
+	typeDefs := &strings.Builder{}
 	for _, t := range m.TypeDefs {
 		// LocalIdent "=" "type" OpaqueType
 		// LocalIdent "=" "type" Type
-		fmt.Fprintf(buf, "%s = type %s\n", t, t.Def())
+		fmt.Fprintf(typeDefs, "%s = type %s\n", t, t.Def())
+	}
+	// Top-level entities are grouped into sections separated by blank lines, as
+	// done by LLVM.
+	body := &strings.Builder{}
+	// Comdat definitions.
+	for _, def := range m.ComdatDefs {
+		// ComdatName "=" "comdat" SelectionKind
+		fmt.Fprintf(body, "\n%s\n", def.Def())
+	}
+	// Global variable declarations and definitions.
+	if len(m.Globals) > 0 {
+		body.WriteString("\n")
+	}
+	for _, g := range m.Globals {
+		fmt.Fprintln(body, g.Def())
 	}
-	// TODO: implement Module.Def.
 	// Function declarations and definitions.
 	for _, f := range m.Funcs {
-		fmt.Fprintln(buf, f.Def())
+		fmt.Fprintf(body, "\n%s\n", f.Def())
+	}
+	// Named metadata definitions.
+	if len(m.NamedMetadataDefs) > 0 {
+		body.WriteString("\n")
+	}
+	for _, def := range m.NamedMetadataDefs {
+		// MetadataName "=" "!" "{" MetadataNodes "}"
+		fmt.Fprintln(body, def.Def())
+	}
+	// Metadata definitions.
+	if len(m.MetadataDefs) > 0 {
+		body.WriteString("\n")
+	}
+	for _, node := range m.MetadataDefs {
+		// MetadataID "=" MDNode
+		fmt.Fprintf(body, "%s = %s\n", node.Ident(), node.Def())
 	}
-	// TODO: implement Module.Def.
+	defs, rest := typeDefs.String(), body.String()
+	if m.HoistStructTypes {
+		// Hoisted struct type definitions follow the type definitions.
+		var hoisted string
+		hoisted, defs, rest = m.hoistStructTypes(defs, rest)
+		defs += hoisted
+	}
+	if len(defs) > 0 {
+		buf.WriteString("\n")
+	}
+	buf.WriteString(defs)
+	buf.WriteString(rest)
 	return buf.String()
 }
 
+// Verify reports an error if the module is invalid; i.e. if any function of the
+// module is invalid (see Function.Verify), if global identifiers of functions
+// and global variables are not unique, or if a function or global variable
+// references a comdat without a comdat definition in the module.
+//
+// All errors are reported, as a combined error of type Errors.
+func (m *Module) Verify() error {
+	var errs Errors
+	for _, f := range m.Funcs {
+		if err := f.Verify(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	// Global identifiers of functions and global variables share the same
+	// namespace.
+	names := make(map[string]bool)
+	comdats := make(map[string]bool)
+	for _, def := range m.ComdatDefs {
+		comdats[def.Name] = true
+	}
+	check := func(name string, comdat *ComdatDef) {
+		if names[name] {
+			errs = append(errs, errors.Errorf("duplicate global identifier %v", enc.Global(name)))
+		}
+		names[name] = true
+		if comdat != nil && !comdats[comdat.Name] {
+			errs = append(errs, errors.Errorf("undefined comdat %v referenced by %v", enc.Comdat(comdat.Name), enc.Global(name)))
+		}
+	}
+	for _, g := range m.Globals {
+		check(g.GlobalName, g.Comdat)
+	}
+	for _, f := range m.Funcs {
+		check(f.GlobalName, f.Comdat)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Canonicalize sorts the top-level definitions of the module by kind and name,
+// so that structurally equal modules have identical output regardless of the
+// order in which top-level definitions were added. The kind order is given by
+// the fields of Module; within each kind, unnamed and numbered definitions
+// precede named definitions, and keep their relative order. Instructions and
+// basic blocks within functions are never reordered, nor are metadata
+// definitions.
+func (m *Module) Canonicalize() {
+	sort.SliceStable(m.TypeDefs, func(i, j int) bool {
+		return lessName(strings.TrimPrefix(m.TypeDefs[i].String(), "%"), strings.TrimPrefix(m.TypeDefs[j].String(), "%"))
+	})
+	sort.SliceStable(m.Globals, func(i, j int) bool {
+		return lessName(m.Globals[i].GlobalName, m.Globals[j].GlobalName)
+	})
+	sort.SliceStable(m.Funcs, func(i, j int) bool {
+		return lessName(m.Funcs[i].GlobalName, m.Funcs[j].GlobalName)
+	})
+	sort.SliceStable(m.ComdatDefs, func(i, j int) bool {
+		return lessName(m.ComdatDefs[i].Name, m.ComdatDefs[j].Name)
+	})
+	sort.SliceStable(m.NamedMetadataDefs, func(i, j int) bool {
+		return lessName(m.NamedMetadataDefs[i].Name, m.NamedMetadataDefs[j].Name)
+	})
+}
+
+// lessName reports whether the top-level definition with name a is ordered
+// before the one with name b in canonical order. Unnamed and numbered
+// definitions are ordered before named definitions, and are considered equal
+// so that their relative order is preserved by stable sorting.
+func lessName(a, b string) bool {
+	anon := isUnnamed(a) || isLocalID(a)
+	bnon := isUnnamed(b) || isLocalID(b)
+	switch {
+	case anon || bnon:
+		return anon && !bnon
+	default:
+		return a < b
+	}
+}
+
+// Errors is a list of errors, as reported by Module.Verify.
+type Errors []error
+
+// Error returns the error messages of the list of errors, separated by
+// newlines.
+func (errs Errors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
 // ~~~ [ Comdat Definition ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // ComdatDef is a comdat definition top-level entity.
@@ -1,13 +1,74 @@
 package ir
 
-import "github.com/llir/l/ir/types"
+import (
+	"github.com/llir/l/ir/enum"
+	"github.com/llir/l/ir/types"
+)
 
 // --- [ Functions ] -----------------------------------------------------------
 
-// NewFunction appends a new function to the module based on the given function
+// NewFunc appends a new function to the module based on the given function
 // name, return type and function parameters.
-func (m *Module) NewFunction(name string, retType types.Type, params ...*Param) *Function {
+func (m *Module) NewFunc(name string, retType types.Type, params ...*Param) *Function {
 	f := NewFunction(name, retType, params...)
 	m.Funcs = append(m.Funcs, f)
+	// Invalidate function symbol table.
+	m.funcTable = nil
 	return f
 }
+
+// NewFunction appends a new function to the module based on the given function
+// name, return type and function parameters.
+//
+// Deprecated: use NewFunc.
+func (m *Module) NewFunction(name string, retType types.Type, params ...*Param) *Function {
+	return m.NewFunc(name, retType, params...)
+}
+
+// Func returns the function of the module with the given name (without '@'
+// prefix). The boolean return value indicates success.
+//
+// Lookup is backed by a symbol table which is lazily built on first use, and
+// rebuilt when the number of symbols changes or when a hit is found to be stale
+// (e.g. the old name of a renamed function). Misses do not rebuild the symbol
+// table; functions renamed or replaced in place are thus only found by their
+// new name once the symbol table has been rebuilt.
+func (m *Module) Func(name string) (*Function, bool) {
+	if m.funcTable == nil || m.funcCount != len(m.Funcs) {
+		m.indexFuncs()
+	}
+	i, ok := m.funcTable[name]
+	if !ok {
+		return nil, false
+	}
+	if m.Funcs[i].Name() != name {
+		// Stale hit.
+		m.indexFuncs()
+		if i, ok = m.funcTable[name]; !ok {
+			return nil, false
+		}
+	}
+	return m.Funcs[i], true
+}
+
+// indexFuncs builds the function symbol table of the module.
+func (m *Module) indexFuncs() {
+	m.funcTable = make(map[string]int, len(m.Funcs))
+	for i, f := range m.Funcs {
+		m.funcTable[f.Name()] = i
+	}
+	m.funcCount = len(m.Funcs)
+}
+
+// FuncsByLinkage returns the functions of the module with the given linkage, in
+// order of definition. The absence of an explicit linkage (enum.LinkageNone)
+// is treated as external linkage.
+func (m *Module) FuncsByLinkage(linkage enum.Linkage) []*Function {
+	var funcs []*Function
+	for _, f := range m.Funcs {
+		if sameLinkage(f.Linkage, linkage) {
+			funcs = append(funcs, f)
+		}
+	}
+	return funcs
+}
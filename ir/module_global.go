@@ -1,6 +1,11 @@
 package ir
 
-import "github.com/llir/l/ir/types"
+import (
+	"fmt"
+
+	"github.com/llir/l/ir/enum"
+	"github.com/llir/l/ir/types"
+)
 
 // --- [ Global variables ] ----------------------------------------------------
 
@@ -9,6 +14,8 @@ import "github.com/llir/l/ir/types"
 func (m *Module) NewGlobalDecl(name string, contentType types.Type) *Global {
 	g := NewGlobalDecl(name, contentType)
 	m.Globals = append(m.Globals, g)
+	// Invalidate global variable symbol table.
+	m.globalTable = nil
 	return g
 }
 
@@ -17,5 +24,117 @@ func (m *Module) NewGlobalDecl(name string, contentType types.Type) *Global {
 func (m *Module) NewGlobalDef(name string, init Constant) *Global {
 	g := NewGlobalDef(name, init)
 	m.Globals = append(m.Globals, g)
+	// Invalidate global variable symbol table.
+	m.globalTable = nil
+	return g
+}
+
+// Global returns the global variable of the module with the given name (without
+// '@' prefix). The boolean return value indicates success.
+//
+// Lookup is backed by a symbol table, as described for Func.
+func (m *Module) Global(name string) (*Global, bool) {
+	if m.globalTable == nil || m.globalCount != len(m.Globals) {
+		m.indexGlobals()
+	}
+	i, ok := m.globalTable[name]
+	if !ok {
+		return nil, false
+	}
+	if m.Globals[i].Name() != name {
+		// Stale hit.
+		m.indexGlobals()
+		if i, ok = m.globalTable[name]; !ok {
+			return nil, false
+		}
+	}
+	return m.Globals[i], true
+}
+
+// indexGlobals builds the global variable symbol table of the module.
+func (m *Module) indexGlobals() {
+	m.globalTable = make(map[string]int, len(m.Globals))
+	for i, g := range m.Globals {
+		m.globalTable[g.Name()] = i
+	}
+	m.globalCount = len(m.Globals)
+}
+
+// GlobalsByLinkage returns the global variables of the module with the given
+// linkage, in order of definition. The absence of an explicit linkage
+// (enum.LinkageNone) is treated as external linkage.
+func (m *Module) GlobalsByLinkage(linkage enum.Linkage) []*Global {
+	var globals []*Global
+	for _, g := range m.Globals {
+		if sameLinkage(g.Linkage, linkage) {
+			globals = append(globals, g)
+		}
+	}
+	return globals
+}
+
+// AddGlobalCtor appends a static constructor of the given priority to the
+// @llvm.global_ctors global variable of the module, creating the global
+// variable with appending linkage if not already present. The constructor
+// function is called with the given associated data (e.g. a global variable
+// initialized by the constructor); a nil value indicates no associated data.
+//
+// The @llvm.global_ctors global variable is of type [N x { i32, void ()*, i8*
+// }], where N grows by one for each added static constructor.
+func (m *Module) AddGlobalCtor(priority int, fn *Function, data Constant) *Global {
+	return m.addStructor("llvm.global_ctors", priority, fn, data)
+}
+
+// AddGlobalDtor appends a static destructor of the given priority to the
+// @llvm.global_dtors global variable of the module, creating the global
+// variable with appending linkage if not already present. The destructor
+// function is called with the given associated data; a nil value indicates no
+// associated data.
+//
+// The @llvm.global_dtors global variable is of type [N x { i32, void ()*, i8*
+// }], where N grows by one for each added static destructor.
+func (m *Module) AddGlobalDtor(priority int, fn *Function, data Constant) *Global {
+	return m.addStructor("llvm.global_dtors", priority, fn, data)
+}
+
+// ### [ Helper functions ] ####################################################
+
+// sameLinkage reports whether the linkages a and b are equivalent, treating the
+// absence of an explicit linkage as external linkage.
+func sameLinkage(a, b enum.Linkage) bool {
+	if a == enum.LinkageNone {
+		a = enum.LinkageExternal
+	}
+	if b == enum.LinkageNone {
+		b = enum.LinkageExternal
+	}
+	return a == b
+}
+
+// addStructor appends a static constructor or destructor entry to the
+// @llvm.global_ctors or @llvm.global_dtors global variable with the given name,
+// creating the global variable if not already present.
+func (m *Module) addStructor(name string, priority int, fn *Function, data Constant) *Global {
+	structorType := types.NewStruct(types.I32, types.NewPointer(types.NewFunc(types.Void)), types.I8Ptr)
+	if data == nil {
+		data = NewNull(types.I8Ptr)
+	}
+	entry := NewStruct(structorType, NewInt(types.I32, int64(priority)), fn, data)
+	g, ok := m.Global(name)
+	if !ok {
+		arrayType := types.NewArray(0, structorType)
+		g = m.NewGlobalDef(name, NewArray(arrayType))
+		g.Linkage = enum.LinkageAppending
+	}
+	init, ok := g.Init.(*ConstArray)
+	if !ok {
+		panic(fmt.Errorf("invalid initial value of global variable %v; expected *ir.ConstArray, got %T", g.Ident(), g.Init))
+	}
+	init.Elems = append(init.Elems, entry)
+	init.Typ = types.NewArray(int64(len(init.Elems)), init.Typ.ElemType)
+	g.ContentType = init.Typ
+	if g.Typ != nil {
+		g.Typ.ElemType = g.ContentType
+	}
 	return g
 }
@@ -0,0 +1,231 @@
+package ir
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/llir/l/internal/enc"
+	"github.com/llir/l/ir/types"
+	"github.com/llir/l/ir/value"
+)
+
+// --- [ Type definitions ] ----------------------------------------------------
+
+// hoistStructTypes replaces the literal struct types which occur more than once
+// in the given LLVM IR assembly of the type definitions and body of the module
+// by references to generated type definitions (e.g. "%anon.0"). The generated
+// type definitions are returned, along with the updated type definitions and
+// body of the module.
+//
+// Literal struct types are located through type definitions, the content types
+// of global variables, function signatures and the types of instructions,
+// terminators and their operands, and their uses are counted in the LLVM IR
+// assembly (outside of quoted strings and comments). The struct types of the
+// module are left unchanged.
+//
+// Hoisting rewrites the printed assembly, rather than printing through a map
+// from types to generated type names, as types print themselves (see
+// types.Type) without access to a printing context. This is sound as literal
+// struct types print structurally, so each textual occurrence of the
+// definition of a literal struct type outside of quoted strings and comments is
+// a use of a structurally identical type; packed struct types ("<{ ... }>") are
+// told apart by their leading '<', and enclosing struct types are replaced
+// before the struct types of their fields.
+func (m *Module) hoistStructTypes(typeDefs, body string) (hoisted, newTypeDefs, newBody string) {
+	// Definitions of literal struct types, in order of first occurrence.
+	var keys []string
+	seen := make(map[string]bool)
+	var walk func(t types.Type)
+	walk = func(t types.Type) {
+		switch t := t.(type) {
+		case *types.StructType:
+			if len(t.Alias) > 0 {
+				// Identified struct types are already named.
+				return
+			}
+			key := t.Def()
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+			for _, field := range t.Fields {
+				walk(field)
+			}
+		case *types.ArrayType:
+			walk(t.ElemType)
+		case *types.VectorType:
+			walk(t.ElemType)
+		case *types.PointerType:
+			walk(t.ElemType)
+		case *types.FuncType:
+			walk(t.RetType)
+			for _, param := range t.Params {
+				walk(param)
+			}
+		}
+	}
+	walkOps := func(ops []*value.Value) {
+		for _, op := range ops {
+			if *op != nil {
+				walk((*op).Type())
+			}
+		}
+	}
+	// Names of type definitions, to prevent name collisions.
+	names := make(map[string]bool)
+	for _, t := range m.TypeDefs {
+		names[t.String()] = true
+		// Walk the definition of the type definition, rather than its name.
+		if s, ok := t.(*types.StructType); ok {
+			for _, field := range s.Fields {
+				walk(field)
+			}
+		}
+	}
+	for _, g := range m.Globals {
+		// The types of initial values are given by the content types.
+		walk(g.ContentType)
+	}
+	for _, f := range m.Funcs {
+		walk(f.Sig)
+		for _, block := range f.Blocks {
+			for _, inst := range block.Insts {
+				if v, ok := inst.(value.Value); ok {
+					walk(v.Type())
+				}
+				switch inst := inst.(type) {
+				case *InstAlloca:
+					walk(inst.ElemType)
+				case *InstGetElementPtr:
+					walk(inst.ElemType)
+				}
+				walkOps(inst.Operands())
+			}
+			if block.Term != nil {
+				walkOps(block.Term.Operands())
+			}
+		}
+	}
+	// Hoist enclosing struct types before the struct types of their fields, so
+	// that uses within a hoisted struct type are counted once, in its type
+	// definition.
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return len(keys[order[i]]) > len(keys[order[j]])
+	})
+	texts := []string{typeDefs, body}
+	// Definitions of hoisted struct types, indexed by key index.
+	defs := make(map[int]string)
+	placeholder := func(i int) string {
+		return fmt.Sprintf("\x00%d\x00", i)
+	}
+	for _, i := range order {
+		key := keys[i]
+		n := 0
+		for _, text := range texts {
+			n += len(structTypeUses(text, key))
+		}
+		for _, def := range defs {
+			n += len(structTypeUses(def, key))
+		}
+		if n < 2 {
+			continue
+		}
+		for j, text := range texts {
+			texts[j] = replaceStructType(text, key, placeholder(i))
+		}
+		for j, def := range defs {
+			defs[j] = replaceStructType(def, key, placeholder(i))
+		}
+		defs[i] = key
+	}
+	// Assign generated type names to hoisted struct types, in order of first
+	// occurrence.
+	var oldnew []string
+	var hoistedKeys []int
+	id := 0
+	for i := range keys {
+		if _, ok := defs[i]; !ok {
+			continue
+		}
+		var name string
+		for {
+			name = enc.Local(fmt.Sprintf("anon.%d", id))
+			id++
+			if !names[name] {
+				break
+			}
+		}
+		oldnew = append(oldnew, placeholder(i), name)
+		hoistedKeys = append(hoistedKeys, i)
+	}
+	if len(hoistedKeys) == 0 {
+		return "", typeDefs, body
+	}
+	r := strings.NewReplacer(oldnew...)
+	buf := &strings.Builder{}
+	for _, i := range hoistedKeys {
+		fmt.Fprintf(buf, "%s = type %s\n", r.Replace(placeholder(i)), r.Replace(defs[i]))
+	}
+	return buf.String(), r.Replace(texts[0]), r.Replace(texts[1])
+}
+
+// ### [ Helper functions ] ####################################################
+
+// structTypeUses returns the start offsets of uses of the given literal struct
+// type definition in the LLVM IR assembly s, skipping quoted strings and
+// comments.
+func structTypeUses(s, key string) []int {
+	var uses []int
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			// Skip quoted string; quotes within strings are escaped as \22.
+			end := strings.IndexByte(s[i+1:], '"')
+			if end == -1 {
+				return uses
+			}
+			i += 1 + end
+		case ';':
+			// Skip comment.
+			end := strings.IndexByte(s[i:], '\n')
+			if end == -1 {
+				return uses
+			}
+			i += end
+		default:
+			if !strings.HasPrefix(s[i:], key) {
+				continue
+			}
+			// Skip the struct type of packed struct types (e.g. "<{ i32 }>").
+			if key[0] == '{' && i > 0 && s[i-1] == '<' {
+				continue
+			}
+			uses = append(uses, i)
+			i += len(key) - 1
+		}
+	}
+	return uses
+}
+
+// replaceStructType replaces the uses of the given literal struct type
+// definition in the LLVM IR assembly s with new; see structTypeUses.
+func replaceStructType(s, key, new string) string {
+	uses := structTypeUses(s, key)
+	if len(uses) == 0 {
+		return s
+	}
+	buf := &strings.Builder{}
+	prev := 0
+	for _, use := range uses {
+		buf.WriteString(s[prev:use])
+		buf.WriteString(new)
+		prev = use + len(key)
+	}
+	buf.WriteString(s[prev:])
+	return buf.String()
+}
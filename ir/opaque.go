@@ -0,0 +1,198 @@
+package ir
+
+import (
+	"reflect"
+
+	"github.com/llir/l/ir/types"
+	"github.com/llir/l/ir/value"
+)
+
+// UpgradeToOpaquePointers rewrites all typed pointer types of the module (e.g.
+// "i32*") to opaque pointer types (e.g. "ptr"), preserving address spaces.
+//
+// Types which were inferred from pointee types (e.g. the result type of load
+// instructions, or the type of global variables and functions) are made
+// explicit before rewriting pointer types, so the module prints the same
+// element types in opaque form; e.g.
+//
+//    load i32, i32* %p   ->   load i32, ptr %p
+//
+// Identified struct types are rewritten in place, while other composite types
+// containing pointer types are replaced. Note, instructions created after the
+// upgrade which infer their type from a pointee type (e.g. load instructions
+// created by NewLoad) must be given an explicit type.
+func (m *Module) UpgradeToOpaquePointers() {
+	// Materialize types inferred from pointee types.
+	walkStructs(m, func(v reflect.Value) {
+		if x, ok := v.Addr().Interface().(value.Value); ok {
+			x.Type()
+		}
+	})
+	// Rewrite typed pointer types to opaque pointer types.
+	memo := make(map[types.Type]types.Type)
+	for i, t := range m.TypeDefs {
+		m.TypeDefs[i] = opaqueType(t, memo)
+	}
+	walkStructs(m, func(v reflect.Value) {
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath != "" {
+				// Unexported field.
+				continue
+			}
+			rewriteType(v.Field(i), memo)
+		}
+	})
+}
+
+// ### [ Helper functions ] ####################################################
+
+// typeType is the reflection type of the types.Type interface.
+var typeType = reflect.TypeOf((*types.Type)(nil)).Elem()
+
+// walkStructs invokes f for each struct reachable from the exported fields of
+// the given pointer to struct (e.g. module), including the struct itself. Each
+// struct referenced by pointer is visited once, and types are not visited.
+func walkStructs(x interface{}, f func(v reflect.Value)) {
+	visited := make(map[interface{}]bool)
+	var walk func(v reflect.Value)
+	walk = func(v reflect.Value) {
+		if v.Type().Implements(typeType) {
+			return
+		}
+		switch v.Kind() {
+		case reflect.Ptr:
+			if v.IsNil() || v.Elem().Kind() != reflect.Struct {
+				return
+			}
+			if visited[v.Interface()] {
+				return
+			}
+			visited[v.Interface()] = true
+			walk(v.Elem())
+		case reflect.Interface:
+			if v.IsNil() {
+				return
+			}
+			e := v.Elem()
+			if e.Kind() == reflect.Struct {
+				// Walk an addressable copy of the struct, and store it back.
+				c := reflect.New(e.Type()).Elem()
+				c.Set(e)
+				walk(c)
+				if v.CanSet() {
+					v.Set(c)
+				}
+				return
+			}
+			walk(e)
+		case reflect.Struct:
+			if v.CanAddr() {
+				f(v)
+			}
+			for i := 0; i < v.NumField(); i++ {
+				if v.Type().Field(i).PkgPath != "" {
+					// Unexported field.
+					continue
+				}
+				walk(v.Field(i))
+			}
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < v.Len(); i++ {
+				walk(v.Index(i))
+			}
+		}
+	}
+	walk(reflect.ValueOf(x))
+}
+
+// rewriteType rewrites the given settable type (or slice of types) to its
+// opaque pointer form.
+func rewriteType(v reflect.Value, memo map[types.Type]types.Type) {
+	if v.Kind() == reflect.Slice && v.Type().Elem().Implements(typeType) {
+		for i := 0; i < v.Len(); i++ {
+			rewriteType(v.Index(i), memo)
+		}
+		return
+	}
+	if !v.Type().Implements(typeType) || !v.CanSet() || v.IsNil() {
+		return
+	}
+	t := opaqueType(v.Interface().(types.Type), memo)
+	if reflect.TypeOf(t).AssignableTo(v.Type()) {
+		v.Set(reflect.ValueOf(t))
+	}
+}
+
+// opaqueType returns the opaque pointer form of the given type; i.e. the type
+// with all typed pointer types replaced by opaque pointer types of the same
+// address space. Types without pointer types are returned unchanged.
+func opaqueType(t types.Type, memo map[types.Type]types.Type) types.Type {
+	if u, ok := memo[t]; ok {
+		return u
+	}
+	switch t := t.(type) {
+	case *types.PointerType:
+		if t.ElemType == nil {
+			return t
+		}
+		u := &types.PointerType{Alias: t.Alias, AddrSpace: t.AddrSpace}
+		memo[t] = u
+		return u
+	case *types.ArrayType:
+		elem := opaqueType(t.ElemType, memo)
+		if elem == t.ElemType {
+			return t
+		}
+		u := *t
+		u.ElemType = elem
+		memo[t] = &u
+		return &u
+	case *types.VectorType:
+		elem := opaqueType(t.ElemType, memo)
+		if elem == t.ElemType {
+			return t
+		}
+		u := *t
+		u.ElemType = elem
+		memo[t] = &u
+		return &u
+	case *types.FuncType:
+		u := *t
+		u.RetType = opaqueType(t.RetType, memo)
+		changed := u.RetType != t.RetType
+		u.Params = make([]types.Type, len(t.Params))
+		for i, param := range t.Params {
+			u.Params[i] = opaqueType(param, memo)
+			changed = changed || u.Params[i] != param
+		}
+		if !changed {
+			return t
+		}
+		memo[t] = &u
+		return &u
+	case *types.StructType:
+		if len(t.Alias) > 0 {
+			// Rewrite identified struct types in place, as they may be
+			// recursive.
+			memo[t] = t
+			for i, field := range t.Fields {
+				t.Fields[i] = opaqueType(field, memo)
+			}
+			return t
+		}
+		u := *t
+		u.Fields = make([]types.Type, len(t.Fields))
+		changed := false
+		for i, field := range t.Fields {
+			u.Fields[i] = opaqueType(field, memo)
+			changed = changed || u.Fields[i] != field
+		}
+		if !changed {
+			return t
+		}
+		memo[t] = &u
+		return &u
+	default:
+		return t
+	}
+}
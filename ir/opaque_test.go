@@ -0,0 +1,117 @@
+package ir
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/llir/l/ir/enum"
+	"github.com/llir/l/ir/types"
+)
+
+func TestUpgradeToOpaquePointers(t *testing.T) {
+	m := &Module{}
+	// %T = type { i32, i32* }
+	typ := types.NewStruct(types.I32, types.I32Ptr)
+	typ.SetAlias("T")
+	m.TypeDefs = append(m.TypeDefs, typ)
+	g := m.NewGlobalDef("g", NewInt(types.I32, 1))
+	h := m.NewFunc("h", types.I32Ptr, NewParam(types.I32Ptr, ""))
+	p := NewParam(types.NewPointer(typ), "p")
+	q := NewParam(&types.PointerType{ElemType: types.I8, AddrSpace: 1}, "q")
+	f := m.NewFunc("f", types.I32, p, q)
+	entry := NewBlock("entry")
+	elem := entry.NewGetElementPtr(typ, p, NewInt(types.I64, 0), NewInt(types.I32, 1))
+	ptr := entry.NewLoad(elem)
+	x := entry.NewLoad(ptr)
+	entry.NewStore(x, ptr)
+	entry.NewCall(h, ptr)
+	entry.NewLoad(q)
+	entry.NewStore(x, g)
+	entry.NewRet(x)
+	f.Blocks = []*BasicBlock{entry}
+	if err := f.AssignIDs(); err != nil {
+		t.Fatal(err)
+	}
+	m.UpgradeToOpaquePointers()
+	want := `%T = type { i32, ptr }
+
+@g = global i32 1
+
+declare ptr @h(ptr)
+
+define i32 @f(ptr %p, ptr addrspace(1) %q) {
+entry:
+  %0 = getelementptr %T, ptr %p, i64 0, i32 1
+  %1 = load ptr, ptr %0
+  %2 = load i32, ptr %1
+  store i32 %2, ptr %1
+  %3 = call ptr @h(ptr %1)
+  %4 = load i8, ptr addrspace(1) %q
+  store i32 %2, ptr @g
+  ret i32 %2
+}`
+	if got := strings.TrimSpace(m.Def()); want != got {
+		t.Errorf("module mismatch; expected `%v`, got `%v`", want, got)
+	}
+	if got := g.Type().String(); got != "ptr" {
+		t.Errorf("global variable type mismatch; expected `ptr`, got `%v`", got)
+	}
+	// Package-level pointer types are not modified.
+	if got := types.I32Ptr.String(); got != "i32*" {
+		t.Errorf("pointer type mismatch; expected `i32*`, got `%v`", got)
+	}
+}
+
+func TestUpgradeToOpaquePointersCalls(t *testing.T) {
+	m := &Module{}
+	g := m.NewFunc("g", types.I32, NewParam(types.I32Ptr, "x"))
+	p := NewParam(types.I32Ptr, "p")
+	f := m.NewFunc("f", types.I32, p)
+	entry := NewBlock("")
+	entry.NewCall(g, p)
+	entry.NewRet(NewInt(types.I32, 0))
+	f.Blocks = []*BasicBlock{entry}
+	m.UpgradeToOpaquePointers()
+	// Calls created after the upgrade infer their type from the signature of
+	// the callee.
+	golden := []struct {
+		call  *InstCall
+		typ   string
+		valid bool
+	}{
+		{call: entry.Insts[0].(*InstCall), typ: "i32", valid: true},
+		{call: NewCall(g, p), typ: "i32", valid: true},
+		{call: NewCall(g), typ: "i32", valid: false},
+		{call: NewCall(g, NewInt(types.I32, 0)), typ: "i32", valid: false},
+	}
+	for _, gold := range golden {
+		if got := gold.call.Type().String(); got != gold.typ {
+			t.Errorf("type mismatch of `%v`; expected `%v`, got `%v`", gold.call.Def(), gold.typ, got)
+		}
+		err := gold.call.Validate()
+		if gold.valid && err != nil {
+			t.Errorf("unexpected error for `%v`; %v", gold.call.Def(), err)
+		}
+		if !gold.valid && err == nil {
+			t.Errorf("expected error for `%v`, got nil", gold.call.Def())
+		}
+	}
+	// Musttail calls.
+	call := NewCall(g, p)
+	call.Tail = enum.TailMustTail
+	block := NewBlock("")
+	block.Insts = []Instruction{call}
+	block.NewRet(call)
+	if err := call.ValidateMustTail(f, block); err != nil {
+		t.Errorf("unexpected error for `%v`; %v", call.Def(), err)
+	}
+	// Invoke and callbr terminators.
+	invoke := NewInvoke(g, []Arg{p}, entry, entry)
+	if got := invoke.Type().String(); got != "i32" {
+		t.Errorf("type mismatch of `%v`; expected `i32`, got `%v`", invoke.Def(), got)
+	}
+	callbr := NewCallBr(g, []Arg{p}, entry)
+	if got := callbr.Type().String(); got != "i32" {
+		t.Errorf("type mismatch of `%v`; expected `i32`, got `%v`", callbr.Def(), got)
+	}
+}
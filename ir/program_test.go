@@ -0,0 +1,138 @@
+package ir
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/llir/l/ir/enum"
+	"github.com/llir/l/ir/types"
+)
+
+// TestModuleProgram builds the LLVM IR module of a small C program, and
+// compares the output byte-for-byte against testdata/sum.ll, which holds the
+// clang -emit-llvm -S -O0 output of the program (without metadata and attribute
+// groups).
+//
+//	int count;
+//
+//	int sum(int *a, int n) {
+//		int s = 0;
+//		for (int i = 0; i < n && a[i] != 0; i++) {
+//			s += a[i];
+//		}
+//		count++;
+//		return s;
+//	}
+func TestModuleProgram(t *testing.T) {
+	m := &Module{
+		ModuleID:       "sum.c",
+		SourceFilename: "sum.c",
+		DataLayout:     "e-m:e-p270:32:32-p271:32:32-p272:64:64-i64:64-f80:128-n8:16:32:64-S128",
+		TargetTriple:   "x86_64-pc-linux-gnu",
+	}
+	count := m.NewGlobalDef("count", NewInt(types.I32, 0))
+	count.Preemption = enum.PreemptionDSOLocal
+	count.Align = 4
+	a := NewParam(types.I32Ptr, "a")
+	a.Attrs = []enum.ParamAttribute{enum.ParamAttrNoUndef}
+	n := NewParam(types.I32, "n")
+	n.Attrs = []enum.ParamAttribute{enum.ParamAttrNoUndef}
+	f := m.NewFunc("sum", types.I32, a, n)
+	f.Preemption = enum.PreemptionDSOLocal
+	entry := NewBlock("entry")
+	forCond := NewBlock("for.cond")
+	landRHS := NewBlock("land.rhs")
+	landEnd := NewBlock("land.end")
+	forBody := NewBlock("for.body")
+	forInc := NewBlock("for.inc")
+	forEnd := NewBlock("for.end")
+	f.Blocks = []*BasicBlock{entry, forCond, landRHS, landEnd, forBody, forInc, forEnd}
+	zero, one := NewInt(types.I32, 0), NewInt(types.I32, 1)
+	alloca := func(name string, elemType types.Type, align int) *InstAlloca {
+		inst := entry.NewAlloca(elemType)
+		inst.SetName(name)
+		inst.Alignment = align
+		return inst
+	}
+	load := func(block *BasicBlock, src *InstAlloca) *InstLoad {
+		inst := block.NewLoad(src)
+		inst.Alignment = src.Alignment
+		return inst
+	}
+	// entry:
+	aAddr := alloca("a.addr", types.I32Ptr, 8)
+	nAddr := alloca("n.addr", types.I32, 4)
+	s := alloca("s", types.I32, 4)
+	i := alloca("i", types.I32, 4)
+	entry.NewStore(a, aAddr).Alignment = 8
+	entry.NewStore(n, nAddr).Alignment = 4
+	entry.NewStore(zero, s).Alignment = 4
+	entry.NewStore(zero, i).Alignment = 4
+	entry.NewBr(forCond)
+	// for.cond:
+	i0 := load(forCond, i)
+	n1 := load(forCond, nAddr)
+	cmp := forCond.NewICmp(enum.IPredSLT, i0, n1)
+	cmp.SetName("cmp")
+	forCond.NewCondBr(cmp, landRHS, landEnd)
+	// land.rhs:
+	a2 := load(landRHS, aAddr)
+	i3 := load(landRHS, i)
+	idxprom := landRHS.NewSExt(i3, types.I64)
+	idxprom.SetName("idxprom")
+	arrayidx := landRHS.NewGetElementPtr(types.I32, a2, idxprom)
+	arrayidx.SetName("arrayidx")
+	arrayidx.InBounds = true
+	x4 := landRHS.NewLoad(arrayidx)
+	x4.Alignment = 4
+	cmp1 := landRHS.NewICmp(enum.IPredNE, x4, zero)
+	cmp1.SetName("cmp1")
+	landRHS.NewBr(landEnd)
+	// land.end:
+	phi := landEnd.NewPhi(NewIncoming(False, forCond), NewIncoming(cmp1, landRHS))
+	landEnd.NewCondBr(phi, forBody, forEnd)
+	// for.body:
+	a6 := load(forBody, aAddr)
+	i7 := load(forBody, i)
+	idxprom2 := forBody.NewSExt(i7, types.I64)
+	idxprom2.SetName("idxprom2")
+	arrayidx3 := forBody.NewGetElementPtr(types.I32, a6, idxprom2)
+	arrayidx3.SetName("arrayidx3")
+	arrayidx3.InBounds = true
+	x8 := forBody.NewLoad(arrayidx3)
+	x8.Alignment = 4
+	s9 := load(forBody, s)
+	add := forBody.NewAdd(s9, x8)
+	add.SetName("add")
+	add.OverflowFlags = []enum.OverflowFlag{enum.OverflowFlagNSW}
+	forBody.NewStore(add, s).Alignment = 4
+	forBody.NewBr(forInc)
+	// for.inc:
+	i10 := load(forInc, i)
+	inc := forInc.NewAdd(i10, one)
+	inc.SetName("inc")
+	inc.OverflowFlags = []enum.OverflowFlag{enum.OverflowFlagNSW}
+	forInc.NewStore(inc, i).Alignment = 4
+	forInc.NewBr(forCond)
+	// for.end:
+	count11 := forEnd.NewLoad(count)
+	count11.Alignment = 4
+	inc4 := forEnd.NewAdd(count11, one)
+	inc4.SetName("inc4")
+	inc4.OverflowFlags = []enum.OverflowFlag{enum.OverflowFlagNSW}
+	forEnd.NewStore(inc4, count).Alignment = 4
+	forEnd.NewRet(load(forEnd, s))
+	if err := f.AssignIDs(); err != nil {
+		t.Fatalf("unable to assign IDs; %v", err)
+	}
+	if err := m.Verify(); err != nil {
+		t.Fatalf("unexpected error for module; %v", err)
+	}
+	buf, err := ioutil.ReadFile("testdata/sum.ll")
+	if err != nil {
+		t.Fatalf("unable to read golden file; %v", err)
+	}
+	if want, got := string(buf), m.Def(); want != got {
+		t.Errorf("module mismatch; expected `%v`, got `%v`", want, got)
+	}
+}
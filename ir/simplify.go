@@ -0,0 +1,49 @@
+package ir
+
+// SimplifyGEPs eliminates no-op getelementptr instructions (see
+// InstGetElementPtr.IsNoOp), replacing all uses of the eliminated instruction
+// with its source address. The number of eliminated instructions is returned.
+func (f *Function) SimplifyGEPs() int {
+	n := 0
+	for _, block := range f.Blocks {
+		var insts []Instruction
+		for _, inst := range block.Insts {
+			gep, ok := inst.(*InstGetElementPtr)
+			if !ok || !gep.IsNoOp() {
+				insts = append(insts, inst)
+				continue
+			}
+			f.ReplaceAll(gep, gep.Src)
+			n++
+		}
+		block.Insts = insts
+	}
+	return n
+}
+
+// SimplifyFreezes eliminates freeze instructions of constants which are
+// neither undef nor poison (see Fold), replacing all uses of the eliminated
+// instruction with its constant operand. The number of eliminated instructions
+// is returned.
+func (f *Function) SimplifyFreezes() int {
+	n := 0
+	for _, block := range f.Blocks {
+		var insts []Instruction
+		for _, inst := range block.Insts {
+			freeze, ok := inst.(*InstFreeze)
+			if !ok {
+				insts = append(insts, inst)
+				continue
+			}
+			c, ok := Fold(freeze)
+			if !ok {
+				insts = append(insts, inst)
+				continue
+			}
+			f.ReplaceAll(freeze, c)
+			n++
+		}
+		block.Insts = insts
+	}
+	return n
+}
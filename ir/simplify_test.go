@@ -0,0 +1,78 @@
+package ir
+
+import (
+	"testing"
+
+	"github.com/llir/l/ir/types"
+)
+
+func TestSimplifyGEPs(t *testing.T) {
+	arr := types.NewArray(4, types.I8)
+	p := NewParam(types.I8Ptr, "p")
+	q := NewParam(types.NewPointer(arr), "q")
+	x := NewParam(types.I8, "x")
+	entry := NewBlock("entry")
+	// getelementptr i8, i8* %p, i64 0
+	zero := entry.NewGetElementPtr(types.I8, p, NewInt(types.I64, 0))
+	store1 := entry.NewStore(x, zero)
+	// getelementptr i8, i8* %p, i64 1
+	one := entry.NewGetElementPtr(types.I8, p, NewInt(types.I64, 1))
+	store2 := entry.NewStore(x, one)
+	// getelementptr [4 x i8], [4 x i8]* %q, i64 0, i64 0 (changes type)
+	decay := entry.NewGetElementPtr(arr, q, NewInt(types.I64, 0), NewInt(types.I64, 0))
+	entry.NewStore(x, decay)
+	entry.NewRet(nil)
+	f := NewFunction("f", types.Void, p, q, x)
+	f.Blocks = []*BasicBlock{entry}
+	if !zero.IsNoOp() {
+		t.Errorf("expected no-op getelementptr `%v`", zero.Def())
+	}
+	if one.IsNoOp() {
+		t.Errorf("unexpected no-op getelementptr `%v`", one.Def())
+	}
+	if decay.IsNoOp() {
+		t.Errorf("unexpected no-op getelementptr `%v`", decay.Def())
+	}
+	if n := f.SimplifyGEPs(); n != 1 {
+		t.Errorf("number of eliminated instructions mismatch; expected 1, got %d", n)
+	}
+	// Two getelementptr and three stores remain.
+	if len(entry.Insts) != 5 {
+		t.Fatalf("number of instructions mismatch; expected 5, got %d", len(entry.Insts))
+	}
+	if store1.Dst != p {
+		t.Errorf("use of eliminated getelementptr not replaced")
+	}
+	if store2.Dst != one {
+		t.Errorf("use of non-zero getelementptr replaced")
+	}
+}
+
+func TestSimplifyFreezes(t *testing.T) {
+	x := NewParam(types.I32, "x")
+	entry := NewBlock("entry")
+	// freeze i32 5
+	five := entry.NewFreeze(NewInt(types.I32, 5))
+	add1 := entry.NewAdd(x, five)
+	// freeze i32 undef
+	undef := entry.NewFreeze(NewUndef(types.I32))
+	add2 := entry.NewAdd(x, undef)
+	// freeze i32 poison
+	entry.NewFreeze(NewPoison(types.I32))
+	entry.NewRet(nil)
+	f := NewFunction("f", types.Void, x)
+	f.Blocks = []*BasicBlock{entry}
+	if n := f.SimplifyFreezes(); n != 1 {
+		t.Errorf("number of eliminated instructions mismatch; expected 1, got %d", n)
+	}
+	// Two freeze and two add instructions remain.
+	if len(entry.Insts) != 4 {
+		t.Fatalf("number of instructions mismatch; expected 4, got %d", len(entry.Insts))
+	}
+	if got := add1.Y.String(); got != "i32 5" {
+		t.Errorf("use of eliminated freeze mismatch; expected `i32 5`, got `%v`", got)
+	}
+	if add2.Y != undef {
+		t.Errorf("use of freeze of undef replaced")
+	}
+}
@@ -2,12 +2,14 @@ package ir
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/llir/l/internal/enc"
 	"github.com/llir/l/ir/enum"
 	"github.com/llir/l/ir/types"
 	"github.com/llir/l/ir/value"
+	"github.com/pkg/errors"
 )
 
 // === [ Terminators ] =========================================================
@@ -26,6 +28,7 @@ import (
 //    *ir.TermSwitch        // https://godoc.org/github.com/llir/l/ir#TermSwitch
 //    *ir.TermIndirectBr    // https://godoc.org/github.com/llir/l/ir#TermIndirectBr
 //    *ir.TermInvoke        // https://godoc.org/github.com/llir/l/ir#TermInvoke
+//    *ir.TermCallBr        // https://godoc.org/github.com/llir/l/ir#TermCallBr
 //    *ir.TermResume        // https://godoc.org/github.com/llir/l/ir#TermResume
 //    *ir.TermCatchSwitch   // https://godoc.org/github.com/llir/l/ir#TermCatchSwitch
 //    *ir.TermCatchRet      // https://godoc.org/github.com/llir/l/ir#TermCatchRet
@@ -36,6 +39,8 @@ type Terminator interface {
 	Def() string
 	// Succs returns the successor basic blocks of the terminator.
 	Succs() []*BasicBlock
+	// Operands returns a mutable list of value operands of the terminator.
+	Operands() []*value.Value
 }
 
 // --- [ ret ] -----------------------------------------------------------------
@@ -63,6 +68,14 @@ func (*TermRet) Succs() []*BasicBlock {
 	return nil
 }
 
+// Operands returns a mutable list of value operands of the terminator.
+func (term *TermRet) Operands() []*value.Value {
+	if term.X != nil {
+		return []*value.Value{&term.X}
+	}
+	return nil
+}
+
 // Def returns the LLVM syntax representation of the terminator.
 func (term *TermRet) Def() string {
 	// "ret" VoidType OptCommaSepMetadataAttachmentList
@@ -73,7 +86,7 @@ func (term *TermRet) Def() string {
 	} else {
 		fmt.Fprintf(buf, "ret %v", term.X)
 	}
-	for _, md := range term.Metadata {
+	for _, md := range canonicalMetadata(term.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
@@ -109,12 +122,18 @@ func (term *TermBr) Succs() []*BasicBlock {
 	return term.Successors
 }
 
+// Operands returns a mutable list of value operands of the terminator.
+func (term *TermBr) Operands() []*value.Value {
+	// no value operands.
+	return nil
+}
+
 // Def returns the LLVM syntax representation of the terminator.
 func (term *TermBr) Def() string {
 	// "br" LabelType LocalIdent OptCommaSepMetadataAttachmentList
 	buf := &strings.Builder{}
 	fmt.Fprintf(buf, "br %v", term.Target)
-	for _, md := range term.Metadata {
+	for _, md := range canonicalMetadata(term.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
@@ -140,8 +159,12 @@ type TermCondBr struct {
 }
 
 // NewCondBr returns a new conditional br terminator based on the given
-// branching condition and conditional target basic blocks.
+// branching condition and conditional target basic blocks. NewCondBr panics if
+// the branching condition is not of type i1.
 func NewCondBr(cond value.Value, targetTrue, targetFalse *BasicBlock) *TermCondBr {
+	if !cond.Type().Equal(types.I1) {
+		panic(fmt.Errorf("invalid branching condition type; expected i1, got %v", cond.Type()))
+	}
 	return &TermCondBr{Cond: cond, TargetTrue: targetTrue, TargetFalse: targetFalse}
 }
 
@@ -154,17 +177,32 @@ func (term *TermCondBr) Succs() []*BasicBlock {
 	return term.Successors
 }
 
+// Operands returns a mutable list of value operands of the terminator.
+func (term *TermCondBr) Operands() []*value.Value {
+	return []*value.Value{&term.Cond}
+}
+
 // Def returns the LLVM syntax representation of the terminator.
 func (term *TermCondBr) Def() string {
 	// "br" IntType Value "," LabelType LocalIdent "," LabelType LocalIdent OptCommaSepMetadataAttachmentList
 	buf := &strings.Builder{}
 	fmt.Fprintf(buf, "br %v, %v, %v", term.Cond, term.TargetTrue, term.TargetFalse)
-	for _, md := range term.Metadata {
+	for _, md := range canonicalMetadata(term.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// SetBranchWeights attaches !prof branch weights metadata to the terminator,
+// based on the weights of the true and false target basic blocks.
+func (term *TermCondBr) SetBranchWeights(weights ...uint32) error {
+	if len(weights) != 2 {
+		return errors.Errorf("invalid number of branch weights of conditional br terminator; expected 2, got %d", len(weights))
+	}
+	setMetadataAttachment(&term.Metadata, "prof", NewBranchWeights(weights...))
+	return nil
+}
+
 // --- [ switch ] --------------------------------------------------------------
 
 // TermSwitch is an LLVM IR switch terminator.
@@ -190,6 +228,23 @@ func NewSwitch(x value.Value, targetDefault *BasicBlock, cases ...*Case) *TermSw
 	return &TermSwitch{X: x, TargetDefault: targetDefault, Cases: cases}
 }
 
+// NewSwitchFromMap returns a new switch terminator based on the given control
+// variable, default target basic block and map from case values to target
+// basic blocks. The case comparands are integer constants of the given type,
+// sorted by value for deterministic output.
+func NewSwitchFromMap(x value.Value, targetDefault *BasicBlock, cases map[int64]*BasicBlock, caseType *types.IntType) *TermSwitch {
+	keys := make([]int64, 0, len(cases))
+	for key := range cases {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	cs := make([]*Case, 0, len(keys))
+	for _, key := range keys {
+		cs = append(cs, NewCase(NewInt(caseType, key), cases[key]))
+	}
+	return NewSwitch(x, targetDefault, cs...)
+}
+
 // Succs returns the successor basic blocks of the terminator.
 func (term *TermSwitch) Succs() []*BasicBlock {
 	// Cache successors if not present.
@@ -204,21 +259,51 @@ func (term *TermSwitch) Succs() []*BasicBlock {
 	return term.Successors
 }
 
+// Operands returns a mutable list of value operands of the terminator.
+//
+// Operands only contains the control variable. The case comparands are of type
+// Constant, and may thus not be updated through a *value.Value; use
+// CaseOperands to access them.
+func (term *TermSwitch) Operands() []*value.Value {
+	return []*value.Value{&term.X}
+}
+
+// CaseOperands returns a mutable list of the case comparands of the
+// terminator.
+func (term *TermSwitch) CaseOperands() []*Constant {
+	ops := make([]*Constant, 0, len(term.Cases))
+	for _, c := range term.Cases {
+		ops = append(ops, &c.X)
+	}
+	return ops
+}
+
 // Def returns the LLVM syntax representation of the terminator.
 func (term *TermSwitch) Def() string {
 	// "switch" Type Value "," LabelType LocalIdent "[" Cases "]" OptCommaSepMetadataAttachmentList
 	buf := &strings.Builder{}
 	fmt.Fprintf(buf, "switch %v, %v [\n", term.X, term.TargetDefault)
 	for _, c := range term.Cases {
-		fmt.Fprintf(buf, "\t\t%v\n", c)
+		fmt.Fprintf(buf, "    %v\n", c)
 	}
-	buf.WriteString("\t]")
-	for _, md := range term.Metadata {
+	buf.WriteString("  ]")
+	for _, md := range canonicalMetadata(term.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// SetBranchWeights attaches !prof branch weights metadata to the terminator,
+// based on the weights of the default target basic block followed by the
+// target basic blocks of each case.
+func (term *TermSwitch) SetBranchWeights(weights ...uint32) error {
+	if want := 1 + len(term.Cases); len(weights) != want {
+		return errors.Errorf("invalid number of branch weights of switch terminator; expected %d, got %d", want, len(weights))
+	}
+	setMetadataAttachment(&term.Metadata, "prof", NewBranchWeights(weights...))
+	return nil
+}
+
 // ~~~ [ Switch case ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // Case is a switch case.
@@ -235,6 +320,12 @@ func NewCase(x Constant, target *BasicBlock) *Case {
 	return &Case{X: x, Target: target}
 }
 
+// String returns the string representation of the switch case.
+func (c *Case) String() string {
+	// TypeConst "," LabelType LocalIdent
+	return fmt.Sprintf("%v, %v", c.X, c.Target)
+}
+
 // --- [ indirectbr ] ----------------------------------------------------------
 
 // TermIndirectBr is an LLVM IR indirectbr terminator.
@@ -251,9 +342,10 @@ type TermIndirectBr struct {
 }
 
 // NewIndirectBr returns a new indirectbr terminator based on the given target
-// address (derived from a blockaddress constant) and set of valid target basic
+// address (derived from a blockaddress constant; e.g. loaded from a table of
+// blockaddress constants in computed goto) and set of valid target basic
 // blocks.
-func NewIndirectBr(addr *ConstBlockAddress, validTargets ...*BasicBlock) *TermIndirectBr {
+func NewIndirectBr(addr value.Value, validTargets ...*BasicBlock) *TermIndirectBr {
 	return &TermIndirectBr{Addr: addr, ValidTargets: validTargets}
 }
 
@@ -262,6 +354,11 @@ func (term *TermIndirectBr) Succs() []*BasicBlock {
 	return term.ValidTargets
 }
 
+// Operands returns a mutable list of value operands of the terminator.
+func (term *TermIndirectBr) Operands() []*value.Value {
+	return []*value.Value{&term.Addr}
+}
+
 // Def returns the LLVM syntax representation of the terminator.
 func (term *TermIndirectBr) Def() string {
 	// "indirectbr" Type Value "," "[" LabelList "]" OptCommaSepMetadataAttachmentList
@@ -274,12 +371,35 @@ func (term *TermIndirectBr) Def() string {
 		buf.WriteString(target.String())
 	}
 	buf.WriteString("]")
-	for _, md := range term.Metadata {
+	for _, md := range canonicalMetadata(term.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
 }
 
+// Validate reports an error if the indirectbr terminator is invalid; i.e. if
+// the target address is not of pointer type, or if the target address is a
+// blockaddress constant which is invalid (see ConstBlockAddress.Validate) or
+// refers to a basic block outside of the set of valid target basic blocks.
+func (term *TermIndirectBr) Validate() error {
+	if !types.IsPointer(term.Addr.Type()) {
+		return errors.Errorf("invalid target address type of indirectbr terminator; expected pointer type, got %v", term.Addr.Type())
+	}
+	c, ok := term.Addr.(*ConstBlockAddress)
+	if !ok {
+		return nil
+	}
+	if err := c.Validate(); err != nil {
+		return errors.WithStack(err)
+	}
+	for _, target := range term.ValidTargets {
+		if target == c.Block {
+			return nil
+		}
+	}
+	return errors.Errorf("invalid target address %v of indirectbr terminator; expected one of the valid target basic blocks", c.Ident())
+}
+
 // --- [ invoke ] --------------------------------------------------------------
 
 // TermInvoke is an LLVM IR invoke terminator.
@@ -336,13 +456,12 @@ func (term *TermInvoke) String() string {
 func (term *TermInvoke) Type() types.Type {
 	// Cache type if not present.
 	if term.Typ == nil {
-		t, ok := term.Invokee.Type().(*types.PointerType)
-		if !ok {
-			panic(fmt.Errorf("invalid invokee type; expected *types.PointerType, got %T", term.Invokee.Type()))
+		sig, err := calleeSig(term.Invokee, nil)
+		if err != nil {
+			panic(err)
 		}
-		sig, ok := t.ElemType.(*types.FuncType)
-		if !ok {
-			panic(fmt.Errorf("invalid invokee type; expected *types.FuncType, got %T", t.ElemType))
+		if sig == nil {
+			panic(fmt.Errorf("unable to infer type of invoke to %s through opaque pointer; Typ must be set explicitly", term.Invokee.Ident()))
 		}
 		if sig.Variadic {
 			term.Typ = sig
@@ -380,6 +499,16 @@ func (term *TermInvoke) Succs() []*BasicBlock {
 	return term.Successors
 }
 
+// Operands returns a mutable list of value operands of the terminator.
+func (term *TermInvoke) Operands() []*value.Value {
+	ops := make([]*value.Value, 0, 1+len(term.Args))
+	ops = append(ops, &term.Invokee)
+	for i := range term.Args {
+		ops = append(ops, &term.Args[i])
+	}
+	return ops
+}
+
 // Def returns the LLVM syntax representation of the terminator.
 func (term *TermInvoke) Def() string {
 	// "invoke" OptCallingConv ReturnAttrs Type Value "(" Args ")" FuncAttrs OperandBundles "to" LabelType LocalIdent "unwind" LabelType LocalIdent OptCommaSepMetadataAttachmentList
@@ -391,7 +520,7 @@ func (term *TermInvoke) Def() string {
 	for _, attr := range term.ReturnAttrs {
 		fmt.Fprintf(buf, " %v", attr)
 	}
-	fmt.Fprintf(buf, "%v %v(", term.Type(), term.Invokee.Ident())
+	fmt.Fprintf(buf, " %v %v(", term.Type(), term.Invokee.Ident())
 	for i, arg := range term.Args {
 		if i != 0 {
 			buf.WriteString(", ")
@@ -410,7 +539,159 @@ func (term *TermInvoke) Def() string {
 		buf.WriteString("]")
 	}
 	fmt.Fprintf(buf, " to %v unwind %v", term.Normal, term.Exception)
-	for _, md := range term.Metadata {
+	for _, md := range canonicalMetadata(term.Metadata) {
+		fmt.Fprintf(buf, ", %v", md)
+	}
+	return buf.String()
+}
+
+// --- [ callbr ] --------------------------------------------------------------
+
+// TermCallBr is an LLVM IR callbr terminator.
+type TermCallBr struct {
+	// Name of local variable associated with the result.
+	LocalName string
+	// Callee; an inline assembler expression (*ir.InlineAsm) or a function.
+	Callee value.Value
+	// Function arguments.
+	Args []Arg
+	// Default control flow return point; the result of the terminator (e.g.
+	// from output constraints of inline assembly) flows to this basic block.
+	Default *BasicBlock
+	// Indirect control flow return points.
+	Indirect []*BasicBlock
+
+	// extra.
+
+	// Type of result produced by the terminator, or function signature of the
+	// callee (as used when callee is variadic).
+	Typ types.Type
+	// Successor basic blocks of the terminator.
+	Successors []*BasicBlock
+	// (optional) Calling convention; zero if not present.
+	CallingConv enum.CallingConv
+	// (optional) Return attributes.
+	ReturnAttrs []enum.ReturnAttribute
+	// (optional) Address space; zero if not present.
+	AddrSpace types.AddrSpace
+	// (optional) Function attributes.
+	FuncAttrs []enum.FuncAttribute
+	// (optional) Operand bundles.
+	OperandBundles []enum.OperandBundle
+	// (optional) Metadata.
+	Metadata []MetadataAttachment
+}
+
+// NewCallBr returns a new callbr terminator based on the given callee, function
+// arguments and control flow return points for default and indirect execution.
+func NewCallBr(callee value.Value, args []Arg, defaultTarget *BasicBlock, indirectTargets ...*BasicBlock) *TermCallBr {
+	return &TermCallBr{Callee: callee, Args: args, Default: defaultTarget, Indirect: indirectTargets}
+}
+
+// String returns the LLVM syntax representation of the terminator as a type-
+// value pair.
+func (term *TermCallBr) String() string {
+	return fmt.Sprintf("%v %v", term.Type(), term.Ident())
+}
+
+// Type returns the type of the terminator.
+func (term *TermCallBr) Type() types.Type {
+	// Cache type if not present.
+	if term.Typ == nil {
+		sig, err := calleeSig(term.Callee, nil)
+		if err != nil {
+			panic(err)
+		}
+		if sig == nil {
+			panic(fmt.Errorf("unable to infer type of callbr to %s through opaque pointer; Typ must be set explicitly", term.Callee.Ident()))
+		}
+		if sig.Variadic {
+			term.Typ = sig
+		} else {
+			term.Typ = sig.RetType
+		}
+	}
+	if t, ok := term.Typ.(*types.FuncType); ok {
+		return t.RetType
+	}
+	return term.Typ
+}
+
+// Ident returns the identifier associated with the terminator.
+func (term *TermCallBr) Ident() string {
+	return enc.Local(term.LocalName)
+}
+
+// Name returns the name of the terminator.
+func (term *TermCallBr) Name() string {
+	return term.LocalName
+}
+
+// SetName sets the name of the terminator.
+func (term *TermCallBr) SetName(name string) {
+	term.LocalName = name
+}
+
+// Succs returns the successor basic blocks of the terminator.
+func (term *TermCallBr) Succs() []*BasicBlock {
+	// Cache successors if not present.
+	if term.Successors == nil {
+		succs := make([]*BasicBlock, 0, 1+len(term.Indirect))
+		succs = append(succs, term.Default)
+		succs = append(succs, term.Indirect...)
+		term.Successors = succs
+	}
+	return term.Successors
+}
+
+// Operands returns a mutable list of value operands of the terminator.
+func (term *TermCallBr) Operands() []*value.Value {
+	ops := make([]*value.Value, 0, 1+len(term.Args))
+	ops = append(ops, &term.Callee)
+	for i := range term.Args {
+		ops = append(ops, &term.Args[i])
+	}
+	return ops
+}
+
+// Def returns the LLVM syntax representation of the terminator.
+func (term *TermCallBr) Def() string {
+	// "callbr" OptCallingConv ReturnAttrs Type Value "(" Args ")" FuncAttrs OperandBundles "to" LabelType LocalIdent "[" LabelList "]" OptCommaSepMetadataAttachmentList
+	buf := &strings.Builder{}
+	buf.WriteString("callbr")
+	if term.CallingConv != enum.CallingConvNone {
+		fmt.Fprintf(buf, " %v", term.CallingConv)
+	}
+	for _, attr := range term.ReturnAttrs {
+		fmt.Fprintf(buf, " %v", attr)
+	}
+	fmt.Fprintf(buf, " %v %v(", term.Type(), term.Callee.Ident())
+	for i, arg := range term.Args {
+		if i != 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(arg.String())
+	}
+	buf.WriteString(")")
+	for _, attr := range term.FuncAttrs {
+		fmt.Fprintf(buf, " %v", attr)
+	}
+	if len(term.OperandBundles) > 0 {
+		buf.WriteString("[")
+		for _, operandBundle := range term.OperandBundles {
+			fmt.Fprintf(buf, " %v", operandBundle)
+		}
+		buf.WriteString("]")
+	}
+	fmt.Fprintf(buf, " to %v [", term.Default)
+	for i, target := range term.Indirect {
+		if i != 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(target.String())
+	}
+	buf.WriteString("]")
+	for _, md := range canonicalMetadata(term.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
@@ -441,12 +722,17 @@ func (term *TermResume) Succs() []*BasicBlock {
 	return nil
 }
 
+// Operands returns a mutable list of value operands of the terminator.
+func (term *TermResume) Operands() []*value.Value {
+	return []*value.Value{&term.X}
+}
+
 // Def returns the LLVM syntax representation of the terminator.
 func (term *TermResume) Def() string {
 	// "resume" Type Value OptCommaSepMetadataAttachmentList
 	buf := &strings.Builder{}
 	fmt.Fprintf(buf, "resume %v", term.X)
-	for _, md := range term.Metadata {
+	for _, md := range canonicalMetadata(term.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
@@ -518,6 +804,12 @@ func (term *TermCatchSwitch) Succs() []*BasicBlock {
 	return term.Successors
 }
 
+// Operands returns a mutable list of value operands of the terminator.
+func (term *TermCatchSwitch) Operands() []*value.Value {
+	// no value operands.
+	return nil
+}
+
 // Def returns the LLVM syntax representation of the terminator.
 func (term *TermCatchSwitch) Def() string {
 	// "catchswitch" "within" ExceptionScope "[" LabelList "]" "unwind" UnwindTarget OptCommaSepMetadataAttachmentList
@@ -530,7 +822,7 @@ func (term *TermCatchSwitch) Def() string {
 		buf.WriteString(handler.String())
 	}
 	fmt.Fprintf(buf, "] unwind %v", term.UnwindTarget)
-	for _, md := range term.Metadata {
+	for _, md := range canonicalMetadata(term.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
@@ -568,12 +860,19 @@ func (term *TermCatchRet) Succs() []*BasicBlock {
 	return term.Successors
 }
 
+// Operands returns a mutable list of value operands of the terminator.
+func (term *TermCatchRet) Operands() []*value.Value {
+	// The exit catchpad is not a value operand, as it is referred to by
+	// *InstCatchPad.
+	return nil
+}
+
 // Def returns the LLVM syntax representation of the terminator.
 func (term *TermCatchRet) Def() string {
 	// "catchret" "from" Value "to" LabelType LocalIdent OptCommaSepMetadataAttachmentList
 	buf := &strings.Builder{}
 	fmt.Fprintf(buf, "catchret from %v to %v", term.From, term.To)
-	for _, md := range term.Metadata {
+	for _, md := range canonicalMetadata(term.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
@@ -615,12 +914,19 @@ func (term *TermCleanupRet) Succs() []*BasicBlock {
 	return term.Successors
 }
 
+// Operands returns a mutable list of value operands of the terminator.
+func (term *TermCleanupRet) Operands() []*value.Value {
+	// The exit cleanuppad is not a value operand, as it is referred to by
+	// *InstCleanupPad.
+	return nil
+}
+
 // Def returns the LLVM syntax representation of the terminator.
 func (term *TermCleanupRet) Def() string {
 	// "cleanupret" "from" Value "unwind" UnwindTarget OptCommaSepMetadataAttachmentList
 	buf := &strings.Builder{}
 	fmt.Fprintf(buf, "cleanupret from %v unwind %v", term.From, term.UnwindTarget)
-	for _, md := range term.Metadata {
+	for _, md := range canonicalMetadata(term.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
@@ -647,12 +953,18 @@ func (term *TermUnreachable) Succs() []*BasicBlock {
 	return nil
 }
 
+// Operands returns a mutable list of value operands of the terminator.
+func (term *TermUnreachable) Operands() []*value.Value {
+	// no value operands.
+	return nil
+}
+
 // Def returns the LLVM syntax representation of the terminator.
 func (term *TermUnreachable) Def() string {
 	// "unreachable" OptCommaSepMetadataAttachmentList
 	buf := &strings.Builder{}
 	buf.WriteString("unreachable")
-	for _, md := range term.Metadata {
+	for _, md := range canonicalMetadata(term.Metadata) {
 		fmt.Fprintf(buf, ", %v", md)
 	}
 	return buf.String()
@@ -0,0 +1,205 @@
+package ir
+
+import (
+	"testing"
+
+	"github.com/llir/l/ir/types"
+)
+
+func TestNewSwitchFromMap(t *testing.T) {
+	x := NewParam(types.I32, "x")
+	def := NewBlock("default")
+	a := NewBlock("a")
+	b := NewBlock("b")
+	c := NewBlock("c")
+	cases := map[int64]*BasicBlock{
+		42: a,
+		-1: b,
+		7:  c,
+		0:  a,
+	}
+	want := `switch i32 %x, label %default [
+    i32 -1, label %b
+    i32 0, label %a
+    i32 7, label %c
+    i32 42, label %a
+  ]`
+	// Render repeatedly, as Go map iteration order is randomized.
+	for i := 0; i < 10; i++ {
+		entry := NewBlock("entry")
+		term := entry.NewSwitchFromMap(x, def, cases, types.I32)
+		if entry.Term != term {
+			t.Fatalf("switch terminator not set on basic block")
+		}
+		if got := term.Def(); want != got {
+			t.Fatalf("switch mismatch; expected `%v`, got `%v`", want, got)
+		}
+	}
+}
+
+func TestBranchWeights(t *testing.T) {
+	cond := NewParam(types.I1, "cond")
+	x := NewParam(types.I32, "x")
+	entry := NewBlock("entry")
+	a := NewBlock("a")
+	b := NewBlock("b")
+	condBr := entry.NewCondBr(cond, a, b)
+	if err := condBr.SetBranchWeights(100, 1); err != nil {
+		t.Fatal(err)
+	}
+	sw := a.NewSwitch(x, b, NewCase(NewInt(types.I32, 1), a))
+	if err := sw.SetBranchWeights(1, 2); err != nil {
+		t.Fatal(err)
+	}
+	b.NewRet(nil)
+	m := &Module{}
+	m.Funcs = append(m.Funcs, &Function{
+		GlobalName: "f",
+		Sig:        types.NewFunc(types.Void, types.I1, types.I32),
+		Params:     []*Param{cond, x},
+		Blocks:     []*BasicBlock{entry, a, b},
+	})
+	m.AssignMetadataIDs()
+	golden := []struct {
+		got  string
+		want string
+	}{
+		{got: condBr.Def(), want: "br i1 %cond, label %a, label %b, !prof !0"},
+		{got: sw.Def(), want: "switch i32 %x, label %b [\n    i32 1, label %a\n  ], !prof !1"},
+		{got: m.MetadataDefs[0].Def(), want: `!{!"branch_weights", i32 100, i32 1}`},
+		{got: m.MetadataDefs[1].Def(), want: `!{!"branch_weights", i32 1, i32 2}`},
+	}
+	for _, g := range golden {
+		if g.want != g.got {
+			t.Errorf("branch weights mismatch; expected `%v`, got `%v`", g.want, g.got)
+		}
+	}
+	// Invalid number of branch weights.
+	if err := condBr.SetBranchWeights(1, 2, 3); err == nil {
+		t.Errorf("expected error for invalid number of branch weights of conditional br terminator")
+	}
+	if err := sw.SetBranchWeights(1); err == nil {
+		t.Errorf("expected error for invalid number of branch weights of switch terminator")
+	}
+}
+
+func TestTermOperands(t *testing.T) {
+	x := NewParam(types.I32, "x")
+	a := NewParam(types.I1, "a")
+	b := NewParam(types.I1, "b")
+	entry := NewBlock("entry")
+	t1 := NewBlock("t1")
+	t2 := NewBlock("t2")
+	exit := NewBlock("exit")
+	entry.NewCondBr(a, t1, t2)
+	one, two := NewInt(types.I32, 1), NewInt(types.I32, 2)
+	t1.NewSwitch(x, exit, NewCase(one, t2), NewCase(two, exit))
+	t2.NewBr(exit)
+	exit.NewRet(x)
+	f := NewFunction("f", types.I32, x, a, b)
+	f.Blocks = []*BasicBlock{entry, t1, t2, exit}
+	// Switch operands include the condition, and the constant case comparands
+	// are accessed through CaseOperands.
+	if ops := t1.Term.Operands(); len(ops) != 1 || *ops[0] != x {
+		t.Errorf("switch operands mismatch; expected [%v], got %v", x, ops)
+	}
+	if ops := t1.Term.(*TermSwitch).CaseOperands(); len(ops) != 2 || *ops[0] != one || *ops[1] != two {
+		t.Errorf("switch case operands mismatch; expected [%v, %v], got %v", one, two, ops)
+	}
+	// Return operand present for non-void returns only.
+	if ops := exit.Term.Operands(); len(ops) != 1 || *ops[0] != x {
+		t.Errorf("ret operands mismatch; expected [%v], got %v", x, ops)
+	}
+	if ops := NewRet(nil).Operands(); len(ops) != 0 {
+		t.Errorf("ret void operands mismatch; expected none, got %v", ops)
+	}
+	// Replace the branch condition.
+	f.ReplaceAll(a, b)
+	if want, got := "br i1 %b, label %t1, label %t2", entry.Term.Def(); want != got {
+		t.Errorf("terminator mismatch; expected `%v`, got `%v`", want, got)
+	}
+	// Replace a case comparand by a constant; case comparands are not replaced
+	// by non-constant values.
+	three := NewInt(types.I32, 3)
+	f.ReplaceAll(one, three)
+	f.ReplaceAll(two, x)
+	if want, got := "switch i32 %x, label %exit [\n    i32 3, label %t2\n    i32 2, label %exit\n  ]", t1.Term.Def(); want != got {
+		t.Errorf("terminator mismatch; expected `%v`, got `%v`", want, got)
+	}
+}
+
+func TestIndirectBrComputedGoto(t *testing.T) {
+	// Computed goto through a table of blockaddress constants of unnamed basic
+	// blocks.
+	i := NewParam(types.I64, "i")
+	entry := NewBlock("entry")
+	a := NewBlock("")
+	b := NewBlock("")
+	f := NewFunction("f", types.I32, i)
+	f.Blocks = []*BasicBlock{entry, a, b}
+	tableType := types.NewArray(2, types.I8Ptr)
+	table := NewGlobalDef("table", NewArray(tableType, NewBlockAddress(f, a), NewBlockAddress(f, b)))
+	table.Immutable = true
+	elem := entry.NewGetElementPtr(tableType, table, NewInt(types.I64, 0), i)
+	elem.SetName("elem")
+	addr := entry.NewLoad(elem)
+	addr.SetName("addr")
+	entry.NewIndirectBr(addr, a, b)
+	a.NewRet(NewInt(types.I32, 1))
+	b.NewRet(NewInt(types.I32, 2))
+	if err := f.Verify(); err != nil {
+		t.Fatalf("unexpected error for `%v`; %v", f.Def(), err)
+	}
+	if err := f.AssignIDs(); err != nil {
+		t.Fatalf("unable to assign IDs; %v", err)
+	}
+	// The blockaddress constants refer to the basic blocks by their assigned
+	// local IDs.
+	want := "@table = constant [2 x i8*] [i8* blockaddress(@f, %0), i8* blockaddress(@f, %1)]"
+	if got := table.Def(); want != got {
+		t.Errorf("global variable mismatch; expected `%v`, got `%v`", want, got)
+	}
+	want = `define i32 @f(i64 %i) {
+entry:
+  %elem = getelementptr [2 x i8*], [2 x i8*]* @table, i64 0, i64 %i
+  %addr = load i8*, i8** %elem
+  indirectbr i8* %addr, [label %0, label %1]
+
+0:                                                ; preds = %entry
+  ret i32 1
+
+1:                                                ; preds = %entry
+  ret i32 2
+}`
+	if got := f.Def(); want != got {
+		t.Errorf("function mismatch; expected `%v`, got `%v`", want, got)
+	}
+	// Invalid blockaddress targets.
+	golden := []struct {
+		in   *TermIndirectBr
+		want string
+	}{
+		{
+			in:   NewIndirectBr(NewBlockAddress(f, entry), entry),
+			want: "invalid blockaddress of entry basic block %entry of function @f",
+		},
+		{
+			in:   NewIndirectBr(NewBlockAddress(f, NewBlock("c")), a),
+			want: "invalid blockaddress of basic block %c; expected basic block of function @f",
+		},
+		{
+			in:   NewIndirectBr(NewBlockAddress(f, b), a),
+			want: "invalid target address blockaddress(@f, %1) of indirectbr terminator; expected one of the valid target basic blocks",
+		},
+	}
+	for _, g := range golden {
+		err := g.in.Validate()
+		if err == nil {
+			t.Errorf("expected error for `%v`, got nil", g.in.Def())
+			continue
+		}
+		if got := err.Error(); g.want != got {
+			t.Errorf("error mismatch; expected `%v`, got `%v`", g.want, got)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+; ModuleID = 'sum.c'
+source_filename = "sum.c"
+target datalayout = "e-m:e-p270:32:32-p271:32:32-p272:64:64-i64:64-f80:128-n8:16:32:64-S128"
+target triple = "x86_64-pc-linux-gnu"
+
+@count = dso_local global i32 0, align 4
+
+define dso_local i32 @sum(i32* noundef %a, i32 noundef %n) {
+entry:
+  %a.addr = alloca i32*, align 8
+  %n.addr = alloca i32, align 4
+  %s = alloca i32, align 4
+  %i = alloca i32, align 4
+  store i32* %a, i32** %a.addr, align 8
+  store i32 %n, i32* %n.addr, align 4
+  store i32 0, i32* %s, align 4
+  store i32 0, i32* %i, align 4
+  br label %for.cond
+
+for.cond:                                         ; preds = %for.inc, %entry
+  %0 = load i32, i32* %i, align 4
+  %1 = load i32, i32* %n.addr, align 4
+  %cmp = icmp slt i32 %0, %1
+  br i1 %cmp, label %land.rhs, label %land.end
+
+land.rhs:                                         ; preds = %for.cond
+  %2 = load i32*, i32** %a.addr, align 8
+  %3 = load i32, i32* %i, align 4
+  %idxprom = sext i32 %3 to i64
+  %arrayidx = getelementptr inbounds i32, i32* %2, i64 %idxprom
+  %4 = load i32, i32* %arrayidx, align 4
+  %cmp1 = icmp ne i32 %4, 0
+  br label %land.end
+
+land.end:                                         ; preds = %land.rhs, %for.cond
+  %5 = phi i1 [ false, %for.cond ], [ %cmp1, %land.rhs ]
+  br i1 %5, label %for.body, label %for.end
+
+for.body:                                         ; preds = %land.end
+  %6 = load i32*, i32** %a.addr, align 8
+  %7 = load i32, i32* %i, align 4
+  %idxprom2 = sext i32 %7 to i64
+  %arrayidx3 = getelementptr inbounds i32, i32* %6, i64 %idxprom2
+  %8 = load i32, i32* %arrayidx3, align 4
+  %9 = load i32, i32* %s, align 4
+  %add = add nsw i32 %9, %8
+  store i32 %add, i32* %s, align 4
+  br label %for.inc
+
+for.inc:                                          ; preds = %for.body
+  %10 = load i32, i32* %i, align 4
+  %inc = add nsw i32 %10, 1
+  store i32 %inc, i32* %i, align 4
+  br label %for.cond
+
+for.end:                                          ; preds = %land.end
+  %11 = load i32, i32* @count, align 4
+  %inc4 = add nsw i32 %11, 1
+  store i32 %inc4, i32* @count, align 4
+  %12 = load i32, i32* %s, align 4
+  ret i32 %12
+}
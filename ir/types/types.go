@@ -15,6 +15,7 @@ var (
 	// Basic types.
 	Void     = &VoidType{}     // void
 	MMX      = &MMXType{}      // x86_mmx
+	X86MMX   = MMX             // x86_mmx
 	Label    = &LabelType{}    // label
 	Token    = &TokenType{}    // token
 	Metadata = &MetadataType{} // metadata
@@ -26,6 +27,7 @@ var (
 	I64 = &IntType{BitSize: 64} // i64
 	// Floating-point types.
 	Half     = &FloatType{Kind: FloatKindHalf}     // half
+	BFloat   = &FloatType{Kind: FloatKindBFloat}   // bfloat
 	Float    = &FloatType{Kind: FloatKindFloat}    // float
 	Double   = &FloatType{Kind: FloatKindDouble}   // double
 	X86FP80  = &FloatType{Kind: FloatKindX86FP80}  // x86_fp80
@@ -127,7 +129,30 @@ func (t *FuncType) Equal(u Type) bool {
 	return false
 }
 
-// String returns the string representation of the function type.
+// CallCompatible reports whether the function types t and u are call
+// compatible, as required of the caller and callee signatures of musttail
+// calls; i.e. whether t and u have the same return type, the same fixed
+// parameter types and the same variadic-ness. Pointer types may differ in
+// element type, but not in address space.
+func (t *FuncType) CallCompatible(u *FuncType) bool {
+	if !callCompatible(t.RetType, u.RetType) {
+		return false
+	}
+	if len(t.Params) != len(u.Params) {
+		return false
+	}
+	for i := range t.Params {
+		if !callCompatible(t.Params[i], u.Params[i]) {
+			return false
+		}
+	}
+	return t.Variadic == u.Variadic
+}
+
+// String returns the string representation of the function type. Variadic
+// function types are rendered with a trailing ellipsis in the parameter list
+// (e.g. "i32 (i8*, ...)"), as required in type position of call instructions
+// and constant expressions over function pointers.
 func (t *FuncType) String() string {
 	if len(t.Alias) > 0 {
 		return enc.Local(t.Alias)
@@ -137,14 +162,20 @@ func (t *FuncType) String() string {
 
 // Def returns the LLVM syntax representation of the definition of the type.
 func (t *FuncType) Def() string {
+	return t.def([]Type{t})
+}
+
+// def returns the LLVM syntax representation of the definition of the type,
+// with the given stack of composite types being printed.
+func (t *FuncType) def(stack []Type) string {
 	// Type "(" Params ")"
 	buf := &strings.Builder{}
-	fmt.Fprintf(buf, "%v (", t.RetType)
+	fmt.Fprintf(buf, "%s (", typeString(t.RetType, stack))
 	for i, param := range t.Params {
 		if i != 0 {
 			buf.WriteString(", ")
 		}
-		buf.WriteString(param.String())
+		buf.WriteString(typeString(param, stack))
 	}
 	if t.Variadic {
 		if len(t.Params) > 0 {
@@ -241,6 +272,11 @@ func (t *FloatType) SetAlias(alias string) {
 	t.Alias = alias
 }
 
+// BitSize returns the size in number of bits of the floating-point type.
+func (t *FloatType) BitSize() int64 {
+	return t.Kind.BitSize()
+}
+
 //go:generate stringer -linecomment -type FloatKind
 
 // FloatKind represents the set of floating-point kinds.
@@ -249,6 +285,7 @@ type FloatKind uint8
 // Floating-point kinds.
 const (
 	FloatKindHalf     FloatKind = iota // half
+	FloatKindBFloat                    // bfloat
 	FloatKindFloat                     // float
 	FloatKindDouble                    // double
 	FloatKindX86FP80                   // x86_fp80
@@ -256,6 +293,23 @@ const (
 	FloatKindPPCFP128                  // ppc_fp128
 )
 
+// BitSize returns the size in number of bits of the floating-point kind.
+func (kind FloatKind) BitSize() int64 {
+	switch kind {
+	case FloatKindHalf, FloatKindBFloat:
+		return 16
+	case FloatKindFloat:
+		return 32
+	case FloatKindDouble:
+		return 64
+	case FloatKindX86FP80:
+		return 80
+	case FloatKindFP128, FloatKindPPCFP128:
+		return 128
+	}
+	panic(fmt.Errorf("support for floating-point kind %v not yet implemented", kind))
+}
+
 // --- [ MMX types ] -----------------------------------------------------------
 
 // MMXType is an LLVM IR MMX type.
@@ -294,10 +348,12 @@ func (t *MMXType) SetAlias(alias string) {
 // --- [ Pointer types ] -------------------------------------------------------
 
 // PointerType is an LLVM IR pointer type.
+//
+// A pointer type without element type is an opaque pointer type (e.g. "ptr").
 type PointerType struct {
 	// Type name alias; or empty if not present.
 	Alias string
-	// Element type.
+	// Element type; or nil if opaque pointer type.
 	ElemType Type
 	// Address space; or zero value for default address space.
 	AddrSpace AddrSpace
@@ -327,9 +383,23 @@ func (t *PointerType) String() string {
 
 // Def returns the LLVM syntax representation of the definition of the type.
 func (t *PointerType) Def() string {
+	return t.def([]Type{t})
+}
+
+// def returns the LLVM syntax representation of the definition of the type,
+// with the given stack of composite types being printed.
+func (t *PointerType) def(stack []Type) string {
+	// "ptr" OptAddrSpace
 	// Type OptAddrSpace "*"
 	buf := &strings.Builder{}
-	buf.WriteString(t.ElemType.String())
+	if t.ElemType == nil {
+		buf.WriteString("ptr")
+		if t.AddrSpace != 0 {
+			fmt.Fprintf(buf, " %v", t.AddrSpace)
+		}
+		return buf.String()
+	}
+	buf.WriteString(typeString(t.ElemType, stack))
 	if t.AddrSpace != 0 {
 		fmt.Fprintf(buf, " %v", t.AddrSpace)
 	}
@@ -357,10 +427,13 @@ func (a AddrSpace) String() string {
 type VectorType struct {
 	// Type name alias; or empty if not present.
 	Alias string
-	// Vector length.
+	// Vector length; or the minimum vector length if scalable.
 	Len int64
 	// Element type.
 	ElemType Type
+	// Scalable vector; the vector length is an unknown integer multiple (vscale)
+	// of Len.
+	Scalable bool
 }
 
 // NewVector returns a new vector type based on the given vector length and
@@ -372,10 +445,20 @@ func NewVector(len int64, elemType Type) *VectorType {
 	}
 }
 
+// NewScalableVector returns a new scalable vector type based on the given
+// minimum vector length and element type.
+func NewScalableVector(len int64, elemType Type) *VectorType {
+	return &VectorType{
+		Len:      len,
+		ElemType: elemType,
+		Scalable: true,
+	}
+}
+
 // Equal reports whether t and u are of equal type.
 func (t *VectorType) Equal(u Type) bool {
 	if u, ok := u.(*VectorType); ok {
-		if t.Len != u.Len {
+		if t.Len != u.Len || t.Scalable != u.Scalable {
 			return false
 		}
 		return t.ElemType.Equal(u.ElemType)
@@ -393,8 +476,18 @@ func (t *VectorType) String() string {
 
 // Def returns the LLVM syntax representation of the definition of the type.
 func (t *VectorType) Def() string {
+	return t.def([]Type{t})
+}
+
+// def returns the LLVM syntax representation of the definition of the type,
+// with the given stack of composite types being printed.
+func (t *VectorType) def(stack []Type) string {
 	// "<" int_lit "x" Type ">"
-	return fmt.Sprintf("<%d x %v>", t.Len, t.ElemType)
+	// "<" "vscale" "x" int_lit "x" Type ">"
+	if t.Scalable {
+		return fmt.Sprintf("<vscale x %d x %s>", t.Len, typeString(t.ElemType, stack))
+	}
+	return fmt.Sprintf("<%d x %s>", t.Len, typeString(t.ElemType, stack))
 }
 
 // SetAlias sets the type name alias of the type.
@@ -549,8 +642,14 @@ func (t *ArrayType) String() string {
 
 // Def returns the LLVM syntax representation of the definition of the type.
 func (t *ArrayType) Def() string {
+	return t.def([]Type{t})
+}
+
+// def returns the LLVM syntax representation of the definition of the type,
+// with the given stack of composite types being printed.
+func (t *ArrayType) def(stack []Type) string {
 	// "[" int_lit "x" Type "]"
-	return fmt.Sprintf("[%d x %v]", t.Len, t.ElemType)
+	return fmt.Sprintf("[%d x %s]", t.Len, typeString(t.ElemType, stack))
 }
 
 // SetAlias sets the type name alias of the type.
@@ -616,6 +715,12 @@ func (t *StructType) String() string {
 
 // Def returns the LLVM syntax representation of the definition of the type.
 func (t *StructType) Def() string {
+	return t.def([]Type{t})
+}
+
+// def returns the LLVM syntax representation of the definition of the type,
+// with the given stack of composite types being printed.
+func (t *StructType) def(stack []Type) string {
 	// "opaque"
 	// "{" Types "}"
 	// "<" "{" Types "}" ">"
@@ -631,7 +736,7 @@ func (t *StructType) Def() string {
 		if i != 0 {
 			buf.WriteString(", ")
 		}
-		buf.WriteString(field.String())
+		buf.WriteString(typeString(field, stack))
 	}
 	buf.WriteString(" }")
 	if t.Packed {
@@ -645,10 +750,197 @@ func (t *StructType) SetAlias(alias string) {
 	t.Alias = alias
 }
 
+// --- [ Target extension types ] ----------------------------------------------
+
+// TargetExtType is an LLVM IR target extension type.
+type TargetExtType struct {
+	// Type name alias; or empty if not present.
+	Alias string
+	// Target extension type name.
+	Name string
+	// Type parameters.
+	TypeParams []Type
+	// Integer parameters.
+	IntParams []uint64
+}
+
+// NewTargetExt returns a new target extension type based on the given name,
+// type parameters and integer parameters.
+func NewTargetExt(name string, typeParams []Type, intParams ...uint64) *TargetExtType {
+	return &TargetExtType{
+		Name:       name,
+		TypeParams: typeParams,
+		IntParams:  intParams,
+	}
+}
+
+// Equal reports whether t and u are of equal type.
+func (t *TargetExtType) Equal(u Type) bool {
+	if u, ok := u.(*TargetExtType); ok {
+		if t.Name != u.Name {
+			return false
+		}
+		if len(t.TypeParams) != len(u.TypeParams) {
+			return false
+		}
+		for i := range t.TypeParams {
+			if !t.TypeParams[i].Equal(u.TypeParams[i]) {
+				return false
+			}
+		}
+		if len(t.IntParams) != len(u.IntParams) {
+			return false
+		}
+		for i := range t.IntParams {
+			if t.IntParams[i] != u.IntParams[i] {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// String returns the string representation of the target extension type.
+func (t *TargetExtType) String() string {
+	if len(t.Alias) > 0 {
+		return enc.Local(t.Alias)
+	}
+	return t.Def()
+}
+
+// Def returns the LLVM syntax representation of the definition of the type.
+func (t *TargetExtType) Def() string {
+	return t.def([]Type{t})
+}
+
+// def returns the LLVM syntax representation of the definition of the type,
+// with the given stack of composite types being printed.
+func (t *TargetExtType) def(stack []Type) string {
+	// "target" "(" Name TypeParams IntParams ")"
+	buf := &strings.Builder{}
+	fmt.Fprintf(buf, "target(%s", enc.Quote([]byte(t.Name)))
+	for _, param := range t.TypeParams {
+		fmt.Fprintf(buf, ", %s", typeString(param, stack))
+	}
+	for _, param := range t.IntParams {
+		fmt.Fprintf(buf, ", %d", param)
+	}
+	buf.WriteString(")")
+	return buf.String()
+}
+
+// SetAlias sets the type name alias of the type.
+func (t *TargetExtType) SetAlias(alias string) {
+	t.Alias = alias
+}
+
 // Convenience functions.
 
+// IsInt reports whether the given type is an integer type.
+func IsInt(t Type) bool {
+	_, ok := t.(*IntType)
+	return ok
+}
+
+// IsFloat reports whether the given type is a floating-point type.
+func IsFloat(t Type) bool {
+	_, ok := t.(*FloatType)
+	return ok
+}
+
 // IsPointer reports whether the given type is a pointer type.
 func IsPointer(t Type) bool {
 	_, ok := t.(*PointerType)
 	return ok
 }
+
+// IsSized reports whether the given type is sized; i.e. whether values of the
+// type have a known size in memory. Void, function, label, metadata, token and
+// target extension types, opaque struct types, and composite types containing
+// unsized types are unsized.
+func IsSized(t Type) bool {
+	switch t := t.(type) {
+	case *IntType, *FloatType, *MMXType, *PointerType:
+		return true
+	case *VectorType:
+		return IsSized(t.ElemType)
+	case *ArrayType:
+		return IsSized(t.ElemType)
+	case *StructType:
+		if t.Opaque {
+			return false
+		}
+		for _, field := range t.Fields {
+			if !IsSized(field) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// ### [ Helper functions ] ####################################################
+
+// callCompatible reports whether the types t and u are call compatible; i.e.
+// equal, or pointer types in the same address space.
+func callCompatible(t, u Type) bool {
+	if p, ok := t.(*PointerType); ok {
+		if q, ok := u.(*PointerType); ok {
+			return p.AddrSpace == q.AddrSpace
+		}
+	}
+	return t.Equal(u)
+}
+
+// cyclicType is the string representation of an unnamed composite type which
+// contains itself; such types must be named to be representable in LLVM IR.
+const cyclicType = "<cyclic type>"
+
+// compositeType is a composite type which may contain itself.
+type compositeType interface {
+	Type
+	// def returns the LLVM syntax representation of the definition of the type,
+	// with the given stack of composite types being printed.
+	def(stack []Type) string
+}
+
+// typeString returns the string representation of the given type, as
+// contained within the given stack of composite types being printed. Unnamed
+// composite types already present on the stack are represented by an error
+// marker, to prevent infinite recursion on cyclic types.
+func typeString(t Type, stack []Type) string {
+	c, ok := t.(compositeType)
+	if !ok || len(typeAlias(t)) > 0 {
+		return t.String()
+	}
+	for _, s := range stack {
+		if s == t {
+			return cyclicType
+		}
+	}
+	return c.def(append(stack, t))
+}
+
+// typeAlias returns the type name alias of the given composite type; or empty
+// if not present.
+func typeAlias(t Type) string {
+	switch t := t.(type) {
+	case *FuncType:
+		return t.Alias
+	case *PointerType:
+		return t.Alias
+	case *VectorType:
+		return t.Alias
+	case *ArrayType:
+		return t.Alias
+	case *StructType:
+		return t.Alias
+	case *TargetExtType:
+		return t.Alias
+	default:
+		panic(fmt.Errorf("invalid composite type; expected *types.FuncType, *types.PointerType, *types.VectorType, *types.ArrayType, *types.StructType or *types.TargetExtType, got %T", t))
+	}
+}
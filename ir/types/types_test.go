@@ -1,5 +1,7 @@
 package types
 
+import "testing"
+
 // Assert that each type implements the types.Type interface.
 var (
 	_ Type = (*VoidType)(nil)
@@ -14,4 +16,179 @@ var (
 	_ Type = (*MetadataType)(nil)
 	_ Type = (*ArrayType)(nil)
 	_ Type = (*StructType)(nil)
+	_ Type = (*TargetExtType)(nil)
 )
+
+func TestVectorString(t *testing.T) {
+	golden := []struct {
+		in   *VectorType
+		want string
+	}{
+		{in: NewVector(4, I32), want: "<4 x i32>"},
+		{in: NewScalableVector(4, I32), want: "<vscale x 4 x i32>"},
+	}
+	for _, g := range golden {
+		got := g.in.String()
+		if g.want != got {
+			t.Errorf("vector type mismatch; expected `%v`, got `%v`", g.want, got)
+		}
+	}
+	if NewVector(4, I32).Equal(NewScalableVector(4, I32)) {
+		t.Errorf("fixed-length and scalable vector types should not be equal")
+	}
+}
+
+func TestFloatString(t *testing.T) {
+	golden := []struct {
+		in          *FloatType
+		want        string
+		wantBitSize int64
+	}{
+		{in: Half, want: "half", wantBitSize: 16},
+		{in: BFloat, want: "bfloat", wantBitSize: 16},
+		{in: Float, want: "float", wantBitSize: 32},
+		{in: Double, want: "double", wantBitSize: 64},
+		{in: X86FP80, want: "x86_fp80", wantBitSize: 80},
+		{in: FP128, want: "fp128", wantBitSize: 128},
+		{in: PPCFP128, want: "ppc_fp128", wantBitSize: 128},
+	}
+	for _, g := range golden {
+		if got := g.in.String(); g.want != got {
+			t.Errorf("floating-point type mismatch; expected `%v`, got `%v`", g.want, got)
+		}
+		if got := g.in.BitSize(); g.wantBitSize != got {
+			t.Errorf("bit size mismatch of %v; expected %d, got %d", g.in, g.wantBitSize, got)
+		}
+	}
+}
+
+func TestMMXString(t *testing.T) {
+	if want, got := "x86_mmx", X86MMX.String(); want != got {
+		t.Errorf("MMX type mismatch; expected `%v`, got `%v`", want, got)
+	}
+	if !X86MMX.Equal(&MMXType{}) {
+		t.Errorf("expected MMX types to be equal")
+	}
+}
+
+func TestTargetExtString(t *testing.T) {
+	golden := []struct {
+		in   *TargetExtType
+		want string
+	}{
+		{in: NewTargetExt("aarch64.svcount", nil), want: `target("aarch64.svcount")`},
+		{in: NewTargetExt("spirv.Image", []Type{Void}, 0, 1), want: `target("spirv.Image", void, 0, 1)`},
+		{in: NewTargetExt("foo", []Type{I8, Float}), want: `target("foo", i8, float)`},
+	}
+	for _, g := range golden {
+		got := g.in.String()
+		if g.want != got {
+			t.Errorf("target extension type mismatch; expected `%v`, got `%v`", g.want, got)
+		}
+	}
+	if !NewTargetExt("foo", []Type{I8}, 1).Equal(NewTargetExt("foo", []Type{I8}, 1)) {
+		t.Errorf("expected target extension types to be equal")
+	}
+	if NewTargetExt("foo", []Type{I8}, 1).Equal(NewTargetExt("foo", []Type{I8}, 2)) {
+		t.Errorf("expected target extension types with different integer parameters to differ")
+	}
+}
+
+func TestCyclicTypeString(t *testing.T) {
+	// Unnamed struct type containing a pointer to itself.
+	st := NewStruct(I32, nil)
+	st.Fields[1] = NewPointer(st)
+	if want, got := "{ i32, <cyclic type>* }", st.String(); want != got {
+		t.Errorf("cyclic struct type mismatch; expected `%v`, got `%v`", want, got)
+	}
+	// Named struct types are referenced by name.
+	st.SetAlias("list")
+	if want, got := "{ i32, %list* }", st.Def(); want != got {
+		t.Errorf("named struct type mismatch; expected `%v`, got `%v`", want, got)
+	}
+	// Unnamed pointer type pointing to itself.
+	ptr := &PointerType{}
+	ptr.ElemType = ptr
+	if want, got := "<cyclic type>*", ptr.String(); want != got {
+		t.Errorf("cyclic pointer type mismatch; expected `%v`, got `%v`", want, got)
+	}
+}
+
+func TestFuncTypeCallCompatible(t *testing.T) {
+	i8Ptr := NewPointer(I8)
+	i32Ptr := NewPointer(I32)
+	i8PtrAS1 := &PointerType{ElemType: I8, AddrSpace: 1}
+	variadic := NewFunc(I32, I32)
+	variadic.Variadic = true
+	golden := []struct {
+		a, b *FuncType
+		want bool
+	}{
+		// Equal signatures.
+		{a: NewFunc(I32, I32), b: NewFunc(I32, I32), want: true},
+		// Pointer types with differing element types.
+		{a: NewFunc(i8Ptr, i8Ptr), b: NewFunc(i32Ptr, i32Ptr), want: true},
+		// Pointer types with differing address spaces.
+		{a: NewFunc(Void, i8Ptr), b: NewFunc(Void, i8PtrAS1), want: false},
+		// Differing return types.
+		{a: NewFunc(I32, I32), b: NewFunc(I64, I32), want: false},
+		// Differing number of parameters.
+		{a: NewFunc(I32, I32), b: NewFunc(I32, I32, I32), want: false},
+		// Differing parameter types.
+		{a: NewFunc(I32, I32), b: NewFunc(I32, I64), want: false},
+		// Differing variadic-ness.
+		{a: NewFunc(I32, I32), b: variadic, want: false},
+	}
+	for _, g := range golden {
+		if got := g.a.CallCompatible(g.b); g.want != got {
+			t.Errorf("call compatibility mismatch of `%v` and `%v`; expected %v, got %v", g.a, g.b, g.want, got)
+		}
+	}
+}
+
+func TestIsSized(t *testing.T) {
+	opaque := &StructType{Alias: "opaque", Opaque: true}
+	golden := []struct {
+		in   Type
+		want bool
+	}{
+		{in: I32, want: true},
+		{in: Double, want: true},
+		{in: NewPointer(opaque), want: true},
+		{in: NewArray(4, I8), want: true},
+		{in: NewStruct(I32, NewVector(2, Float)), want: true},
+		{in: Void, want: false},
+		{in: Label, want: false},
+		{in: NewFunc(Void), want: false},
+		{in: opaque, want: false},
+		{in: NewStruct(I32, opaque), want: false},
+		{in: NewArray(2, opaque), want: false},
+	}
+	for _, g := range golden {
+		if got := IsSized(g.in); g.want != got {
+			t.Errorf("sized mismatch of `%v`; expected %v, got %v", g.in, g.want, got)
+		}
+	}
+}
+
+func TestFuncTypeVariadic(t *testing.T) {
+	i8Ptr := NewPointer(I8)
+	printf := NewFunc(I32, i8Ptr)
+	printf.Variadic = true
+	noParams := NewFunc(Void)
+	noParams.Variadic = true
+	golden := []struct {
+		in   Type
+		want string
+	}{
+		{in: printf, want: "i32 (i8*, ...)"},
+		{in: noParams, want: "void (...)"},
+		{in: NewPointer(printf), want: "i32 (i8*, ...)*"},
+		{in: NewFunc(I32, i8Ptr), want: "i32 (i8*)"},
+	}
+	for _, g := range golden {
+		if got := g.in.String(); g.want != got {
+			t.Errorf("function type mismatch; expected `%v`, got `%v`", g.want, got)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package ir
+
+import (
+	"github.com/llir/l/ir/value"
+)
+
+// === [ Value kinds ] =========================================================
+
+//go:generate stringer -linecomment -type ValueKind
+
+// ValueKind specifies the kind of an LLVM IR value.
+type ValueKind uint8
+
+// Value kinds.
+const (
+	UnknownKind  ValueKind = iota // unknown
+	ConstantKind                  // constant
+	GlobalKind                    // global
+	InstKind                      // instruction
+	ParamKind                     // parameter
+	BlockKind                     // basic block
+)
+
+// Kind returns the kind of the given value. Global variables and functions are
+// of GlobalKind, other constants (including constant expressions) are of
+// ConstantKind, and instructions and terminators producing values are of
+// InstKind. Values of any other kind (e.g. inline assembly) are of
+// UnknownKind.
+func Kind(v value.Value) ValueKind {
+	switch v.(type) {
+	case *Global, *Function:
+		return GlobalKind
+	case Constant:
+		return ConstantKind
+	case Instruction, Terminator:
+		return InstKind
+	case *Param:
+		return ParamKind
+	case *BasicBlock:
+		return BlockKind
+	default:
+		return UnknownKind
+	}
+}
+
+// IsConstant reports whether the given value is a constant, including global
+// variables and functions.
+func IsConstant(v value.Value) bool {
+	switch Kind(v) {
+	case ConstantKind, GlobalKind:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsGlobal reports whether the given value is a global variable or function.
+func IsGlobal(v value.Value) bool {
+	return Kind(v) == GlobalKind
+}
+
+// IsInst reports whether the given value is the result of an instruction or
+// terminator.
+func IsInst(v value.Value) bool {
+	return Kind(v) == InstKind
+}
+
+// IsParam reports whether the given value is a function parameter.
+func IsParam(v value.Value) bool {
+	return Kind(v) == ParamKind
+}
+
+// IsBlock reports whether the given value is a basic block.
+func IsBlock(v value.Value) bool {
+	return Kind(v) == BlockKind
+}
+
+// === [ Underlying objects ] ==================================================
+
+// UnderlyingObject returns the underlying object of the given pointer value, as
+// determined by stripping getelementptr (following the source address), bitcast
+// and addrspacecast instructions and constant expressions; e.g. the alloca
+// instruction, global variable or function parameter on which the pointer is
+// based.
+//
+// Stripping stops at the first value which is not one of the above, in which
+// case that value is returned. The value at which a cycle is detected (e.g. a
+// getelementptr instruction using itself as source address in unreachable
+// code) is returned to ensure termination.
+func UnderlyingObject(v value.Value) value.Value {
+	visited := make(map[value.Value]bool)
+	for !visited[v] {
+		visited[v] = true
+		switch x := v.(type) {
+		case *InstGetElementPtr:
+			v = x.Src
+		case *InstBitCast:
+			v = x.From
+		case *InstAddrSpaceCast:
+			v = x.From
+		case *ExprGetElementPtr:
+			v = x.Src
+		case *ExprBitCast:
+			v = x.From
+		case *ExprAddrSpaceCast:
+			v = x.From
+		default:
+			// Alloca instruction, global variable, function parameter or other
+			// base value.
+			return v
+		}
+	}
+	return v
+}
@@ -0,0 +1,52 @@
+package value
+
+// Set is an insertion-ordered set of values. The zero value is an empty set
+// ready to use.
+//
+// Values are iterated in the order they were first added to the set, which
+// keeps the output of analyses and transformations reproducible.
+type Set struct {
+	// Index of each value in values.
+	index map[Value]int
+	// Values in order of insertion.
+	values []Value
+}
+
+// NewSet returns a new set containing the given values.
+func NewSet(vs ...Value) *Set {
+	s := &Set{}
+	for _, v := range vs {
+		s.Add(v)
+	}
+	return s
+}
+
+// Add adds the given value to the set. The boolean return value indicates
+// whether the value was not already present in the set.
+func (s *Set) Add(v Value) bool {
+	if _, ok := s.index[v]; ok {
+		return false
+	}
+	if s.index == nil {
+		s.index = make(map[Value]int)
+	}
+	s.index[v] = len(s.values)
+	s.values = append(s.values, v)
+	return true
+}
+
+// Has reports whether the given value is present in the set.
+func (s *Set) Has(v Value) bool {
+	_, ok := s.index[v]
+	return ok
+}
+
+// Len returns the number of values in the set.
+func (s *Set) Len() int {
+	return len(s.values)
+}
+
+// Slice returns the values of the set in order of insertion.
+func (s *Set) Slice() []Value {
+	return append([]Value(nil), s.values...)
+}
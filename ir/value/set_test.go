@@ -0,0 +1,53 @@
+package value
+
+import (
+	"testing"
+
+	"github.com/llir/l/ir/types"
+)
+
+// testValue is a named value used for testing.
+type testValue string
+
+func (v testValue) String() string   { return "i32 " + v.Ident() }
+func (v testValue) Type() types.Type { return types.I32 }
+func (v testValue) Ident() string    { return "%" + string(v) }
+
+func TestSetOrder(t *testing.T) {
+	a, b, c := testValue("a"), testValue("b"), testValue("c")
+	s := NewSet(c, a)
+	if !s.Add(b) {
+		t.Errorf("expected %v to be added", b.Ident())
+	}
+	// Duplicates do not change the order of insertion.
+	if s.Add(c) {
+		t.Errorf("expected %v to already be present", c.Ident())
+	}
+	want := []Value{c, a, b}
+	got := s.Slice()
+	if len(want) != len(got) {
+		t.Fatalf("number of values mismatch; expected %d, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if want[i] != got[i] {
+			t.Errorf("value %d mismatch; expected %v, got %v", i, want[i].Ident(), got[i].Ident())
+		}
+	}
+	if !s.Has(a) || s.Has(testValue("d")) {
+		t.Errorf("set membership mismatch")
+	}
+	if s.Len() != 3 {
+		t.Errorf("number of values mismatch; expected 3, got %d", s.Len())
+	}
+}
+
+func TestSetZeroValue(t *testing.T) {
+	var s Set
+	if s.Has(testValue("a")) || s.Len() != 0 || len(s.Slice()) != 0 {
+		t.Errorf("expected zero value set to be empty")
+	}
+	s.Add(testValue("a"))
+	if !s.Has(testValue("a")) {
+		t.Errorf("expected %v to be present", testValue("a").Ident())
+	}
+}
@@ -11,9 +11,10 @@ import (
 //
 // A Value has one of the following underlying types.
 //
-//    ir.Constant   // https://godoc.org/github.com/llir/l/ir#Constant
-//    value.Named   // https://godoc.org/github.com/llir/l/ir/value#Named
-//    TODO: add literal metadata value?
+//    ir.Constant         // https://godoc.org/github.com/llir/l/ir#Constant
+//    value.Named         // https://godoc.org/github.com/llir/l/ir/value#Named
+//    *ir.InlineAsm       // https://godoc.org/github.com/llir/l/ir#InlineAsm
+//    *ir.MetadataValue   // https://godoc.org/github.com/llir/l/ir#MetadataValue
 type Value interface {
 	// String returns the LLVM syntax representation of the value as a type-value
 	// pair.
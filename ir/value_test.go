@@ -1,6 +1,9 @@
 package ir
 
 import (
+	"testing"
+
+	"github.com/llir/l/ir/types"
 	"github.com/llir/l/ir/value"
 )
 
@@ -79,6 +82,7 @@ var (
 	_ value.Named = (*InstFCmp)(nil)
 	_ value.Named = (*InstPhi)(nil)
 	_ value.Named = (*InstSelect)(nil)
+	_ value.Named = (*InstFreeze)(nil)
 	_ value.Named = (*InstCall)(nil)
 	_ value.Named = (*InstVAArg)(nil)
 	_ value.Named = (*InstLandingPad)(nil)
@@ -87,5 +91,75 @@ var (
 
 	// Terminators.
 	_ value.Named = (*TermInvoke)(nil)
+	_ value.Named = (*TermCallBr)(nil)
 	_ value.Named = (*TermCatchSwitch)(nil) // token result used by catchpad
 )
+
+func TestKind(t *testing.T) {
+	x := NewParam(types.I32, "x")
+	entry := NewBlock("entry")
+	add := entry.NewAdd(x, x)
+	f := NewFunction("f", types.I32, x)
+	invoke := NewInvoke(f, []value.Value{x}, entry, entry)
+	golden := []struct {
+		in   value.Value
+		want ValueKind
+	}{
+		{in: NewInt(types.I32, 1), want: ConstantKind},
+		{in: NewUndef(types.I32), want: ConstantKind},
+		{in: NewAddExpr(NewInt(types.I32, 1), NewInt(types.I32, 2)), want: ConstantKind},
+		{in: NewGlobalDecl("g", types.I32), want: GlobalKind},
+		{in: f, want: GlobalKind},
+		{in: add, want: InstKind},
+		{in: invoke, want: InstKind},
+		{in: x, want: ParamKind},
+		{in: entry, want: BlockKind},
+		{in: NewInlineAsm(types.NewFunc(types.Void), "nop", ""), want: UnknownKind},
+	}
+	for _, g := range golden {
+		if got := Kind(g.in); g.want != got {
+			t.Errorf("value kind mismatch of `%v`; expected %v, got %v", g.in.Ident(), g.want, got)
+		}
+	}
+	if !IsConstant(f) || IsConstant(x) {
+		t.Errorf("IsConstant mismatch")
+	}
+	if !IsInst(add) || !IsParam(x) || !IsBlock(entry) || !IsGlobal(f) {
+		t.Errorf("value kind predicate mismatch")
+	}
+}
+
+func TestUnderlyingObject(t *testing.T) {
+	// %s = type { i32, [4 x i32] }
+	s := types.NewStruct(types.I32, types.NewArray(4, types.I32))
+	entry := NewBlock("entry")
+	alloca := entry.NewAlloca(s)
+	field := entry.NewGetElementPtr(s, alloca, NewInt(types.I64, 0), NewInt(types.I32, 1))
+	elem := entry.NewGetElementPtr(types.NewArray(4, types.I32), field, NewInt(types.I64, 0), NewInt(types.I64, 2))
+	cast := entry.NewBitCast(elem, types.I8Ptr)
+	p := NewParam(types.I8Ptr, "p")
+	g := NewGlobalDef("g", NewInt(types.I32, 0))
+	gep := NewGetElementPtrExpr(types.I32, g, NewIndex(NewInt(types.I64, 1)))
+	// Self-referencing getelementptr instruction, as may occur in unreachable
+	// code.
+	cyclic := NewGetElementPtr(types.I8, nil, NewInt(types.I64, 1))
+	cyclic.Src = cyclic
+	golden := []struct {
+		in   value.Value
+		want value.Value
+	}{
+		{in: elem, want: alloca},
+		{in: field, want: alloca},
+		{in: cast, want: alloca},
+		{in: alloca, want: alloca},
+		{in: p, want: p},
+		{in: entry.NewGetElementPtr(types.I8, p, NewInt(types.I64, 4)), want: p},
+		{in: NewBitCastExpr(gep, types.I8Ptr), want: g},
+		{in: cyclic, want: cyclic},
+	}
+	for _, g := range golden {
+		if got := UnderlyingObject(g.in); g.want != got {
+			t.Errorf("underlying object mismatch of `%v`; expected `%v`, got `%v`", g.in, g.want, got)
+		}
+	}
+}
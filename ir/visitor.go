@@ -0,0 +1,491 @@
+package ir
+
+import "fmt"
+
+// === [ Visitors ] ============================================================
+
+// InstVisitor is a visitor of LLVM IR instructions, with one method per
+// instruction kind.
+//
+// Embed BaseVisitor to only override the methods of interest.
+type InstVisitor interface {
+	// Binary instructions.
+	VisitAdd(inst *InstAdd)
+	VisitFAdd(inst *InstFAdd)
+	VisitSub(inst *InstSub)
+	VisitFSub(inst *InstFSub)
+	VisitMul(inst *InstMul)
+	VisitFMul(inst *InstFMul)
+	VisitUDiv(inst *InstUDiv)
+	VisitSDiv(inst *InstSDiv)
+	VisitFDiv(inst *InstFDiv)
+	VisitURem(inst *InstURem)
+	VisitSRem(inst *InstSRem)
+	VisitFRem(inst *InstFRem)
+
+	// Bitwise instructions.
+	VisitShl(inst *InstShl)
+	VisitLShr(inst *InstLShr)
+	VisitAShr(inst *InstAShr)
+	VisitAnd(inst *InstAnd)
+	VisitOr(inst *InstOr)
+	VisitXor(inst *InstXor)
+
+	// Vector instructions.
+	VisitExtractElement(inst *InstExtractElement)
+	VisitInsertElement(inst *InstInsertElement)
+	VisitShuffleVector(inst *InstShuffleVector)
+
+	// Aggregate instructions.
+	VisitExtractValue(inst *InstExtractValue)
+	VisitInsertValue(inst *InstInsertValue)
+
+	// Memory instructions.
+	VisitAlloca(inst *InstAlloca)
+	VisitLoad(inst *InstLoad)
+	VisitStore(inst *InstStore)
+	VisitFence(inst *InstFence)
+	VisitCmpXchg(inst *InstCmpXchg)
+	VisitAtomicRMW(inst *InstAtomicRMW)
+	VisitGetElementPtr(inst *InstGetElementPtr)
+
+	// Conversion instructions.
+	VisitTrunc(inst *InstTrunc)
+	VisitZExt(inst *InstZExt)
+	VisitSExt(inst *InstSExt)
+	VisitFPTrunc(inst *InstFPTrunc)
+	VisitFPExt(inst *InstFPExt)
+	VisitFPToUI(inst *InstFPToUI)
+	VisitFPToSI(inst *InstFPToSI)
+	VisitUIToFP(inst *InstUIToFP)
+	VisitSIToFP(inst *InstSIToFP)
+	VisitPtrToInt(inst *InstPtrToInt)
+	VisitIntToPtr(inst *InstIntToPtr)
+	VisitBitCast(inst *InstBitCast)
+	VisitAddrSpaceCast(inst *InstAddrSpaceCast)
+
+	// Other instructions.
+	VisitICmp(inst *InstICmp)
+	VisitFCmp(inst *InstFCmp)
+	VisitPhi(inst *InstPhi)
+	VisitSelect(inst *InstSelect)
+	VisitFreeze(inst *InstFreeze)
+	VisitCall(inst *InstCall)
+	VisitVAArg(inst *InstVAArg)
+	VisitLandingPad(inst *InstLandingPad)
+	VisitCatchPad(inst *InstCatchPad)
+	VisitCleanupPad(inst *InstCleanupPad)
+}
+
+// Accept dispatches the given instruction to the corresponding method of the
+// visitor.
+func Accept(inst Instruction, v InstVisitor) {
+	switch inst := inst.(type) {
+	// Binary instructions.
+	case *InstAdd:
+		v.VisitAdd(inst)
+	case *InstFAdd:
+		v.VisitFAdd(inst)
+	case *InstSub:
+		v.VisitSub(inst)
+	case *InstFSub:
+		v.VisitFSub(inst)
+	case *InstMul:
+		v.VisitMul(inst)
+	case *InstFMul:
+		v.VisitFMul(inst)
+	case *InstUDiv:
+		v.VisitUDiv(inst)
+	case *InstSDiv:
+		v.VisitSDiv(inst)
+	case *InstFDiv:
+		v.VisitFDiv(inst)
+	case *InstURem:
+		v.VisitURem(inst)
+	case *InstSRem:
+		v.VisitSRem(inst)
+	case *InstFRem:
+		v.VisitFRem(inst)
+	// Bitwise instructions.
+	case *InstShl:
+		v.VisitShl(inst)
+	case *InstLShr:
+		v.VisitLShr(inst)
+	case *InstAShr:
+		v.VisitAShr(inst)
+	case *InstAnd:
+		v.VisitAnd(inst)
+	case *InstOr:
+		v.VisitOr(inst)
+	case *InstXor:
+		v.VisitXor(inst)
+	// Vector instructions.
+	case *InstExtractElement:
+		v.VisitExtractElement(inst)
+	case *InstInsertElement:
+		v.VisitInsertElement(inst)
+	case *InstShuffleVector:
+		v.VisitShuffleVector(inst)
+	// Aggregate instructions.
+	case *InstExtractValue:
+		v.VisitExtractValue(inst)
+	case *InstInsertValue:
+		v.VisitInsertValue(inst)
+	// Memory instructions.
+	case *InstAlloca:
+		v.VisitAlloca(inst)
+	case *InstLoad:
+		v.VisitLoad(inst)
+	case *InstStore:
+		v.VisitStore(inst)
+	case *InstFence:
+		v.VisitFence(inst)
+	case *InstCmpXchg:
+		v.VisitCmpXchg(inst)
+	case *InstAtomicRMW:
+		v.VisitAtomicRMW(inst)
+	case *InstGetElementPtr:
+		v.VisitGetElementPtr(inst)
+	// Conversion instructions.
+	case *InstTrunc:
+		v.VisitTrunc(inst)
+	case *InstZExt:
+		v.VisitZExt(inst)
+	case *InstSExt:
+		v.VisitSExt(inst)
+	case *InstFPTrunc:
+		v.VisitFPTrunc(inst)
+	case *InstFPExt:
+		v.VisitFPExt(inst)
+	case *InstFPToUI:
+		v.VisitFPToUI(inst)
+	case *InstFPToSI:
+		v.VisitFPToSI(inst)
+	case *InstUIToFP:
+		v.VisitUIToFP(inst)
+	case *InstSIToFP:
+		v.VisitSIToFP(inst)
+	case *InstPtrToInt:
+		v.VisitPtrToInt(inst)
+	case *InstIntToPtr:
+		v.VisitIntToPtr(inst)
+	case *InstBitCast:
+		v.VisitBitCast(inst)
+	case *InstAddrSpaceCast:
+		v.VisitAddrSpaceCast(inst)
+	// Other instructions.
+	case *InstICmp:
+		v.VisitICmp(inst)
+	case *InstFCmp:
+		v.VisitFCmp(inst)
+	case *InstPhi:
+		v.VisitPhi(inst)
+	case *InstSelect:
+		v.VisitSelect(inst)
+	case *InstFreeze:
+		v.VisitFreeze(inst)
+	case *InstCall:
+		v.VisitCall(inst)
+	case *InstVAArg:
+		v.VisitVAArg(inst)
+	case *InstLandingPad:
+		v.VisitLandingPad(inst)
+	case *InstCatchPad:
+		v.VisitCatchPad(inst)
+	case *InstCleanupPad:
+		v.VisitCleanupPad(inst)
+	default:
+		panic(fmt.Errorf("support for instruction %T not yet implemented", inst))
+	}
+}
+
+// --- [ Base visitor ] --------------------------------------------------------
+
+// BaseVisitor is an instruction visitor with no-op methods for each instruction
+// kind. BaseVisitor may be embedded by visitors to only override the methods of
+// interest.
+type BaseVisitor struct{}
+
+// Binary instructions.
+
+// VisitAdd visits the given add instruction.
+func (BaseVisitor) VisitAdd(inst *InstAdd) {}
+
+// VisitFAdd visits the given fadd instruction.
+func (BaseVisitor) VisitFAdd(inst *InstFAdd) {}
+
+// VisitSub visits the given sub instruction.
+func (BaseVisitor) VisitSub(inst *InstSub) {}
+
+// VisitFSub visits the given fsub instruction.
+func (BaseVisitor) VisitFSub(inst *InstFSub) {}
+
+// VisitMul visits the given mul instruction.
+func (BaseVisitor) VisitMul(inst *InstMul) {}
+
+// VisitFMul visits the given fmul instruction.
+func (BaseVisitor) VisitFMul(inst *InstFMul) {}
+
+// VisitUDiv visits the given udiv instruction.
+func (BaseVisitor) VisitUDiv(inst *InstUDiv) {}
+
+// VisitSDiv visits the given sdiv instruction.
+func (BaseVisitor) VisitSDiv(inst *InstSDiv) {}
+
+// VisitFDiv visits the given fdiv instruction.
+func (BaseVisitor) VisitFDiv(inst *InstFDiv) {}
+
+// VisitURem visits the given urem instruction.
+func (BaseVisitor) VisitURem(inst *InstURem) {}
+
+// VisitSRem visits the given srem instruction.
+func (BaseVisitor) VisitSRem(inst *InstSRem) {}
+
+// VisitFRem visits the given frem instruction.
+func (BaseVisitor) VisitFRem(inst *InstFRem) {}
+
+// Bitwise instructions.
+
+// VisitShl visits the given shl instruction.
+func (BaseVisitor) VisitShl(inst *InstShl) {}
+
+// VisitLShr visits the given lshr instruction.
+func (BaseVisitor) VisitLShr(inst *InstLShr) {}
+
+// VisitAShr visits the given ashr instruction.
+func (BaseVisitor) VisitAShr(inst *InstAShr) {}
+
+// VisitAnd visits the given and instruction.
+func (BaseVisitor) VisitAnd(inst *InstAnd) {}
+
+// VisitOr visits the given or instruction.
+func (BaseVisitor) VisitOr(inst *InstOr) {}
+
+// VisitXor visits the given xor instruction.
+func (BaseVisitor) VisitXor(inst *InstXor) {}
+
+// Vector instructions.
+
+// VisitExtractElement visits the given extractelement instruction.
+func (BaseVisitor) VisitExtractElement(inst *InstExtractElement) {}
+
+// VisitInsertElement visits the given insertelement instruction.
+func (BaseVisitor) VisitInsertElement(inst *InstInsertElement) {}
+
+// VisitShuffleVector visits the given shufflevector instruction.
+func (BaseVisitor) VisitShuffleVector(inst *InstShuffleVector) {}
+
+// Aggregate instructions.
+
+// VisitExtractValue visits the given extractvalue instruction.
+func (BaseVisitor) VisitExtractValue(inst *InstExtractValue) {}
+
+// VisitInsertValue visits the given insertvalue instruction.
+func (BaseVisitor) VisitInsertValue(inst *InstInsertValue) {}
+
+// Memory instructions.
+
+// VisitAlloca visits the given alloca instruction.
+func (BaseVisitor) VisitAlloca(inst *InstAlloca) {}
+
+// VisitLoad visits the given load instruction.
+func (BaseVisitor) VisitLoad(inst *InstLoad) {}
+
+// VisitStore visits the given store instruction.
+func (BaseVisitor) VisitStore(inst *InstStore) {}
+
+// VisitFence visits the given fence instruction.
+func (BaseVisitor) VisitFence(inst *InstFence) {}
+
+// VisitCmpXchg visits the given cmpxchg instruction.
+func (BaseVisitor) VisitCmpXchg(inst *InstCmpXchg) {}
+
+// VisitAtomicRMW visits the given atomicrmw instruction.
+func (BaseVisitor) VisitAtomicRMW(inst *InstAtomicRMW) {}
+
+// VisitGetElementPtr visits the given getelementptr instruction.
+func (BaseVisitor) VisitGetElementPtr(inst *InstGetElementPtr) {}
+
+// Conversion instructions.
+
+// VisitTrunc visits the given trunc instruction.
+func (BaseVisitor) VisitTrunc(inst *InstTrunc) {}
+
+// VisitZExt visits the given zext instruction.
+func (BaseVisitor) VisitZExt(inst *InstZExt) {}
+
+// VisitSExt visits the given sext instruction.
+func (BaseVisitor) VisitSExt(inst *InstSExt) {}
+
+// VisitFPTrunc visits the given fptrunc instruction.
+func (BaseVisitor) VisitFPTrunc(inst *InstFPTrunc) {}
+
+// VisitFPExt visits the given fpext instruction.
+func (BaseVisitor) VisitFPExt(inst *InstFPExt) {}
+
+// VisitFPToUI visits the given fptoui instruction.
+func (BaseVisitor) VisitFPToUI(inst *InstFPToUI) {}
+
+// VisitFPToSI visits the given fptosi instruction.
+func (BaseVisitor) VisitFPToSI(inst *InstFPToSI) {}
+
+// VisitUIToFP visits the given uitofp instruction.
+func (BaseVisitor) VisitUIToFP(inst *InstUIToFP) {}
+
+// VisitSIToFP visits the given sitofp instruction.
+func (BaseVisitor) VisitSIToFP(inst *InstSIToFP) {}
+
+// VisitPtrToInt visits the given ptrtoint instruction.
+func (BaseVisitor) VisitPtrToInt(inst *InstPtrToInt) {}
+
+// VisitIntToPtr visits the given inttoptr instruction.
+func (BaseVisitor) VisitIntToPtr(inst *InstIntToPtr) {}
+
+// VisitBitCast visits the given bitcast instruction.
+func (BaseVisitor) VisitBitCast(inst *InstBitCast) {}
+
+// VisitAddrSpaceCast visits the given addrspacecast instruction.
+func (BaseVisitor) VisitAddrSpaceCast(inst *InstAddrSpaceCast) {}
+
+// Other instructions.
+
+// VisitICmp visits the given icmp instruction.
+func (BaseVisitor) VisitICmp(inst *InstICmp) {}
+
+// VisitFCmp visits the given fcmp instruction.
+func (BaseVisitor) VisitFCmp(inst *InstFCmp) {}
+
+// VisitPhi visits the given phi instruction.
+func (BaseVisitor) VisitPhi(inst *InstPhi) {}
+
+// VisitSelect visits the given select instruction.
+func (BaseVisitor) VisitSelect(inst *InstSelect) {}
+
+// VisitFreeze visits the given freeze instruction.
+func (BaseVisitor) VisitFreeze(inst *InstFreeze) {}
+
+// VisitCall visits the given call instruction.
+func (BaseVisitor) VisitCall(inst *InstCall) {}
+
+// VisitVAArg visits the given va_arg instruction.
+func (BaseVisitor) VisitVAArg(inst *InstVAArg) {}
+
+// VisitLandingPad visits the given landingpad instruction.
+func (BaseVisitor) VisitLandingPad(inst *InstLandingPad) {}
+
+// VisitCatchPad visits the given catchpad instruction.
+func (BaseVisitor) VisitCatchPad(inst *InstCatchPad) {}
+
+// VisitCleanupPad visits the given cleanuppad instruction.
+func (BaseVisitor) VisitCleanupPad(inst *InstCleanupPad) {}
+
+// --- [ Terminator visitor ] --------------------------------------------------
+
+// TermVisitor is a visitor of LLVM IR terminators, with one method per
+// terminator kind.
+//
+// Embed BaseTermVisitor to only override the methods of interest.
+type TermVisitor interface {
+	VisitRet(term *TermRet)
+	VisitBr(term *TermBr)
+	VisitCondBr(term *TermCondBr)
+	VisitSwitch(term *TermSwitch)
+	VisitIndirectBr(term *TermIndirectBr)
+	VisitInvoke(term *TermInvoke)
+	VisitCallBr(term *TermCallBr)
+	VisitResume(term *TermResume)
+	VisitCatchSwitch(term *TermCatchSwitch)
+	VisitCatchRet(term *TermCatchRet)
+	VisitCleanupRet(term *TermCleanupRet)
+	VisitUnreachable(term *TermUnreachable)
+}
+
+// AcceptTerm dispatches the given terminator to the corresponding method of the
+// visitor.
+func AcceptTerm(term Terminator, v TermVisitor) {
+	switch term := term.(type) {
+	case *TermRet:
+		v.VisitRet(term)
+	case *TermBr:
+		v.VisitBr(term)
+	case *TermCondBr:
+		v.VisitCondBr(term)
+	case *TermSwitch:
+		v.VisitSwitch(term)
+	case *TermIndirectBr:
+		v.VisitIndirectBr(term)
+	case *TermInvoke:
+		v.VisitInvoke(term)
+	case *TermCallBr:
+		v.VisitCallBr(term)
+	case *TermResume:
+		v.VisitResume(term)
+	case *TermCatchSwitch:
+		v.VisitCatchSwitch(term)
+	case *TermCatchRet:
+		v.VisitCatchRet(term)
+	case *TermCleanupRet:
+		v.VisitCleanupRet(term)
+	case *TermUnreachable:
+		v.VisitUnreachable(term)
+	default:
+		panic(fmt.Errorf("support for terminator %T not yet implemented", term))
+	}
+}
+
+// Accept walks the instructions of the basic block followed by its terminator,
+// dispatching each instruction to iv and the terminator to tv. A nil visitor
+// skips the corresponding part of the basic block.
+func (block *BasicBlock) Accept(iv InstVisitor, tv TermVisitor) {
+	if iv != nil {
+		for _, inst := range block.Insts {
+			Accept(inst, iv)
+		}
+	}
+	if tv != nil && block.Term != nil {
+		AcceptTerm(block.Term, tv)
+	}
+}
+
+// --- [ Base terminator visitor ] ---------------------------------------------
+
+// BaseTermVisitor is a terminator visitor with no-op methods for each
+// terminator kind. BaseTermVisitor may be embedded by visitors to only override
+// the methods of interest.
+type BaseTermVisitor struct{}
+
+// VisitRet visits the given ret terminator.
+func (BaseTermVisitor) VisitRet(term *TermRet) {}
+
+// VisitBr visits the given br terminator.
+func (BaseTermVisitor) VisitBr(term *TermBr) {}
+
+// VisitCondBr visits the given conditional br terminator.
+func (BaseTermVisitor) VisitCondBr(term *TermCondBr) {}
+
+// VisitSwitch visits the given switch terminator.
+func (BaseTermVisitor) VisitSwitch(term *TermSwitch) {}
+
+// VisitIndirectBr visits the given indirectbr terminator.
+func (BaseTermVisitor) VisitIndirectBr(term *TermIndirectBr) {}
+
+// VisitInvoke visits the given invoke terminator.
+func (BaseTermVisitor) VisitInvoke(term *TermInvoke) {}
+
+// VisitCallBr visits the given callbr terminator.
+func (BaseTermVisitor) VisitCallBr(term *TermCallBr) {}
+
+// VisitResume visits the given resume terminator.
+func (BaseTermVisitor) VisitResume(term *TermResume) {}
+
+// VisitCatchSwitch visits the given catchswitch terminator.
+func (BaseTermVisitor) VisitCatchSwitch(term *TermCatchSwitch) {}
+
+// VisitCatchRet visits the given catchret terminator.
+func (BaseTermVisitor) VisitCatchRet(term *TermCatchRet) {}
+
+// VisitCleanupRet visits the given cleanupret terminator.
+func (BaseTermVisitor) VisitCleanupRet(term *TermCleanupRet) {}
+
+// VisitUnreachable visits the given unreachable terminator.
+func (BaseTermVisitor) VisitUnreachable(term *TermUnreachable) {}
@@ -0,0 +1,93 @@
+package ir
+
+import (
+	"testing"
+
+	"github.com/llir/l/ir/types"
+)
+
+// memCounter is an instruction visitor which counts load and store
+// instructions.
+type memCounter struct {
+	BaseVisitor
+	loads, stores int
+}
+
+func (c *memCounter) VisitLoad(inst *InstLoad) {
+	c.loads++
+}
+
+func (c *memCounter) VisitStore(inst *InstStore) {
+	c.stores++
+}
+
+func TestAccept(t *testing.T) {
+	entry := NewBlock("entry")
+	x := entry.NewAlloca(types.I32)
+	entry.NewStore(NewInt(types.I32, 1), x)
+	v := entry.NewLoad(x)
+	w := entry.NewAdd(v, v)
+	entry.NewStore(w, x)
+	entry.NewLoad(x)
+	entry.NewLoad(x)
+	c := &memCounter{}
+	for _, inst := range entry.Insts {
+		Accept(inst, c)
+	}
+	if c.loads != 3 {
+		t.Errorf("number of loads mismatch; expected 3, got %d", c.loads)
+	}
+	if c.stores != 2 {
+		t.Errorf("number of stores mismatch; expected 2, got %d", c.stores)
+	}
+}
+
+// edgeCollector is a terminator visitor which accumulates control flow edges.
+type edgeCollector struct {
+	BaseTermVisitor
+	from  *BasicBlock
+	edges []string
+}
+
+func (c *edgeCollector) VisitBr(term *TermBr) {
+	c.addEdges(term)
+}
+
+func (c *edgeCollector) VisitCondBr(term *TermCondBr) {
+	c.addEdges(term)
+}
+
+func (c *edgeCollector) addEdges(term Terminator) {
+	for _, succ := range term.Succs() {
+		c.edges = append(c.edges, c.from.Name()+"->"+succ.Name())
+	}
+}
+
+func TestBlockAccept(t *testing.T) {
+	entry := NewBlock("entry")
+	then := NewBlock("then")
+	exit := NewBlock("exit")
+	p := NewParam(types.I1, "p")
+	entry.NewCondBr(p, then, exit)
+	then.NewLoad(NewParam(types.I32Ptr, "q"))
+	then.NewBr(exit)
+	exit.NewRet(nil)
+	mc := &memCounter{}
+	ec := &edgeCollector{}
+	for _, block := range []*BasicBlock{entry, then, exit} {
+		ec.from = block
+		block.Accept(mc, ec)
+	}
+	want := []string{"entry->then", "entry->exit", "then->exit"}
+	if len(want) != len(ec.edges) {
+		t.Fatalf("number of edges mismatch; expected %d, got %d", len(want), len(ec.edges))
+	}
+	for i := range want {
+		if want[i] != ec.edges[i] {
+			t.Errorf("edge mismatch; expected `%v`, got `%v`", want[i], ec.edges[i])
+		}
+	}
+	if mc.loads != 1 {
+		t.Errorf("number of loads mismatch; expected 1, got %d", mc.loads)
+	}
+}